@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/retroenv/nesgo/internal/compiler"
 	"github.com/retroenv/nesgo/pkg/ca65"
@@ -12,10 +13,12 @@ import (
 )
 
 type optionFlags struct {
-	input  string
-	output string
+	input     string
+	output    string
+	debugFile string
 
-	quiet bool
+	quiet  bool
+	cycles bool
 }
 
 func main() {
@@ -41,7 +44,9 @@ func readArguments() optionFlags {
 	options := optionFlags{}
 
 	flags.StringVar(&options.output, "o", "", "name of the output .nes file")
+	flags.StringVar(&options.debugFile, "dbg", "", "name of the ld65 debug info file to create, it can be used as symbols file by nesgoemu")
 	flags.BoolVar(&options.quiet, "q", false, "perform operations quietly")
+	flags.BoolVar(&options.cycles, "cycles", false, "print the estimated best and worst case CPU cycles of every function")
 
 	err := flags.Parse(os.Args[1:])
 	args := flags.Args()
@@ -66,7 +71,9 @@ func printBanner(options optionFlags) {
 }
 
 func compileFile(options optionFlags) error {
-	cfg := &compiler.Config{}
+	cfg := &compiler.Config{
+		EstimateCycles: options.cycles,
+	}
 	c, err := compiler.New(cfg)
 	if err != nil {
 		return fmt.Errorf("creating compiler: %w", err)
@@ -84,12 +91,17 @@ func compileFile(options optionFlags) error {
 	if err != nil {
 		return fmt.Errorf("compiling to file '%s' failed: %w", options.output, err)
 	}
+	if options.cycles {
+		printFunctionCycles(c.FunctionCycles())
+	}
 
 	// TODO pass real options
 	ca65Config := ca65.Config{
 		PrgBase: 0x8000,
 		PRGSize: 0x8000,
 		CHRSize: 0x2000,
+
+		DebugFile: options.debugFile,
 	}
 
 	if err = ca65.AssembleUsingExternalApp(asmFile, objectFile, options.output, ca65Config); err != nil {
@@ -98,3 +110,28 @@ func compileFile(options optionFlags) error {
 
 	return nil
 }
+
+// printFunctionCycles prints the estimated cycles of all functions. Loops with
+// an unknown iteration count and recursive functions are listed and counted
+// with a single iteration.
+func printFunctionCycles(functions []compiler.FunctionCycles) {
+	fmt.Printf("%-32s %8s %8s\n", "function", "best", "worst")
+	for _, fun := range functions {
+		worst := fmt.Sprint(fun.Worst)
+		if len(fun.Unbounded) > 0 || fun.Endless {
+			worst = ">" + worst
+		}
+		fmt.Printf("%-32s %8d %8s", fun.Name, fun.Best, worst)
+
+		if fun.Endless {
+			fmt.Print("  never returns")
+		}
+		if len(fun.Unbounded) > 0 {
+			fmt.Printf("  unbounded: %s", strings.Join(fun.Unbounded, ", "))
+		}
+		if fun.VBlankBudget {
+			fmt.Printf("  vblank budget: %d", compiler.VBlankCycles)
+		}
+		fmt.Println()
+	}
+}
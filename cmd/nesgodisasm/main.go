@@ -0,0 +1,126 @@
+// Package main implements a NES ROM disassembler
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/ca65"
+	"github.com/retroenv/nesgo/pkg/disasm"
+	"github.com/retroenv/retrogolib/buildinfo"
+)
+
+var errVerificationFailed = errors.New("reassembled ROM does not match the input")
+
+type optionFlags struct {
+	input  string
+	output string
+
+	hexComments bool
+	verify      bool
+	quiet       bool
+}
+
+func main() {
+	options := readArguments()
+
+	if !options.quiet {
+		printBanner(options)
+		fmt.Printf("Disassembling %s\n", options.input)
+	}
+
+	if err := disasmFile(options); err != nil {
+		fmt.Println(fmt.Errorf("error: %w", err))
+		os.Exit(1)
+	}
+
+	if !options.quiet {
+		fmt.Printf("Output file %s created successfully\n", options.output)
+	}
+}
+
+func readArguments() optionFlags {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	options := optionFlags{}
+
+	flags.StringVar(&options.output, "o", "", "name of the output .asm file, defaults to the input name with .asm extension")
+	flags.BoolVar(&options.hexComments, "c", false, "add the address and bytes of every instruction as comment")
+	flags.BoolVar(&options.verify, "verify", false, "verify the output by reassembling it using ca65")
+	flags.BoolVar(&options.quiet, "q", false, "perform operations quietly")
+
+	err := flags.Parse(os.Args[1:])
+	args := flags.Args()
+	if err != nil || len(args) == 0 {
+		printBanner(options)
+		fmt.Printf("usage: nesgodisasm [options] <file to disassemble>\n\n")
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	options.input = args[0]
+	if options.output == "" {
+		options.output = strings.TrimSuffix(options.input, filepath.Ext(options.input)) + ".asm"
+	}
+	return options
+}
+
+func printBanner(options optionFlags) {
+	if !options.quiet {
+		fmt.Println("[------------------------------------]")
+		fmt.Println("[ nesgodisasm - NES ROM disassembler ]")
+		fmt.Printf("[------------------------------------]\n\n")
+		fmt.Printf("version: %s\n\n", buildinfo.Version(version, commit, date))
+	}
+}
+
+func disasmFile(options optionFlags) error {
+	data, err := os.ReadFile(options.input)
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	dis, err := disasm.New(data, disasm.Options{HexComments: options.hexComments})
+	if err != nil {
+		return fmt.Errorf("initializing disassembler: %w", err)
+	}
+
+	if err = os.WriteFile(options.output, dis.Process(), 0644); err != nil {
+		return fmt.Errorf("writing file '%s': %w", options.output, err)
+	}
+
+	if options.verify {
+		return verifyOutput(options.output, data, dis.AssemblerConfig())
+	}
+	return nil
+}
+
+// verifyOutput reassembles the output file and compares the result with the input ROM.
+func verifyOutput(asmFile string, input []byte, conf ca65.Config) error {
+	dir, err := os.MkdirTemp("", "nesgodisasm")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	objectFile := filepath.Join(dir, "rom.o")
+	outputFile := filepath.Join(dir, "rom.nes")
+	if err = ca65.AssembleUsingExternalApp(asmFile, objectFile, outputFile, conf); err != nil {
+		return fmt.Errorf("reassembling file '%s': %w", asmFile, err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+	if !bytes.Equal(input, output) {
+		return errVerificationFailed
+	}
+	return nil
+}
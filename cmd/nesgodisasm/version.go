@@ -0,0 +1,7 @@
+package main
+
+var (
+	version = "1.0.0"
+	commit  = ""
+	date    = ""
+)
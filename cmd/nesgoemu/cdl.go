@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/retroenv/nesgo/pkg/cdl"
+	"github.com/retroenv/nesgo/pkg/nes"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const cdlBankSize = 0x4000 // 16K
+
+// codeDataLog handles the code/data logging of the emulation into a CDL file.
+type codeDataLog struct {
+	fileName string
+	logger   *cdl.Logger
+}
+
+// newCodeDataLog creates a code/data logger for the cartridge. An existing
+// CDL file gets loaded to accumulate the log over multiple sessions.
+func newCodeDataLog(fileName string, cart *cartridge.Cartridge) (*codeDataLog, error) {
+	c := &codeDataLog{
+		fileName: fileName,
+	}
+	if fileName == "" {
+		return c, nil
+	}
+
+	c.logger = cdl.New(len(cart.PRG), len(cart.CHR))
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("opening CDL file '%s': %w", fileName, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if err := c.logger.Load(file); err != nil {
+		return nil, fmt.Errorf("loading CDL file '%s': %w", fileName, err)
+	}
+	return c, nil
+}
+
+// options returns the emulator options for the code/data logger.
+func (c *codeDataLog) options() []nes.Option {
+	if c.logger == nil {
+		return nil
+	}
+	return []nes.Option{nes.WithCodeDataLog(c.logger)}
+}
+
+// finish writes the CDL file and prints the usage of every PRG bank.
+func (c *codeDataLog) finish() error {
+	if c.logger == nil {
+		return nil
+	}
+
+	file, err := os.Create(c.fileName)
+	if err != nil {
+		return fmt.Errorf("creating CDL file '%s': %w", c.fileName, err)
+	}
+	if err := c.logger.Write(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing CDL file '%s': %w", c.fileName, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing CDL file '%s': %w", c.fileName, err)
+	}
+
+	for i, usage := range c.logger.Usage(cdlBankSize) {
+		fmt.Printf("PRG bank %d: %d code bytes, %d data bytes, %d unused bytes\n",
+			i, usage.Code, usage.Data, usage.Unused)
+	}
+	return nil
+}
@@ -2,25 +2,98 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/fds"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+	"github.com/retroenv/nesgo/pkg/ines"
+	"github.com/retroenv/nesgo/pkg/inputmap"
+	"github.com/retroenv/nesgo/pkg/inputscript"
 	"github.com/retroenv/nesgo/pkg/nes"
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/nesgo/pkg/romdb"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 	"github.com/retroenv/retrogolib/buildinfo"
 )
 
+var (
+	errNoBIOS         = errors.New("disk images require the FDS BIOS file to be passed using -bios")
+	errFourScorePorts = errors.New("-fourscore can not be combined with -port1 or -port2")
+	errFourScoreMovie = errors.New("-fourscore can not be combined with -record or -play, movies only contain 2 gamepads")
+)
+
+// defaultInputDevice is the default device of both controller ports.
+const defaultInputDevice = "gamepad"
+
 type optionFlags struct {
-	input string
+	input   string
+	bios    string
+	noROMDB bool
 
 	debug        bool
 	debugAddress string
 
-	entrypoint int
-	noGui      bool
-	stopAt     int
-	tracing    bool
+	entrypoint  int
+	noGui       bool
+	stopAt      int
+	stopAtFrame uint64
+	tracing     bool
+
+	traceAddresses string
+	traceBanks     string
+	traceFrames    string
+	traceStart     int
+	traceBuffer    int
+	traceFormat    string
+
+	speed  float64
+	turbo  bool
+	paused bool
+	region string
+
+	screenshotFrames string
+	screenshotDir    string
+
+	recordMovie string
+	playMovie   string
+	inputScript string
+
+	port1        string
+	port2        string
+	fourScore    bool
+	inputMapping string
+
+	saveFile string
+
+	cheats    stringList
+	cheatFile string
+
+	cdlFile string
+
+	symbolsFile       string
+	profileFile       string
+	profileStacksFile string
+}
+
+// stringList implements a flag that can be passed multiple times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
 func main() {
@@ -36,12 +109,41 @@ func readArguments() optionFlags {
 	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	options := optionFlags{}
 
+	flags.StringVar(&options.bios, "bios", "", "FDS BIOS file to use for emulating .fds disk images")
+	flags.StringVar(&options.cdlFile, "cdl", "", "record executed code and read data of the PRG and rendered CHR into the given .cdl file")
 	flags.BoolVar(&options.debug, "d", false, "start built-in webserver for debug mode")
 	flags.StringVar(&options.debugAddress, "a", "127.0.0.1:8080", "listening address for the debug server to use")
 	flags.IntVar(&options.entrypoint, "e", -1, "entrypoint to start the CPU")
 	flags.BoolVar(&options.noGui, "c", false, "console mode, disable GUI")
+	flags.BoolVar(&options.noROMDB, "no-romdb", false, "do not fix the cartridge header using the embedded ROM database")
 	flags.IntVar(&options.stopAt, "s", -1, "stop execution at address")
+	flags.Uint64Var(&options.stopAtFrame, "f", 0, "stop execution after the given frame has been rendered")
+	flags.StringVar(&options.region, "region", "auto", "region to emulate: auto, ntsc, pal or dendy")
+	flags.Float64Var(&options.speed, "speed", 1.0, "emulation speed factor, values below 1 result in slow motion")
+	flags.BoolVar(&options.turbo, "turbo", false, "run the emulation as fast as possible")
+	flags.BoolVar(&options.paused, "pause", false, "start paused in frame advance mode, controllable using the debug server")
+	flags.StringVar(&options.port1, "port1", defaultInputDevice, "input device of port 1: gamepad, zapper, paddle or powerpad")
+	flags.StringVar(&options.port2, "port2", defaultInputDevice, "input device of port 2: gamepad, zapper, paddle or powerpad")
+	flags.BoolVar(&options.fourScore, "fourscore", false, "connect a Four Score adapter for 4 gamepads")
+	flags.Var(&options.cheats, "gg", "Game Genie code to apply, can be passed multiple times")
+	flags.StringVar(&options.cheatFile, "cheats", "", "cheat file in FCEUX .cht format to apply")
+	flags.StringVar(&options.inputMapping, "mapping", "", "input mapping config file (default ~/.config/nesgo/input.toml if it exists)")
+	flags.StringVar(&options.inputScript, "input", "", "apply the controller input of the given input script file")
+	flags.StringVar(&options.recordMovie, "record", "", "record the controller input to the given .fm2 movie file")
+	flags.StringVar(&options.profileFile, "profile", "", "profile the executed cycles per function and frame and write the report to the given file")
+	flags.StringVar(&options.profileStacksFile, "profile-stacks", "", "write the profiled call stacks in the collapsed stack format for flame graphs to the given file")
+	flags.StringVar(&options.playMovie, "play", "", "replay the controller input of the given .fm2 movie file and verify the RAM at the end")
+	flags.StringVar(&options.saveFile, "save", "", "battery save file of the cartridge PRG RAM (default <rom>.sav)")
+	flags.StringVar(&options.screenshotFrames, "screenshot", "", "comma separated list of frames to save as PNG screenshots")
+	flags.StringVar(&options.screenshotDir, "screenshot-dir", ".", "directory to save screenshots in")
+	flags.StringVar(&options.symbolsFile, "symbols", "", "ld65 debug info file of the program, used by the profiler to name the functions")
 	flags.BoolVar(&options.tracing, "t", false, "print CPU tracing")
+	flags.StringVar(&options.traceAddresses, "trace-pc", "", "only trace instructions in the hex address range, for example 8000-9FFF")
+	flags.StringVar(&options.traceBanks, "trace-bank", "", "comma separated list of PRG banks to trace instructions in")
+	flags.StringVar(&options.traceFrames, "trace-frames", "", "only trace instructions in the frame range, for example 10-20")
+	flags.IntVar(&options.traceStart, "trace-start", -1, "start tracing when the address gets executed")
+	flags.IntVar(&options.traceBuffer, "trace-buffer", 0, "keep the last n trace lines in memory and print them on a panic or when the stop address is reached")
+	flags.StringVar(&options.traceFormat, "trace-format", "nestest", "trace format: nestest or extended, which adds PPU position and mapper banks")
 
 	err := flags.Parse(os.Args[1:])
 	args := flags.Args()
@@ -64,22 +166,159 @@ func printBanner() {
 }
 
 func emulateFile(options optionFlags) error {
-	file, err := os.Open(options.input)
+	data, err := os.ReadFile(options.input)
+	if err != nil {
+		return fmt.Errorf("reading file '%s': %w", options.input, err)
+	}
+
+	cart, mediaOpts, err := loadMedia(options, data)
 	if err != nil {
-		return fmt.Errorf("opening file '%s': %w", options.input, err)
+		return err
 	}
 
-	cart, err := cartridge.LoadFile(file)
+	reg, err := emulationRegion(options, cart, data)
 	if err != nil {
-		return fmt.Errorf("reading file: %w", err)
+		return err
 	}
-	_ = file.Close()
 
 	opts := []nes.Option{
 		nes.WithEmulator(),
 		nes.WithCartridge(cart),
+		nes.WithRegion(reg),
+		nes.WithSaveFile(saveFileName(options)),
+	}
+	opts = append(opts, mediaOpts...)
+	opts = append(opts, basicOptions(options)...)
+	opts = append(opts, pacingOptions(options)...)
+
+	builderOpts, err := builderOptions(options)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, builderOpts...)
+
+	session, err := newMovieSession(options, cart, reg)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, session.options()...)
+
+	codeDataLog, err := newCodeDataLog(options.cdlFile, cart)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, codeDataLog.options()...)
+
+	profile, err := newProfile(options)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, profile.options()...)
+
+	nes.Start(nil, opts...)
+	if err := codeDataLog.finish(); err != nil {
+		return err
+	}
+	if err := profile.finish(); err != nil {
+		return err
+	}
+	return session.finish()
+}
+
+// builderOptions returns the emulator options of all flags that need to be parsed.
+func builderOptions(options optionFlags) ([]nes.Option, error) {
+	optionBuilders := []func(optionFlags) ([]nes.Option, error){
+		cheatOptions,
+		inputDeviceOptions,
+		inputScriptOptions,
+		screenshotOptions,
+		traceOptions,
+	}
+
+	var opts []nes.Option
+	for _, builder := range optionBuilders {
+		builderOpts, err := builder(options)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, builderOpts...)
+	}
+	return opts, nil
+}
+
+// loadMedia loads the cartridge from the file data. For disk images of the Famicom
+// Disk System, the cartridge contains the BIOS and the disk is returned as option.
+func loadMedia(options optionFlags, data []byte) (*cartridge.Cartridge, []nes.Option, error) {
+	if !fds.IsImage(data) {
+		cart, err := cartridge.LoadFile(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading file: %w", err)
+		}
+		return cart, nil, nil
+	}
+
+	if options.bios == "" {
+		return nil, nil, errNoBIOS
+	}
+	bios, err := os.ReadFile(options.bios)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading BIOS file '%s': %w", options.bios, err)
+	}
+	cart, err := fds.NewCartridge(bios)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading BIOS: %w", err)
+	}
+
+	disk, err := fds.Load(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading disk image: %w", err)
+	}
+	return cart, []nes.Option{nes.WithDisk(disk)}, nil
+}
+
+// cheatOptions returns the emulator options for the Game Genie codes and the cheat file.
+func cheatOptions(options optionFlags) ([]nes.Option, error) {
+	var opts []nes.Option
+
+	for _, code := range options.cheats {
+		if _, err := gamegenie.Decode(code); err != nil {
+			return nil, fmt.Errorf("decoding Game Genie code '%s': %w", code, err)
+		}
+	}
+	if len(options.cheats) > 0 {
+		opts = append(opts, nes.WithCheats(options.cheats...))
+	}
+
+	if options.cheatFile != "" {
+		cheats, err := readCheatFile(options.cheatFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nes.WithRawCheats(cheats...))
+	}
+	return opts, nil
+}
+
+// readCheatFile reads the cheats from the given .cht file.
+func readCheatFile(fileName string) ([]cheat.Cheat, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening cheat file '%s': %w", fileName, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	cheats, err := cheat.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cheat file '%s': %w", fileName, err)
 	}
+	return cheats, nil
+}
 
+// basicOptions returns the emulator options for the basic flags.
+func basicOptions(options optionFlags) []nes.Option {
+	var opts []nes.Option
 	if options.debug {
 		opts = append(opts, nes.WithDebug(options.debugAddress))
 	}
@@ -92,10 +331,185 @@ func emulateFile(options optionFlags) error {
 	if options.stopAt >= 0 {
 		opts = append(opts, nes.WithStopAt(options.stopAt))
 	}
+	if options.stopAtFrame > 0 {
+		opts = append(opts, nes.WithStopAtFrame(options.stopAtFrame))
+	}
 	if options.noGui {
 		opts = append(opts, nes.WithDisabledGUI())
 	}
+	if options.noROMDB {
+		opts = append(opts, nes.WithoutROMDatabase())
+	} else {
+		opts = append(opts, nes.WithROMDatabaseHandler(printROMDatabaseChanges))
+	}
+	return opts
+}
 
-	nes.Start(nil, opts...)
-	return nil
+// printROMDatabaseChanges prints the header fields that the ROM database fixed.
+func printROMDatabaseChanges(entry romdb.Entry, changes []string) {
+	name := entry.Name
+	if name == "" {
+		name = fmt.Sprintf("%08X", entry.CRC32)
+	}
+	fmt.Printf("ROM database entry '%s' fixed header: %s\n", name, strings.Join(changes, ", "))
+}
+
+// pacingOptions returns the emulator options for the frame pacing flags.
+func pacingOptions(options optionFlags) []nes.Option {
+	var opts []nes.Option
+	if options.speed != 1.0 {
+		opts = append(opts, nes.WithSpeed(options.speed))
+	}
+	if options.turbo {
+		opts = append(opts, nes.WithTurbo())
+	}
+	if options.paused {
+		opts = append(opts, nes.WithPaused())
+	}
+	return opts
+}
+
+// saveFileName returns the name of the battery save file, by default the
+// extension of the input file is replaced by .sav.
+func saveFileName(options optionFlags) string {
+	if options.saveFile != "" {
+		return options.saveFile
+	}
+	return strings.TrimSuffix(options.input, filepath.Ext(options.input)) + ".sav"
+}
+
+// inputDeviceOptions returns the emulator options for the input device flags.
+func inputDeviceOptions(options optionFlags) ([]nes.Option, error) {
+	mapping, err := readInputMapping(options.inputMapping)
+	if err != nil {
+		return nil, err
+	}
+	opts := []nes.Option{nes.WithInputMapping(mapping)}
+
+	if options.fourScore {
+		if options.port1 != defaultInputDevice || options.port2 != defaultInputDevice {
+			return nil, errFourScorePorts
+		}
+		if options.recordMovie != "" || options.playMovie != "" {
+			return nil, errFourScoreMovie
+		}
+		return append(opts, nes.WithFourScore()), nil
+	}
+
+	for i, name := range []string{options.port1, options.port2} {
+		device, err := controller.ParseDeviceType(name)
+		if err != nil {
+			return nil, fmt.Errorf("parsing device of port %d: %w", i+1, err)
+		}
+		opts = append(opts, nes.WithInputDevice(i+1, device))
+	}
+	return opts, nil
+}
+
+// readInputMapping reads the input mapping config file. If no file name is
+// given, the file in the user config directory is used if it exists, otherwise
+// the default mapping is returned.
+func readInputMapping(fileName string) (*inputmap.Mapping, error) {
+	if fileName == "" {
+		path, err := inputmap.DefaultPath()
+		if err != nil {
+			return inputmap.Default(), nil // nolint: nilerr
+		}
+		if _, err := os.Stat(path); err != nil {
+			return inputmap.Default(), nil // nolint: nilerr
+		}
+		fileName = path
+	}
+
+	mapping, err := inputmap.Load(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("reading input mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// emulationRegion returns the region to emulate, in auto mode the region
+// is detected from the ROM database entry or the file header. Disk images are
+// always emulated as NTSC, as the Famicom Disk System was only released in Japan.
+func emulationRegion(options optionFlags, cart *cartridge.Cartridge, data []byte) (region.Region, error) {
+	if options.region != "auto" {
+		reg, err := region.Parse(options.region)
+		if err != nil {
+			return reg, fmt.Errorf("parsing region: %w", err)
+		}
+		return reg, nil
+	}
+	if fds.IsImage(data) {
+		return region.NTSC, nil
+	}
+
+	if !options.noROMDB {
+		db, err := romdb.Default()
+		if err != nil {
+			return region.NTSC, fmt.Errorf("loading ROM database: %w", err)
+		}
+		if entry, ok := db.Lookup(cart.PRG, cart.CHR); ok && !entry.MultiRegion {
+			return entry.Region, nil
+		}
+	}
+
+	header, err := ines.Parse(data)
+	if err != nil {
+		return region.NTSC, fmt.Errorf("parsing header: %w", err)
+	}
+	return header.Region, nil
+}
+
+// inputScriptOptions returns the emulator options for the input script flag.
+func inputScriptOptions(options optionFlags) ([]nes.Option, error) {
+	if options.inputScript == "" {
+		return nil, nil
+	}
+
+	script, err := readInputScript(options.inputScript)
+	if err != nil {
+		return nil, err
+	}
+	return []nes.Option{nes.WithInputScript(script)}, nil
+}
+
+// readInputScript reads the input script from the given file.
+func readInputScript(fileName string) (*inputscript.Script, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening input script '%s': %w", fileName, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	script, err := inputscript.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing input script '%s': %w", fileName, err)
+	}
+	return script, nil
+}
+
+// screenshotOptions returns a screenshot option for every configured frame.
+// The screenshots are named after the input file and the frame number.
+// The screenshots triggered by hotkey are saved in the same directory.
+func screenshotOptions(options optionFlags) ([]nes.Option, error) {
+	opts := []nes.Option{nes.WithScreenshotDir(options.screenshotDir)}
+	if options.screenshotFrames == "" {
+		return opts, nil
+	}
+
+	base := strings.TrimSuffix(filepath.Base(options.input), filepath.Ext(options.input))
+	frames := strings.Split(options.screenshotFrames, ",")
+
+	for _, s := range frames {
+		frame, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing screenshot frame '%s': %w", s, err)
+		}
+
+		fileName := filepath.Join(options.screenshotDir, fmt.Sprintf("%s_%d.png", base, frame))
+		opts = append(opts, nes.WithScreenshot(frame, fileName))
+	}
+	return opts, nil
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/nesgo/pkg/nes"
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// movieSession handles the recording and playback of a movie.
+type movieSession struct {
+	recordFile string
+	recording  *movie.Movie
+
+	playback    *movie.Movie
+	played      bool
+	playbackErr error
+}
+
+func newMovieSession(options optionFlags, cart *cartridge.Cartridge, reg region.Region) (*movieSession, error) {
+	s := &movieSession{
+		recordFile: options.recordMovie,
+	}
+
+	if options.recordMovie != "" {
+		s.recording = movie.New(filepath.Base(options.input), cart.PRG, cart.CHR)
+		s.recording.PAL = reg == region.PAL
+	}
+
+	if options.playMovie != "" {
+		file, err := os.Open(options.playMovie)
+		if err != nil {
+			return nil, fmt.Errorf("opening movie file '%s': %w", options.playMovie, err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		s.playback, err = movie.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading movie file '%s': %w", options.playMovie, err)
+		}
+		if err := s.playback.VerifyROM(cart.PRG, cart.CHR); err != nil {
+			return nil, fmt.Errorf("verifying movie: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// options returns the emulator options for the movie session.
+func (s *movieSession) options() []nes.Option {
+	var opts []nes.Option
+	if s.recording != nil {
+		opts = append(opts, nes.WithMovieRecording(s.recording))
+	}
+
+	if s.playback != nil {
+		if s.playback.PAL {
+			opts = append(opts, nes.WithRegion(region.PAL))
+		}
+		opts = append(opts, nes.WithMoviePlayback(s.playback, func(err error) {
+			s.played = true
+			s.playbackErr = err
+		}))
+	}
+	return opts
+}
+
+// finish writes the recorded movie and returns the result of the movie playback.
+func (s *movieSession) finish() error {
+	if s.recording != nil {
+		if err := s.writeRecording(); err != nil {
+			return err
+		}
+	}
+
+	if s.playback == nil {
+		return nil
+	}
+	if !s.played {
+		return fmt.Errorf("movie playback was stopped after %d frames", len(s.playback.Frames))
+	}
+	if s.playbackErr != nil {
+		return fmt.Errorf("verifying movie playback: %w", s.playbackErr)
+	}
+
+	fmt.Printf("movie playback of %d frames finished successfully\n", len(s.playback.Frames))
+	return nil
+}
+
+func (s *movieSession) writeRecording() error {
+	file, err := os.Create(s.recordFile)
+	if err != nil {
+		return fmt.Errorf("creating movie file '%s': %w", s.recordFile, err)
+	}
+
+	if err := s.recording.Write(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing movie file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing movie file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/retroenv/nesgo/pkg/nes"
+	"github.com/retroenv/nesgo/pkg/profiler"
+)
+
+// profile handles the execution profiling of the emulation.
+type profile struct {
+	reportFile string
+	stacksFile string
+	profiler   *profiler.Profiler
+}
+
+// newProfile creates a profiler if a report or collapsed stacks file is set.
+// The optional symbols file is a ld65 debug info file of the program.
+func newProfile(options optionFlags) (*profile, error) {
+	p := &profile{
+		reportFile: options.profileFile,
+		stacksFile: options.profileStacksFile,
+	}
+	if p.reportFile == "" && p.stacksFile == "" {
+		return p, nil
+	}
+
+	var symbols *profiler.Symbols
+	if options.symbolsFile != "" {
+		file, err := os.Open(options.symbolsFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening symbols file '%s': %w", options.symbolsFile, err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		symbols, err = profiler.LoadDebugFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("loading symbols file '%s': %w", options.symbolsFile, err)
+		}
+	}
+
+	p.profiler = profiler.New(symbols)
+	return p, nil
+}
+
+// options returns the emulator options for the profiler.
+func (p *profile) options() []nes.Option {
+	if p.profiler == nil {
+		return nil
+	}
+	return []nes.Option{nes.WithProfiler(p.profiler)}
+}
+
+// finish writes the profiling report and the collapsed stacks files.
+func (p *profile) finish() error {
+	if p.profiler == nil {
+		return nil
+	}
+	if err := writeProfileFile(p.reportFile, p.profiler.WriteReport); err != nil {
+		return err
+	}
+	return writeProfileFile(p.stacksFile, p.profiler.WriteCollapsedStacks)
+}
+
+func writeProfileFile(fileName string, write func(io.Writer) error) error {
+	if fileName == "" {
+		return nil
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("creating profile file '%s': %w", fileName, err)
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing profile file '%s': %w", fileName, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing profile file '%s': %w", fileName, err)
+	}
+	return nil
+}
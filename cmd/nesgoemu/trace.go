@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/cpu"
+	"github.com/retroenv/nesgo/pkg/nes"
+)
+
+var (
+	errInvalidRange           = errors.New("invalid range")
+	errUnsupportedTraceFormat = errors.New("unsupported trace format")
+)
+
+// traceOptions returns the emulator options for the trace flags, setting any
+// of them enables tracing.
+func traceOptions(options optionFlags) ([]nes.Option, error) {
+	var opts []nes.Option
+
+	filter, err := traceFilter(options)
+	if err != nil {
+		return nil, err
+	}
+	if filter.PCEnd != 0 || len(filter.Banks) > 0 || filter.FrameStart > 0 || filter.FrameEnd > 0 {
+		opts = append(opts, nes.WithTraceFilter(filter))
+	}
+
+	switch options.traceFormat {
+	case "nestest":
+	case "extended":
+		opts = append(opts, nes.WithTraceFormat(cpu.ExtendedTraceFormat))
+	default:
+		return nil, fmt.Errorf("%w '%s'", errUnsupportedTraceFormat, options.traceFormat)
+	}
+
+	if options.traceStart >= 0 {
+		opts = append(opts, nes.WithTraceStartAt(uint16(options.traceStart)))
+	}
+	if options.traceBuffer > 0 {
+		opts = append(opts, nes.WithTraceBuffer(options.traceBuffer))
+	}
+	return opts, nil
+}
+
+// traceFilter returns the trace filter for the trace address, bank and frame flags.
+func traceFilter(options optionFlags) (cpu.TraceFilter, error) {
+	var filter cpu.TraceFilter
+
+	if options.traceAddresses != "" {
+		start, end, err := parseRange(options.traceAddresses, 16, 16)
+		if err != nil {
+			return filter, fmt.Errorf("parsing trace address range: %w", err)
+		}
+		filter.PCStart = uint16(start)
+		filter.PCEnd = uint16(end)
+	}
+
+	if options.traceFrames != "" {
+		start, end, err := parseRange(options.traceFrames, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("parsing trace frame range: %w", err)
+		}
+		filter.FrameStart = start
+		filter.FrameEnd = end
+	}
+
+	if options.traceBanks != "" {
+		for _, s := range strings.Split(options.traceBanks, ",") {
+			bank, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return filter, fmt.Errorf("parsing trace bank '%s': %w", s, err)
+			}
+			filter.Banks = append(filter.Banks, bank)
+		}
+	}
+	return filter, nil
+}
+
+// parseRange parses a range in the format start-end.
+func parseRange(s string, base, bitSize int) (uint64, uint64, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%w '%s', expected format start-end", errInvalidRange, s)
+	}
+
+	start, err := strconv.ParseUint(strings.TrimSpace(parts[0]), base, bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing range start: %w", err)
+	}
+	end, err := strconv.ParseUint(strings.TrimSpace(parts[1]), base, bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing range end: %w", err)
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("%w '%s', end is before start", errInvalidRange, s)
+	}
+	return start, end, nil
+}
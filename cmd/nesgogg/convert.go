@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/cheat"
+)
+
+var errMissingFile = errors.New("missing input file")
+
+// subcommands maps the names of the bulk conversion subcommands to their handlers.
+var subcommands = map[string]func(input io.Reader, output, errOutput io.Writer) error{
+	"gg2cht": convertCodesToCheats,
+	"cht2gg": convertCheatsToCodes,
+}
+
+// runSubcommand runs the conversion subcommand on the given input file and
+// prints the result to stdout.
+func runSubcommand(name string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w, usage: nesgogg %s <file>", errMissingFile, name)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("opening file '%s': %w", args[0], err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return subcommands[name](file, os.Stdout, os.Stderr)
+}
+
+// convertCodesToCheats converts a file containing a Game Genie code per line to
+// cheats in the .cht format. The code can be followed by a description, empty
+// lines and lines starting with # are ignored.
+func convertCodesToCheats(input io.Reader, output, errOutput io.Writer) error {
+	var cheats []cheat.Cheat
+	scanner := bufio.NewScanner(input)
+
+	for lineNr := 1; scanner.Scan(); lineNr++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		code, name, _ := strings.Cut(line, " ")
+		c, err := cheat.FromGameGenie(code)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNr, err)
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			c.Name = name
+		}
+		cheats = append(cheats, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading codes: %w", err)
+	}
+
+	if err := cheat.Save(output, cheats); err != nil {
+		return fmt.Errorf("writing cheats: %w", err)
+	}
+	return nil
+}
+
+// convertCheatsToCodes converts the cheats of a .cht file to Game Genie codes
+// followed by the cheat name. Cheats that can not be represented as Game Genie
+// code, like RAM freeze cheats, are skipped.
+func convertCheatsToCodes(input io.Reader, output, errOutput io.Writer) error {
+	cheats, err := cheat.Load(input)
+	if err != nil {
+		return fmt.Errorf("reading cheats: %w", err)
+	}
+
+	for _, c := range cheats {
+		code, err := c.GameGenie()
+		if err != nil {
+			fmt.Fprintf(errOutput, "skipping '%s': %s\n", c.String(), err)
+			continue
+		}
+		fmt.Fprintf(output, "%s %s\n", code, c.Name)
+	}
+	return nil
+}
@@ -19,6 +19,16 @@ type optionFlags struct {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if _, ok := subcommands[os.Args[1]]; ok {
+			if err := runSubcommand(os.Args[1], os.Args[2:]); err != nil {
+				fmt.Println(fmt.Errorf("converting failed: %w", err))
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	options := readArguments()
 
 	if options.code != "" {
@@ -49,7 +59,9 @@ func readArguments() optionFlags {
 
 	if err != nil || (len(args) == 0 && options.address == "") {
 		printBanner()
-		fmt.Printf("usage: nesgogg [options] <code>\n\n")
+		fmt.Printf("usage: nesgogg [options] <code>\n")
+		fmt.Printf("       nesgogg gg2cht <file with codes>\n")
+		fmt.Printf("       nesgogg cht2gg <file.cht>\n\n")
 		flags.PrintDefaults()
 		os.Exit(1)
 	}
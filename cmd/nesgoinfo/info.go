@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/retroenv/nesgo/pkg/ines"
+	"github.com/retroenv/nesgo/pkg/mapper"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+var (
+	errTruncatedFile = errors.New("file is truncated")
+	errInvalidSize   = errors.New("invalid ROM size")
+)
+
+// vectorsSize is the size of the interrupt vectors at the end of the PRG-ROM.
+const vectorsSize = 6
+
+var consoleTypes = map[byte]string{
+	ines.ConsoleNES:        "NES/Famicom",
+	ines.ConsoleVsSystem:   "Vs. System",
+	ines.ConsolePlaychoice: "PlayChoice-10",
+	ines.ConsoleExtended:   "extended",
+}
+
+var mirrorModes = map[cartridge.MirrorMode]string{
+	cartridge.MirrorHorizontal: "horizontal",
+	cartridge.MirrorVertical:   "vertical",
+	cartridge.Mirror4:          "four-screen",
+}
+
+// romInfo contains the information about a ROM file.
+type romInfo struct {
+	File   string `json:"file"`
+	Format string `json:"format"`
+
+	Mapper          uint16 `json:"mapper"`
+	SubMapper       byte   `json:"submapper"`
+	MapperSupported bool   `json:"mapperSupported"`
+
+	PRGSize      int `json:"prgSize"`
+	CHRSize      int `json:"chrSize"`
+	PRGRAMSize   int `json:"prgRamSize"`
+	PRGNVRAMSize int `json:"prgNvramSize"`
+	CHRRAMSize   int `json:"chrRamSize"`
+	CHRNVRAMSize int `json:"chrNvramSize"`
+
+	Mirroring   string `json:"mirroring"`
+	Battery     bool   `json:"battery"`
+	Trainer     bool   `json:"trainer"`
+	Console     string `json:"console"`
+	Region      string `json:"region"`
+	MultiRegion bool   `json:"multiRegion"`
+
+	PRGCRC32 string `json:"prgCrc32"`
+	PRGSHA1  string `json:"prgSha1"`
+	CHRCRC32 string `json:"chrCrc32,omitempty"`
+	CHRSHA1  string `json:"chrSha1,omitempty"`
+	ROMCRC32 string `json:"romCrc32"` // CRC32 of PRG and CHR, as used by ROM databases
+
+	Vectors vectors `json:"vectors"`
+}
+
+// vectors contains the interrupt vectors at the end of the last PRG bank,
+// which is mapped to $E000-$FFFF at power on by most mappers.
+type vectors struct {
+	NMI   uint16 `json:"nmi"`
+	Reset uint16 `json:"reset"`
+	IRQ   uint16 `json:"irq"`
+}
+
+// readROMInfo returns the information about the iNES or NES 2.0 file data.
+// The header is parsed by the ines package instead of retrogolib/cartridge,
+// which only supports the iNES fields and reads 8 bit mapper numbers, the bank
+// counts without the NES 2.0 MSB nibbles and no submapper or RAM sizes.
+func readROMInfo(fileName string, data []byte) (*romInfo, error) {
+	header, err := ines.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing header: %w", err)
+	}
+
+	if header.PRGSize < vectorsSize {
+		return nil, fmt.Errorf("%w: PRG-ROM size %d is too small to contain the interrupt vectors",
+			errInvalidSize, header.PRGSize)
+	}
+	prgStart := ines.HeaderSize + header.TrainerSize()
+	if available := len(data) - prgStart; header.PRGSize > available || header.CHRSize > available-header.PRGSize {
+		return nil, fmt.Errorf("%w: %d bytes, header defines %d bytes",
+			errTruncatedFile, len(data), prgStart+header.PRGSize+header.CHRSize)
+	}
+	chrStart := prgStart + header.PRGSize
+	prg := data[prgStart:chrStart]
+	chr := data[chrStart : chrStart+header.CHRSize]
+
+	info := &romInfo{
+		File:            fileName,
+		Format:          "iNES",
+		Mapper:          header.Mapper,
+		SubMapper:       header.SubMapper,
+		MapperSupported: mapper.Supported(header.Mapper),
+		PRGSize:         header.PRGSize,
+		CHRSize:         header.CHRSize,
+		PRGRAMSize:      header.PRGRAMSize,
+		PRGNVRAMSize:    header.PRGNVRAMSize,
+		CHRRAMSize:      header.CHRRAMSize,
+		CHRNVRAMSize:    header.CHRNVRAMSize,
+		Mirroring:       mirrorModes[header.Mirror],
+		Battery:         header.Battery,
+		Trainer:         header.Trainer,
+		Console:         consoleTypes[header.ConsoleType],
+		Region:          header.Region.String(),
+		MultiRegion:     header.MultiRegion,
+		PRGCRC32:        crc(prg),
+		PRGSHA1:         sha(prg),
+		ROMCRC32:        crc(data[prgStart : chrStart+header.CHRSize]),
+	}
+	if header.NES2 {
+		info.Format = "NES 2.0"
+	}
+	if len(chr) > 0 {
+		info.CHRCRC32 = crc(chr)
+		info.CHRSHA1 = sha(chr)
+	}
+
+	vectorData := prg[len(prg)-vectorsSize:]
+	info.Vectors = vectors{
+		NMI:   binary.LittleEndian.Uint16(vectorData[0:]),
+		Reset: binary.LittleEndian.Uint16(vectorData[2:]),
+		IRQ:   binary.LittleEndian.Uint16(vectorData[4:]),
+	}
+	return info, nil
+}
+
+func crc(data []byte) string {
+	return fmt.Sprintf("%08X", crc32.ChecksumIEEE(data))
+}
+
+func sha(data []byte) string {
+	return fmt.Sprintf("%X", sha1.Sum(data))
+}
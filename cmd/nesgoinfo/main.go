@@ -0,0 +1,117 @@
+// Package main implements a NES ROM header and info inspector
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/retroenv/retrogolib/buildinfo"
+)
+
+type optionFlags struct {
+	input string
+	json  bool
+}
+
+func main() {
+	options := readArguments()
+
+	if err := printFileInfo(options); err != nil {
+		fmt.Println(fmt.Errorf("reading ROM info failed: %w", err))
+		os.Exit(1)
+	}
+}
+
+func readArguments() optionFlags {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	options := optionFlags{}
+
+	flags.BoolVar(&options.json, "json", false, "output the info in JSON format")
+
+	err := flags.Parse(os.Args[1:])
+	args := flags.Args()
+	if err != nil || len(args) == 0 {
+		printBanner()
+		fmt.Printf("usage: nesgoinfo [options] <file.nes>\n\n")
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+	options.input = args[0]
+
+	return options
+}
+
+func printBanner() {
+	fmt.Println("[------------------------------------]")
+	fmt.Println("[ nesgoinfo - NES ROM info inspector ]")
+	fmt.Printf("[------------------------------------]\n\n")
+	fmt.Printf("version: %s\n\n", buildinfo.Version(version, commit, date))
+}
+
+func printFileInfo(options optionFlags) error {
+	data, err := os.ReadFile(options.input)
+	if err != nil {
+		return fmt.Errorf("reading file '%s': %w", options.input, err)
+	}
+
+	info, err := readROMInfo(options.input, data)
+	if err != nil {
+		return err
+	}
+
+	if options.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			return fmt.Errorf("encoding JSON: %w", err)
+		}
+		return nil
+	}
+
+	printText(info)
+	return nil
+}
+
+// printText prints the info in a human readable format.
+func printText(info *romInfo) {
+	supported := "not supported"
+	if info.MapperSupported {
+		supported = "supported"
+	}
+	region := info.Region
+	if info.MultiRegion {
+		region = "multiple regions"
+	}
+
+	fmt.Printf("File:       %s\n", info.File)
+	fmt.Printf("Format:     %s\n", info.Format)
+	fmt.Printf("Mapper:     %d, submapper %d (%s)\n", info.Mapper, info.SubMapper, supported)
+	fmt.Printf("PRG ROM:    %s\n", formatSize(info.PRGSize))
+	fmt.Printf("CHR ROM:    %s\n", formatSize(info.CHRSize))
+	fmt.Printf("PRG RAM:    %s, battery backed %s\n", formatSize(info.PRGRAMSize), formatSize(info.PRGNVRAMSize))
+	fmt.Printf("CHR RAM:    %s, battery backed %s\n", formatSize(info.CHRRAMSize), formatSize(info.CHRNVRAMSize))
+	fmt.Printf("Mirroring:  %s\n", info.Mirroring)
+	fmt.Printf("Battery:    %t\n", info.Battery)
+	fmt.Printf("Trainer:    %t\n", info.Trainer)
+	fmt.Printf("Console:    %s\n", info.Console)
+	fmt.Printf("Region:     %s\n", region)
+	fmt.Printf("PRG CRC32:  %s\n", info.PRGCRC32)
+	fmt.Printf("PRG SHA1:   %s\n", info.PRGSHA1)
+	if info.CHRCRC32 != "" {
+		fmt.Printf("CHR CRC32:  %s\n", info.CHRCRC32)
+		fmt.Printf("CHR SHA1:   %s\n", info.CHRSHA1)
+	}
+	fmt.Printf("ROM CRC32:  %s\n", info.ROMCRC32)
+	fmt.Printf("Vectors:    NMI $%04X, reset $%04X, IRQ $%04X\n",
+		info.Vectors.NMI, info.Vectors.Reset, info.Vectors.IRQ)
+}
+
+// formatSize returns the size in KB or bytes if it is not a multiple of 1 KB.
+func formatSize(size int) string {
+	if size == 0 || size%1024 != 0 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	return fmt.Sprintf("%d KB", size/1024)
+}
@@ -2,10 +2,9 @@ module github.com/retroenv/nesgo
 
 go 1.19
 
-require github.com/retroenv/retrogolib v0.0.0-20230722175549-eebe871ac8f3
-
 require (
-	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6 // indirect
-	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
-	github.com/veandco/go-sdl2 v0.5.0-alpha.4 // indirect
+	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b
+	github.com/retroenv/retrogolib v0.0.0-20230722175549-eebe871ac8f3
+	github.com/veandco/go-sdl2 v0.5.0-alpha.4
 )
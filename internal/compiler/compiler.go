@@ -39,6 +39,7 @@ type Compiler struct {
 	nmiHandler           string
 	irqHandler           string
 	output               []string
+	cycles               []FunctionCycles
 }
 
 // New returns a new compiler.
@@ -122,6 +123,10 @@ func (c *Compiler) OutputAsmFile(fileName string) (string, string, error) {
 		return "", "", fmt.Errorf("optimizing AST: %w", err)
 	}
 
+	if err := c.checkCycles(); err != nil {
+		return "", "", err
+	}
+
 	if err := c.generateProgramOutput(); err != nil {
 		return "", "", fmt.Errorf("generating program output: %w", err)
 	}
@@ -176,9 +181,18 @@ func (c *Compiler) optimize() error {
 		}
 	}
 
+	c.markCycleBudgets()
 	return c.inlineFunctions()
 }
 
+// FunctionCycles returns the statically estimated CPU cycles of all functions
+// of the program. It returns the results of the last OutputAsmFile call, the
+// cycles are only estimated if EstimateCycles is set in the config or a
+// function calls VBlankBudget.
+func (c *Compiler) FunctionCycles() []FunctionCycles {
+	return c.cycles
+}
+
 // addHandlersToParse parses the main function to get the entrypoints for the NES handlers.
 func (c *Compiler) addHandlersToParse(mainPackage *Package) error {
 	mainFunc := mainPackage.functions["main"]
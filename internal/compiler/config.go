@@ -4,6 +4,10 @@ package compiler
 type Config struct {
 	// DisableComments does not output any comments.
 	DisableComments bool
+	// EstimateCycles estimates the CPU cycles of all functions, the result
+	// is returned by FunctionCycles. The estimation is always done if a
+	// function calls VBlankBudget.
+	EstimateCycles bool
 }
 
 func (c Config) validate() error {
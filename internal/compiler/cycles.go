@@ -0,0 +1,668 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/internal/ast"
+	. "github.com/retroenv/retrogolib/addressing"
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+)
+
+const (
+	VBlankBudgetFunctionFullName = "github.com/retroenv/nesgo/pkg/nes.VBlankBudget"
+
+	// VBlankCycles is the amount of CPU cycles of the NTSC vertical blank,
+	// 20 scanlines of 341 PPU cycles each.
+	VBlankCycles = 20 * 341 / 3
+
+	interruptCycles = 7 // cycles of the CPU to jump to the NMI handler
+
+	returnExit  = -1 // exit of a code flow by rts or rti
+	endlessExit = -2 // exit of a code flow into an endless loop
+)
+
+// registerWrites contains the instructions that modify the X and Y registers.
+var registerWrites = map[string]string{
+	"dex": "X", "inx": "X", "ldx": "X", "tax": "X", "tsx": "X",
+	"dey": "Y", "iny": "Y", "ldy": "Y", "tay": "Y",
+}
+
+// FunctionCycles contains the statically estimated CPU cycles of a function,
+// including all called functions.
+type FunctionCycles struct {
+	Name  string
+	Best  int
+	Worst int
+
+	// Unbounded contains the labels of loops with an unknown iteration count,
+	// they are counted with a single iteration, and the names of recursively
+	// called functions, whose recursive calls are not counted.
+	Unbounded []string
+	// Endless is set if the function ends in a loop that never exits.
+	Endless bool
+	// VBlankBudget is set if the function is annotated with VBlankBudget.
+	VBlankBudget bool
+}
+
+// cycleRange is the range of cycles that a code path takes.
+type cycleRange struct {
+	best, worst int
+}
+
+func (r cycleRange) add(o cycleRange) cycleRange {
+	return cycleRange{best: r.best + o.best, worst: r.worst + o.worst}
+}
+
+// codeFlow contains the cycles of all paths through a part of a function,
+// by the node index that the paths exit to.
+type codeFlow struct {
+	exits     map[int]cycleRange
+	continued *cycleRange // paths that branch back to the start of a loop
+}
+
+func (f *codeFlow) addExit(target int, r cycleRange) {
+	if existing, ok := f.exits[target]; ok {
+		r = mergeCycles(existing, r)
+	}
+	f.exits[target] = r
+}
+
+func mergeCycles(a, b cycleRange) cycleRange {
+	if b.best < a.best {
+		a.best = b.best
+	}
+	if b.worst > a.worst {
+		a.worst = b.worst
+	}
+	return a
+}
+
+// cycleEstimator estimates the CPU cycles of the functions of the program
+// based on the instruction timings. As the addresses of the code are not known
+// before linking, branches and indexed memory accesses are counted without
+// page crossing for the best case and with page crossing for the worst case.
+type cycleEstimator struct {
+	c         *Compiler
+	functions map[string]*Function // by function name
+	results   map[string]*FunctionCycles
+	writes    map[string]map[string]bool // registers that functions modify
+	active    map[string]bool            // functions being estimated, to detect recursion
+
+	// state of the function that is currently estimated
+	result    *FunctionCycles
+	nodes     []ast.Node
+	labels    map[string]int // node index by label name
+	loopEnds  map[int]int    // node index of the last back branch by loop start index
+	backEdges map[int]int    // number of back branches by loop start index
+}
+
+// estimateCycles estimates the CPU cycles of all functions of the program.
+func (c *Compiler) estimateCycles() ([]FunctionCycles, error) {
+	e := &cycleEstimator{
+		c:         c,
+		functions: map[string]*Function{},
+		results:   map[string]*FunctionCycles{},
+		writes:    map[string]map[string]bool{},
+		active:    map[string]bool{},
+	}
+	for _, fun := range c.functions {
+		e.functions[fun.Definition.Name] = fun
+	}
+
+	results := make([]FunctionCycles, 0, len(c.functions))
+	for _, fun := range c.functions {
+		result, err := e.function(fun)
+		if err != nil {
+			return nil, fmt.Errorf("estimating cycles of function '%s': %w", fun.Definition.Name, err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// checkCycles estimates the CPU cycles of all functions if it is enabled in
+// the config or a function has a vblank budget that needs to be checked.
+func (c *Compiler) checkCycles() error {
+	if !c.cfg.EstimateCycles && !c.hasCycleBudgets() {
+		return nil
+	}
+
+	cycles, err := c.estimateCycles()
+	if err != nil {
+		return err
+	}
+	if err := checkCycleBudgets(cycles); err != nil {
+		return err
+	}
+	c.cycles = cycles
+	return nil
+}
+
+// hasCycleBudgets returns whether a function is annotated with VBlankBudget.
+func (c *Compiler) hasCycleBudgets() bool {
+	for _, fun := range c.functions {
+		if fun.VBlankBudget {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCycleBudgets returns an error if the worst case cycles of a function
+// that is annotated with VBlankBudget do not fit into the vertical blank.
+func checkCycleBudgets(functions []FunctionCycles) error {
+	for _, fun := range functions {
+		if !fun.VBlankBudget {
+			continue
+		}
+		if len(fun.Unbounded) > 0 || fun.Endless {
+			return fmt.Errorf("function '%s' exceeds the vblank budget of %d cycles, "+
+				"the worst case is unbounded", fun.Name, VBlankCycles)
+		}
+		if worst := fun.Worst + interruptCycles; worst > VBlankCycles {
+			return fmt.Errorf("function '%s' exceeds the vblank budget of %d cycles, "+
+				"the worst case is %d cycles", fun.Name, VBlankCycles, worst)
+		}
+	}
+	return nil
+}
+
+// markCycleBudgets sets the vblank budget flag of all functions that call
+// VBlankBudget, the call gets removed by inlining the empty function.
+func (c *Compiler) markCycleBudgets() {
+	for _, fun := range c.functions {
+		for _, node := range fun.Body.Nodes {
+			if call, ok := node.(*ast.Call); ok && call.Function == VBlankBudgetFunctionFullName {
+				fun.VBlankBudget = true
+			}
+		}
+	}
+}
+
+func (e *cycleEstimator) function(fun *Function) (*FunctionCycles, error) {
+	name := fun.Definition.Name
+	if result, ok := e.results[name]; ok {
+		return result, nil
+	}
+	if e.active[name] {
+		// the depth of recursive calls is unknown, the call is counted
+		// without the cycles of the called function
+		return &FunctionCycles{
+			Name:      name,
+			Unbounded: []string{name},
+		}, nil
+	}
+	e.active[name] = true
+	defer delete(e.active, name)
+
+	// save the state of the calling function
+	result, nodes, labels, loopEnds, backEdges := e.result, e.nodes, e.labels, e.loopEnds, e.backEdges
+	defer func() {
+		e.result, e.nodes, e.labels, e.loopEnds, e.backEdges = result, nodes, labels, loopEnds, backEdges
+	}()
+
+	e.result = &FunctionCycles{
+		Name:         name,
+		VBlankBudget: fun.VBlankBudget,
+	}
+	if err := e.setupFunction(fun); err != nil {
+		return nil, err
+	}
+
+	flow, err := e.flow(0, len(e.nodes), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *cycleRange
+	for _, target := range []int{returnExit, len(e.nodes), endlessExit} {
+		r, ok := flow.exits[target]
+		if !ok {
+			continue
+		}
+		if target == endlessExit {
+			e.result.Endless = true
+		}
+		if total == nil {
+			total = &r
+		} else {
+			merged := mergeCycles(*total, r)
+			total = &merged
+		}
+	}
+	if total != nil {
+		e.result.Best = total.best
+		e.result.Worst = total.worst
+	}
+
+	e.results[name] = e.result
+	return e.result, nil
+}
+
+// setupFunction indexes the labels and loops of the function.
+func (e *cycleEstimator) setupFunction(fun *Function) error {
+	e.nodes = fun.Body.Nodes
+	e.labels = map[string]int{}
+	e.loopEnds = map[int]int{}
+	e.backEdges = map[int]int{}
+
+	for i, node := range e.nodes {
+		if label, ok := node.(*ast.Label); ok {
+			if _, exists := e.labels[label.Name]; !exists {
+				e.labels[label.Name] = i
+			}
+		}
+	}
+
+	for i, node := range e.nodes {
+		branch, ok := node.(*ast.Branching)
+		if !ok {
+			continue
+		}
+		target, ok := e.labels[branch.DestinationName]
+		if !ok {
+			return fmt.Errorf("branching destination label '%s' not found", branch.DestinationName)
+		}
+		if target <= i {
+			e.loopEnds[target] = i
+			e.backEdges[target]++
+		}
+	}
+	return nil
+}
+
+// flow calculates the cycles of all paths through the nodes from start to end.
+// If the part is a loop, branches back to the start are returned as continued
+// paths, otherwise all nested loops are calculated as a whole.
+func (e *cycleEstimator) flow(start, end int, loop bool) (*codeFlow, error) {
+	flow := &codeFlow{exits: map[int]cycleRange{}}
+	arrivals := map[int]cycleRange{start: {}}
+
+	addEdge := func(from, target int, r cycleRange) {
+		switch {
+		case loop && target == start:
+			if flow.continued != nil {
+				r = mergeCycles(*flow.continued, r)
+			}
+			flow.continued = &r
+		case target > from && target < end:
+			if existing, ok := arrivals[target]; ok {
+				r = mergeCycles(existing, r)
+			}
+			arrivals[target] = r
+		case target >= end || target < start:
+			flow.addExit(target, r)
+		default:
+			// branch back into the middle of an already processed part,
+			// the flow can not be estimated
+			e.addUnbounded(e.nodes[target])
+		}
+	}
+
+	for i := start; i < end; i++ {
+		cur, ok := arrivals[i]
+		if !ok {
+			continue
+		}
+
+		// loops that overlap the end of the part are not nested properly
+		// and get handled as branch back into the processed part
+		if loopEnd, isLoop := e.loopEnds[i]; isLoop && (i != start || !loop) && loopEnd < end {
+			if err := e.loopFlow(i, loopEnd, cur, addEdge); err != nil {
+				return nil, err
+			}
+			i = loopEnd
+			continue
+		}
+
+		if err := e.nodeFlow(i, cur, addEdge); err != nil {
+			return nil, err
+		}
+	}
+
+	if r, ok := arrivals[end]; ok {
+		flow.addExit(end, r)
+	}
+	return flow, nil
+}
+
+// nodeFlow adds the edges of the node at the given index.
+func (e *cycleEstimator) nodeFlow(i int, cur cycleRange, addEdge func(from, target int, r cycleRange)) error {
+	switch n := e.nodes[i].(type) {
+	case *ast.Instruction:
+		r, err := e.instructionCycles(n)
+		if err != nil {
+			return fmt.Errorf("instruction '%s': %w", n, err)
+		}
+		if n.Name == ast.ReturnInstruction || n.Name == ast.ReturnInterruptInstruction {
+			addEdge(i, returnExit, cur.add(r))
+		} else {
+			addEdge(i, i+1, cur.add(r))
+		}
+
+	case *ast.Call:
+		r, err := e.callCycles(n)
+		if err != nil {
+			return err
+		}
+		addEdge(i, i+1, cur.add(r))
+
+	case *ast.Branching:
+		target := e.labels[n.DestinationName]
+		if n.Instruction == ast.JmpInstruction || n.Instruction == ast.GotoInstruction {
+			r := opcodeCycles(m6502.Jmp.Name, AbsoluteAddressing)
+			addEdge(i, target, cur.add(r))
+			return nil
+		}
+
+		// not taken branches take the base cycles, taken branches 1 more
+		// and another one if the destination is on a different page
+		r := opcodeCycles(n.Instruction, RelativeAddressing)
+		addEdge(i, i+1, cur.add(r))
+		addEdge(i, target, cur.add(cycleRange{best: r.best + 1, worst: r.best + 2}))
+
+	default:
+		addEdge(i, i+1, cur)
+	}
+	return nil
+}
+
+// loopFlow calculates the cycles of the loop from the start to the end index
+// and adds the paths that exit the loop. Loops with a known iteration count
+// get their cycles multiplied, all other loops are counted with one iteration
+// and marked as unbounded.
+func (e *cycleEstimator) loopFlow(start, end int, cur cycleRange,
+	addEdge func(from, target int, r cycleRange)) error {
+	flow, err := e.flow(start, end+1, true)
+	if err != nil {
+		return err
+	}
+
+	if len(flow.exits) == 0 {
+		e.result.Endless = true
+		e.addUnbounded(e.nodes[start])
+		r := cur
+		if flow.continued != nil {
+			r = r.add(*flow.continued)
+		}
+		addEdge(start, endlessExit, r)
+		return nil
+	}
+
+	iterations, bounded := e.loopIterations(start, end)
+	if !bounded {
+		e.addUnbounded(e.nodes[start])
+		iterations = 1
+	}
+
+	// without early exits, the loop always executes all iterations
+	_, normalExit := flow.exits[end+1]
+	earlyExits := len(flow.exits) > 1 || !normalExit
+
+	for target, r := range flow.exits {
+		if flow.continued != nil && iterations > 1 {
+			r.worst += (iterations - 1) * flow.continued.worst
+			if !earlyExits {
+				r.best += (iterations - 1) * flow.continued.best
+			}
+		}
+		addEdge(start, target, cur.add(r))
+	}
+	return nil
+}
+
+// loopIterations returns the iteration count of the loop if it is known.
+// Supported are loops that count an index register down to zero, like
+//
+//	ldx #8
+//	loop:
+//	dex
+//	bne loop
+//
+// and loops that count an index register up to a constant, like
+//
+//	ldx #0
+//	loop:
+//	inx
+//	cpx #8
+//	bcc loop
+func (e *cycleEstimator) loopIterations(start, end int) (int, bool) {
+	branch, ok := e.nodes[end].(*ast.Branching)
+	if !ok || e.backEdges[start] != 1 {
+		return 0, false
+	}
+
+	counter := end - 1 // index of the instruction that changes the counter
+	var compareRegister string
+	var limit uint64
+	if ins, ok := e.nodes[counter].(*ast.Instruction); ok && (ins.Name == "cpx" || ins.Name == "cpy") {
+		if limit, ok = immediateValue(ins); !ok {
+			return 0, false
+		}
+		compareRegister = strings.ToUpper(ins.Name[2:])
+		counter--
+	}
+	if counter <= start {
+		return 0, false
+	}
+
+	ins, ok := e.nodes[counter].(*ast.Instruction)
+	if !ok {
+		return 0, false
+	}
+	register := registerWrites[ins.Name]
+	if register == "" || (compareRegister != "" && compareRegister != register) ||
+		!e.loopKeepsRegister(start, end, counter, register) {
+		return 0, false
+	}
+	initial, ok := e.registerInitValue(start, register)
+	if !ok {
+		return 0, false
+	}
+
+	var iterations uint64
+	switch {
+	case (ins.Name == "dex" || ins.Name == "dey") && compareRegister == "" && branch.Instruction == "bne":
+		iterations = initial
+	case (ins.Name == "inx" || ins.Name == "iny") && compareRegister != "" && branch.Instruction == "bne":
+		iterations = (limit - initial) & 0xff
+	case (ins.Name == "inx" || ins.Name == "iny") && compareRegister != "" && branch.Instruction == "bcc" &&
+		initial < limit:
+		iterations = limit - initial
+	default:
+		return 0, false
+	}
+	if iterations == 0 {
+		iterations = 256
+	}
+	return int(iterations), true
+}
+
+// loopKeepsRegister returns whether the register is not modified inside of
+// the loop except by the counter instruction.
+func (e *cycleEstimator) loopKeepsRegister(start, end, counter int, register string) bool {
+	for i := start; i <= end; i++ {
+		if i == counter {
+			continue
+		}
+		switch n := e.nodes[i].(type) {
+		case *ast.Instruction:
+			if registerWrites[n.Name] == register {
+				return false
+			}
+		case *ast.Call:
+			fun := e.calledFunction(n)
+			if fun == nil || e.functionWrites(fun)[register] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// registerInitValue returns the immediate value that the register is loaded
+// with before the loop start, if it can be determined.
+func (e *cycleEstimator) registerInitValue(start int, register string) (uint64, bool) {
+	for i := start - 1; i >= 0; i-- {
+		switch n := e.nodes[i].(type) {
+		case *ast.Instruction:
+			if registerWrites[n.Name] != register {
+				continue
+			}
+			if n.Name != "ldx" && n.Name != "ldy" {
+				return 0, false
+			}
+			return immediateValue(n)
+
+		case *ast.Statement:
+			continue
+
+		default:
+			return 0, false // labels, branches or calls make the value unknown
+		}
+	}
+	return 0, false
+}
+
+// functionWrites returns the index registers that the function or any
+// called function modifies.
+func (e *cycleEstimator) functionWrites(fun *Function) map[string]bool {
+	name := fun.Definition.Name
+	if writes, ok := e.writes[name]; ok {
+		return writes
+	}
+
+	writes := map[string]bool{}
+	e.writes[name] = writes // handles recursion
+	for _, node := range fun.Body.Nodes {
+		switch n := node.(type) {
+		case *ast.Instruction:
+			if register := registerWrites[n.Name]; register != "" {
+				writes[register] = true
+			}
+		case *ast.Call:
+			called := e.calledFunction(n)
+			if called == nil {
+				writes["X"], writes["Y"] = true, true
+				continue
+			}
+			for register := range e.functionWrites(called) {
+				writes[register] = true
+			}
+		}
+	}
+	return writes
+}
+
+// callCycles returns the cycles of a jsr and the called function.
+func (e *cycleEstimator) callCycles(call *ast.Call) (cycleRange, error) {
+	fun := e.calledFunction(call)
+	if fun == nil {
+		return cycleRange{}, fmt.Errorf("called function '%s' not found", call.Function)
+	}
+
+	result, err := e.function(fun)
+	if err != nil {
+		return cycleRange{}, err
+	}
+	for _, loop := range result.Unbounded {
+		e.addUnboundedLoop(loop)
+	}
+	if result.Endless {
+		e.result.Endless = true
+	}
+
+	r := opcodeCycles(m6502.Jsr.Name, AbsoluteAddressing)
+	return r.add(cycleRange{best: result.Best, worst: result.Worst}), nil
+}
+
+func (e *cycleEstimator) calledFunction(call *ast.Call) *Function {
+	i := strings.LastIndex(call.Function, ".")
+	return e.functions[call.Function[i+1:]]
+}
+
+// instructionCycles returns the cycles of the instruction in the addressing
+// mode that the instruction gets output with.
+func (e *cycleEstimator) instructionCycles(ins *ast.Instruction) (cycleRange, error) {
+	info := m6502.Instructions[ins.Name]
+	if info == nil {
+		return cycleRange{}, fmt.Errorf("unsupported instruction '%s'", ins.Name)
+	}
+
+	var mode Mode
+	switch len(ins.Arguments) {
+	case 0:
+		mode = ImpliedAddressing
+		if !info.HasAddressing(ImpliedAddressing) {
+			mode = AccumulatorAddressing
+		}
+	case 1:
+		var err error
+		if mode, _, err = e.c.instructionOperand(ins, info); err != nil {
+			return cycleRange{}, err
+		}
+	default:
+		return cycleRange{}, fmt.Errorf("unsupported parameters '%s'", ins.Arguments)
+	}
+
+	// the assembler uses absolute addressing for zero page addresses if the
+	// instruction does not support the indexed zero page addressing mode
+	if !info.HasAddressing(mode) {
+		switch mode {
+		case ZeroPageXAddressing:
+			mode = AbsoluteXAddressing
+		case ZeroPageYAddressing:
+			mode = AbsoluteYAddressing
+		}
+	}
+	if !info.HasAddressing(mode) {
+		return cycleRange{}, fmt.Errorf("unsupported addressing mode %d", mode)
+	}
+	return opcodeCycles(ins.Name, mode), nil
+}
+
+// addUnbounded marks the loop that starts at the node as unbounded.
+func (e *cycleEstimator) addUnbounded(node ast.Node) {
+	name := fmt.Sprint(node)
+	if label, ok := node.(*ast.Label); ok {
+		name = label.Name
+	}
+	e.addUnboundedLoop(e.result.Name + "." + name)
+}
+
+func (e *cycleEstimator) addUnboundedLoop(loop string) {
+	for _, existing := range e.result.Unbounded {
+		if existing == loop {
+			return
+		}
+	}
+	e.result.Unbounded = append(e.result.Unbounded, loop)
+}
+
+// opcodeCycles returns the cycles of the instruction in the given addressing
+// mode, the worst case includes the page crossing cycle.
+func opcodeCycles(instruction string, mode Mode) cycleRange {
+	info := m6502.Instructions[instruction]
+	opcode := m6502.Opcodes[info.Addressing[mode].Opcode]
+	r := cycleRange{best: int(opcode.Timing), worst: int(opcode.Timing)}
+	if opcode.PageCrossCycle {
+		r.worst++
+	}
+	return r
+}
+
+// immediateValue returns the value of an instruction that gets output with
+// immediate addressing.
+func immediateValue(ins *ast.Instruction) (uint64, bool) {
+	if len(ins.Arguments) != 1 {
+		return 0, false
+	}
+	arg, ok := ins.Arguments[0].(*ast.ArgumentValue)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(arg.Value, 0, 8)
+	return value, err == nil
+}
@@ -0,0 +1,209 @@
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+var cyclesCountedLoop = []byte(`
+func test() {
+  Ldx(8)
+  for Bne() {
+    Dex()
+  }
+}
+`)
+
+var cyclesUnboundedLoop = []byte(`
+func test() {
+  Ldy(0)
+  for Bpl() {
+    Bit(PPU_STATUS)
+  }
+  update()
+}
+
+func update() {
+  Ldx(0)
+  for Bcc() {
+    Lda(0x200, X)
+    Inx()
+    Cpx(0x10)
+  }
+  if Beq() {
+    Iny()
+  }
+}
+`)
+
+var cyclesVBlankBudget = []byte(`
+func test() {
+  VBlankBudget()
+  Ldx(0)
+  for Bne() {
+    Lda(0x200, X)
+    Nop()
+    Dex()
+  }
+}
+`)
+
+var cyclesRecursion = []byte(`
+func test() {
+  Ldx(3)
+  countdown()
+  update()
+}
+
+func countdown() {
+  Dex()
+  if Bne() {
+    countdown()
+  }
+}
+
+func update() {
+  Inx()
+}
+`)
+
+var cyclesRecursionVBlankBudget = []byte(`
+func test() {
+  VBlankBudget()
+  Ldx(3)
+  countdown()
+}
+
+func countdown() {
+  Dex()
+  if Bne() {
+    countdown()
+  }
+}
+`)
+
+func estimateTestCycles(t *testing.T, input []byte) ([]FunctionCycles, error) {
+	t.Helper()
+
+	c, err := New(&Config{})
+	assert.NoError(t, err)
+
+	buf := bytes.Buffer{}
+	buf.Write(testFileHeader)
+	buf.Write(input)
+
+	assert.NoError(t, c.Parse("main.go", buf.Bytes()))
+	assert.NoError(t, c.optimize())
+	return c.estimateCycles()
+}
+
+func TestCyclesCountedLoop(t *testing.T) {
+	cycles, err := estimateTestCycles(t, cyclesCountedLoop)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(cycles))
+
+	// ldx, 8 * dex, 7 taken bne, 1 not taken bne, rti
+	assert.Equal(t, 2+8*2+7*3+2+6, cycles[0].Best)
+	assert.Equal(t, 2+8*2+7*4+2+6, cycles[0].Worst)
+	assert.Equal(t, 0, len(cycles[0].Unbounded))
+	assert.False(t, cycles[0].Endless)
+}
+
+func TestCyclesUnboundedLoop(t *testing.T) {
+	cycles, err := estimateTestCycles(t, cyclesUnboundedLoop)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cycles))
+
+	update := cycles[1]
+	assert.Equal(t, "update", update.Name)
+	assert.Equal(t, 0, len(update.Unbounded))
+	// ldx, 16 * (lda, inx, cpx, bcc), beq and jmp or iny, rts
+	loopBest := 16*(4+2+2) + 15*3 + 2
+	loopWorst := 16*(5+2+2) + 15*4 + 2
+	assert.Equal(t, 2+loopBest+5+6, update.Best)
+	assert.Equal(t, 2+loopWorst+6+6, update.Worst)
+
+	test := cycles[0]
+	assert.Equal(t, []string{"test.bpl_loop"}, test.Unbounded)
+	// ldy, bit, bpl, jsr, update, rti
+	assert.Equal(t, 2+4+2+6+update.Best+6, test.Best)
+	assert.Equal(t, 2+4+2+6+update.Worst+6, test.Worst)
+}
+
+func TestCyclesVBlankBudget(t *testing.T) {
+	cycles, err := estimateTestCycles(t, cyclesVBlankBudget)
+	assert.NoError(t, err)
+	assert.True(t, cycles[0].VBlankBudget)
+	assert.True(t, cycles[0].Worst > VBlankCycles)
+
+	err = checkCycleBudgets(cycles)
+	assert.Error(t, err, fmt.Sprintf("function 'test' exceeds the vblank budget of 2273 cycles, "+
+		"the worst case is %d cycles", cycles[0].Worst+7))
+
+	cycles, err = estimateTestCycles(t, cyclesCountedLoop)
+	assert.NoError(t, err)
+	cycles[0].VBlankBudget = true
+	assert.NoError(t, checkCycleBudgets(cycles))
+}
+
+func TestCyclesRecursion(t *testing.T) {
+	cycles, err := estimateTestCycles(t, cyclesRecursion)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(cycles))
+
+	functions := map[string]FunctionCycles{}
+	for _, fun := range cycles {
+		functions[fun.Name] = fun
+	}
+
+	countdown := functions["countdown"]
+	assert.Equal(t, []string{"countdown"}, countdown.Unbounded)
+	assert.False(t, countdown.Endless)
+	// dex, bne not taken, jmp and rts or bne taken, jsr without the
+	// recursion and rts
+	assert.Equal(t, 2+2+3+6, countdown.Best)
+	assert.Equal(t, 2+4+6+6, countdown.Worst)
+
+	update := functions["update"]
+	assert.Equal(t, 0, len(update.Unbounded))
+	assert.Equal(t, 2+6, update.Best)
+
+	test := functions["test"]
+	assert.Equal(t, []string{"countdown"}, test.Unbounded)
+	// ldx, jsr countdown, jsr update, rti
+	assert.Equal(t, 2+6+countdown.Best+6+update.Best+6, test.Best)
+	assert.Equal(t, 2+6+countdown.Worst+6+update.Worst+6, test.Worst)
+}
+
+func compileTestAsm(t *testing.T, cfg *Config, input []byte) (*Compiler, error) {
+	t.Helper()
+
+	c, err := New(cfg)
+	assert.NoError(t, err)
+
+	buf := bytes.Buffer{}
+	buf.Write(testFileHeader)
+	buf.Write(input)
+	assert.NoError(t, c.Parse("main.go", buf.Bytes()))
+
+	_, _, err = c.OutputAsmFile(filepath.Join(t.TempDir(), "main.nes"))
+	return c, err
+}
+
+func TestCyclesOptional(t *testing.T) {
+	c, err := compileTestAsm(t, &Config{}, cyclesRecursion)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(c.FunctionCycles()))
+
+	c, err = compileTestAsm(t, &Config{EstimateCycles: true}, cyclesRecursion)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(c.FunctionCycles()))
+
+	_, err = compileTestAsm(t, &Config{}, cyclesRecursionVBlankBudget)
+	assert.Error(t, err, "function 'test' exceeds the vblank budget of 2273 cycles, "+
+		"the worst case is unbounded")
+}
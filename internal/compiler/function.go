@@ -24,6 +24,8 @@ type Function struct {
 	Labels map[string]*ast.Label
 	// function is an IRQ handler and has to return with rti instruction
 	IrqHandler bool
+	// worst case cycles of the function have to fit into the vertical blank
+	VBlankBudget bool
 }
 
 // resolveFunctionNodes parses all nodes of a function and resolves
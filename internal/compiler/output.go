@@ -133,49 +133,53 @@ func (c *Compiler) outputInstruction(ins *ast.Instruction) error {
 }
 
 func (c *Compiler) outputInstruction1Arg(ins *ast.Instruction, info *cpu.Instruction) error {
+	_, operand, err := c.instructionOperand(ins, info)
+	if err != nil {
+		return err
+	}
+	c.outputLineWithComment(ins.Comment, "  %s %s", ins.Name, operand)
+	return nil
+}
+
+// instructionOperand returns the addressing mode and the formatted operand of
+// an instruction with 1 argument.
+func (c *Compiler) instructionOperand(ins *ast.Instruction, info *cpu.Instruction) (Mode, string, error) {
 	arg := ins.Arguments[0]
 	node, ok := arg.(*ast.ArgumentValue)
 	if !ok {
-		return fmt.Errorf("wrong argument type %T for instruction with 1 arg", arg)
+		return NoAddressing, "", fmt.Errorf("wrong argument type %T for instruction with 1 arg", arg)
 	}
 
 	if info.HasAddressing(AccumulatorAddressing) {
 		if node.Value == "A" {
-			c.outputLineWithComment(ins.Comment, "  %s a", ins.Name)
-			return nil
+			return AccumulatorAddressing, "a", nil
 		}
 	}
 	if info.HasAddressing(RelativeAddressing) {
-		c.outputLineWithComment(ins.Comment, "  %s %s", ins.Name, arg)
-		return nil
+		return RelativeAddressing, fmt.Sprint(arg), nil
 	}
 	if info.HasAddressing(ImmediateAddressing) {
 		val, err := strconv.ParseUint(node.Value, 0, 8)
 		if err == nil {
-			c.outputLineWithComment(ins.Comment, "  %s #$%02x", ins.Name, val)
-			return nil
+			return ImmediateAddressing, fmt.Sprintf("#$%02x", val), nil
 		}
 	}
 	if info.HasAddressing(ZeroPageAddressing, ZeroPageXAddressing, ZeroPageYAddressing) {
 		if val, err := strconv.ParseUint(node.Value, 0, 8); err == nil {
-			register := instructionIndexRegister(ins)
-			c.outputLineWithComment(ins.Comment, "  %s $%02x%s", ins.Name, val, register)
-			return nil
+			mode := indexedAddressing(ins, ZeroPageAddressing, ZeroPageXAddressing, ZeroPageYAddressing)
+			return mode, fmt.Sprintf("$%02x%s", val, instructionIndexRegister(ins)), nil
 		}
 	}
 	if info.HasAddressing(AbsoluteAddressing, AbsoluteXAddressing, AbsoluteYAddressing) {
+		mode := indexedAddressing(ins, AbsoluteAddressing, AbsoluteXAddressing, AbsoluteYAddressing)
 		if val, err := strconv.ParseUint(node.Value, 0, 16); err == nil {
-			register := instructionIndexRegister(ins)
-			c.outputLineWithComment(ins.Comment, "  %s $%04x%s", ins.Name, val, register)
-			return nil
+			return mode, fmt.Sprintf("$%04x%s", val, instructionIndexRegister(ins)), nil
 		}
 		if _, ok := c.variables[node.Value]; ok {
-			register := instructionIndexRegister(ins)
-			c.outputLineWithComment(ins.Comment, "  %s %s%s", ins.Name, node.Value, register)
-			return nil
+			return mode, node.Value + instructionIndexRegister(ins), nil
 		}
 	}
-	return fmt.Errorf("instruction '%s' with 1 argument "+
+	return NoAddressing, "", fmt.Errorf("instruction '%s' with 1 argument "+
 		"has an unexpected parameter '%s'", ins.Name, arg)
 }
 
@@ -190,6 +194,19 @@ func instructionIndexRegister(ins *ast.Instruction) string {
 	}
 }
 
+// indexedAddressing returns the passed addressing mode that matches the index
+// register of the instruction.
+func indexedAddressing(ins *ast.Instruction, mode, modeX, modeY Mode) Mode {
+	switch ins.Addressing {
+	case AbsoluteXAddressing, ZeroPageXAddressing:
+		return modeX
+	case AbsoluteYAddressing, ZeroPageYAddressing:
+		return modeY
+	default:
+		return mode
+	}
+}
+
 func (c *Compiler) outputVariables() error {
 	if len(c.variables) == 0 {
 		return nil
@@ -3,9 +3,12 @@ package nestest
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/retroenv/nesgo/pkg/cpu"
 	"github.com/retroenv/nesgo/pkg/nes"
 	"github.com/retroenv/retrogolib/arch/cpu/m6502"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
@@ -25,15 +28,11 @@ func TestNestest(t *testing.T) {
 
 	m6502.Isc.Name = "isb"
 
-	options := []nes.Option{
-		nes.WithEmulator(),
-		nes.WithCartridge(cart),
+	runner := nes.NewRunner(cart,
 		nes.WithEntrypoint(0xc000),
-		nes.WithStopAt(0x0001),
-		nes.WithDisabledGUI(),
 		nes.WithTracingTarget(trace),
-	}
-	nes.Start(nil, options...)
+	)
+	assert.NoError(t, runner.RunUntil(0x0001))
 
 	assert.NoError(t, trace.Flush())
 
@@ -53,3 +52,91 @@ func TestNestest(t *testing.T) {
 
 	assert.NoError(t, file.Close())
 }
+
+func TestNestestTraceFilter(t *testing.T) {
+	expected := filterLines(t, func(line string) bool {
+		return line >= "C700" && line < "C800"
+	})
+
+	lines := runTrace(t, nes.WithTraceFilter(cpu.TraceFilter{
+		PCStart: 0xc700,
+		PCEnd:   0xc7ff,
+	}))
+	assert.Equal(t, expected, lines)
+}
+
+func TestNestestTraceStartAt(t *testing.T) {
+	started := false
+	expected := filterLines(t, func(line string) bool {
+		started = started || strings.HasPrefix(line, "D900")
+		return started
+	})
+
+	lines := runTrace(t, nes.WithTraceStartAt(0xd900))
+	assert.Equal(t, expected, lines)
+}
+
+func TestNestestTraceBuffer(t *testing.T) {
+	expected := filterLines(t, func(string) bool {
+		return true
+	})
+	expected = expected[len(expected)-16:]
+
+	lines := runTrace(t, nes.WithTraceBuffer(16))
+	assert.Equal(t, expected, lines)
+}
+
+func TestNestestTraceExtendedFormat(t *testing.T) {
+	data, err := os.ReadFile("nestest.log")
+	assert.NoError(t, err)
+	expected := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	lines := runTrace(t, nes.WithTraceFormat(cpu.ExtendedTraceFormat))
+	assert.Equal(t, len(expected), len(lines))
+	assert.True(t, strings.HasSuffix(lines[0], " SP:FD PPU:  0, 21 CYC:7 PRG:00,00 CHR:00"))
+
+	// the nestest.log contains the PPU position but not the mapper banks
+	for i, line := range lines {
+		line = line[:strings.Index(line, " PRG:")]
+		assert.Equal(t, strings.TrimSuffix(expected[i], "\r"), line, fmt.Sprintf("line %d", i+1))
+	}
+}
+
+// runTrace runs nestest with the given trace options and returns the trace
+// lines, including the lines of the ring buffer if enabled.
+func runTrace(t *testing.T, options ...nes.Option) []string {
+	t.Helper()
+
+	file, err := os.Open("nestest.nes")
+	assert.NoError(t, err)
+	cart, err := cartridge.LoadFile(file)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	var buffer bytes.Buffer
+	m6502.Isc.Name = "isb"
+
+	options = append(options, nes.WithEntrypoint(0xc000), nes.WithTracingTarget(&buffer))
+	runner := nes.NewRunner(cart, options...)
+	assert.NoError(t, runner.RunUntil(0x0001))
+	runner.DumpTrace()
+
+	return strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+}
+
+// filterLines returns all lines of the nestest log that match the filter.
+func filterLines(t *testing.T, filter func(line string) bool) []string {
+	t.Helper()
+
+	data, err := os.ReadFile("nestest_no_ppu.log")
+	assert.NoError(t, err)
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if filter(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,100 @@
+//go:build !nesgo
+
+package apu
+
+import "sync"
+
+// Output levels of the 2A03 channels use the approximation formulas of the nonlinear mixer.
+const (
+	pulseMixFactor    = 95.88
+	pulseMixDivisor   = 8128.0
+	tndMixFactor      = 159.79
+	triangleMixWeight = 8227.0
+	noiseMixWeight    = 12241.0
+	dmcMixWeight      = 22638.0
+	mixBias           = 100.0
+
+	// maxPulseVolume is the highest volume of a 2A03 pulse channel.
+	maxPulseVolume = 15
+)
+
+// pulseUnit is the mixer output of a single 2A03 pulse channel per volume step.
+// Expansion audio levels are expressed in this unit.
+var pulseUnit = pulseOutput(maxPulseVolume, 0) / maxPulseVolume
+
+// ExpansionAudio is implemented by mappers that contain an expansion sound chip,
+// like the VRC6, VRC7, Sunsoft 5B, Namco 163, MMC5 or the FDS.
+// The sound chip is clocked by the mapper for every CPU cycle.
+type ExpansionAudio interface {
+	// AudioOutput returns the current output level of all expansion channels. The level
+	// is relative to a 2A03 pulse channel, a value of 15 is as loud as a single 2A03
+	// pulse channel at full volume. The chip implementation is responsible for scaling
+	// its channels to the correct relative level.
+	AudioOutput() float32
+}
+
+// ChannelOutputs contains the current output levels of the 2A03 channels.
+type ChannelOutputs struct {
+	Pulse1   uint8 // 0-15
+	Pulse2   uint8 // 0-15
+	Triangle uint8 // 0-15
+	Noise    uint8 // 0-15
+	DMC      uint8 // 0-127
+}
+
+// Mixer mixes the 2A03 channels and all expansion audio channels to a single sample.
+type Mixer struct {
+	mu         sync.RWMutex
+	expansions []ExpansionAudio
+}
+
+// NewMixer returns a new audio mixer.
+func NewMixer() *Mixer {
+	return &Mixer{}
+}
+
+// AddExpansion adds an expansion sound chip to the mixer.
+func (m *Mixer) AddExpansion(expansion ExpansionAudio) {
+	m.mu.Lock()
+	m.expansions = append(m.expansions, expansion)
+	m.mu.Unlock()
+}
+
+// ClearExpansions removes all expansion sound chips from the mixer.
+func (m *Mixer) ClearExpansions() {
+	m.mu.Lock()
+	m.expansions = nil
+	m.mu.Unlock()
+}
+
+// Sample returns the mixed output sample for the passed 2A03 channel levels and the
+// current output of all expansion sound chips. A sample of all 2A03 channels at full
+// volume is close to 1.0, expansion chips can exceed this.
+func (m *Mixer) Sample(channels ChannelOutputs) float32 {
+	sample := pulseOutput(channels.Pulse1, channels.Pulse2) +
+		tndOutput(channels.Triangle, channels.Noise, channels.DMC)
+
+	m.mu.RLock()
+	for _, expansion := range m.expansions {
+		sample += expansion.AudioOutput() * pulseUnit
+	}
+	m.mu.RUnlock()
+
+	return sample
+}
+
+func pulseOutput(pulse1, pulse2 uint8) float32 {
+	sum := float32(pulse1) + float32(pulse2)
+	if sum == 0 {
+		return 0
+	}
+	return pulseMixFactor / (pulseMixDivisor/sum + mixBias)
+}
+
+func tndOutput(triangle, noise, dmc uint8) float32 {
+	sum := float32(triangle)/triangleMixWeight + float32(noise)/noiseMixWeight + float32(dmc)/dmcMixWeight
+	if sum == 0 {
+		return 0
+	}
+	return tndMixFactor / (1/sum + mixBias)
+}
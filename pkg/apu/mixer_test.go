@@ -0,0 +1,36 @@
+package apu
+
+import (
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+type testExpansion struct {
+	output float32
+}
+
+func (e *testExpansion) AudioOutput() float32 {
+	return e.output
+}
+
+func TestMixer(t *testing.T) {
+	t.Parallel()
+
+	m := NewMixer()
+	assert.Equal(t, float32(0), m.Sample(ChannelOutputs{}))
+
+	pulse := m.Sample(ChannelOutputs{Pulse1: 15})
+	assert.True(t, pulse > 0.14 && pulse < 0.16)
+
+	all := m.Sample(ChannelOutputs{Pulse1: 15, Pulse2: 15, Triangle: 15, Noise: 15, DMC: 127})
+	assert.True(t, all > 0.99 && all < 1.01)
+
+	// an expansion output of 15 is as loud as a single pulse channel at full volume
+	expansion := &testExpansion{output: 15}
+	m.AddExpansion(expansion)
+	assert.Equal(t, pulse, m.Sample(ChannelOutputs{}))
+
+	m.ClearExpansions()
+	assert.Equal(t, float32(0), m.Sample(ChannelOutputs{}))
+}
@@ -2,6 +2,7 @@
 package bus
 
 import (
+	"github.com/retroenv/nesgo/pkg/fds"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 )
 
@@ -11,11 +12,15 @@ import (
 // initialization order issues.
 type Bus struct {
 	Cartridge   *cartridge.Cartridge // used by Mapper
-	Controller1 Controller           // used by Memory
-	Controller2 Controller           // used by Memory
+	CodeDataLog CodeDataLogger       // used by Mapper and PPU, optional
+	Controller1 Controller           // gamepad of player 1
+	Controller2 Controller           // gamepad of player 2
 	CPU         CPU                  // used by PPU
+	Disk        *fds.Disk            // used by the FDS mapper
 	Mapper      Mapper               // used by Memory and PPU
 	Memory      Memory               // used by CPU
 	NameTable   NameTable            // used by CPU and Mapper
 	PPU         PPU                  // used by Memory
+	Port1       InputDevice          // used by Memory
+	Port2       InputDevice          // used by Memory
 }
@@ -0,0 +1,13 @@
+package bus
+
+// CodeDataLogger records the accesses to the PRG and CHR ROM of the cartridge.
+type CodeDataLogger interface {
+	// LogPrgRead logs a read of the PRG ROM byte at the given offset that is
+	// mapped to the given CPU address.
+	LogPrgRead(offset int, address uint16)
+	// LogChrRead logs a read of the CHR ROM byte at the given offset.
+	LogChrRead(offset int)
+	// SetChrDataRead sets whether the following CHR reads are done by the CPU
+	// through the PPU data register instead of being rendered.
+	SetChrDataRead(enabled bool)
+}
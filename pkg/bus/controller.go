@@ -4,7 +4,15 @@ import "github.com/retroenv/nesgo/pkg/controller"
 
 // Controller represents a hardware controller.
 type Controller interface {
+	Buttons() controller.Button
 	Read() uint8
+	SetButtons(buttons controller.Button)
 	SetButtonState(key controller.Button, pressed bool)
 	SetStrobeMode(mode uint8)
 }
+
+// InputDevice represents a device that is connected to a controller port.
+type InputDevice interface {
+	Read() uint8
+	SetStrobeMode(mode uint8)
+}
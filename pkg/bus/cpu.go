@@ -33,6 +33,7 @@ type CPUState struct {
 
 // CPU represents the Central Processing Unit.
 type CPU interface {
+	ClearIrq()
 	Cycles() uint64
 	StallCycles(cycles uint16)
 	State() CPUState
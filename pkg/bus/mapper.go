@@ -16,5 +16,12 @@ type Mapper interface {
 	BasicMemory
 
 	MirrorMode() cartridge.MirrorMode
+	PrgRAM() []byte
+	BatteryRAM() []byte
 	State() MapperState
+	Step()
+
+	// ReadPPU and WritePPU are used by the PPU to access the pattern tables and nametables.
+	ReadPPU(address uint16) uint8
+	WritePPU(address uint16, value uint8)
 }
@@ -14,6 +14,8 @@ type Memory interface {
 	ReadAddressModes(immediate bool, params ...any) byte
 	ReadWord(address uint16) uint16
 	ReadWordBug(address uint16) uint16
+	Reset()
+	SetCartridgeReadHook(hook func(address uint16, value uint8) uint8)
 	WriteAddressModes(value byte, params ...any)
 	WriteWord(address, value uint16)
 
@@ -10,8 +10,13 @@ import (
 type PPU interface {
 	BasicMemory
 
+	Cycle() int
+	Frame() uint64
+	ScanLine() int
 	Image() *image.RGBA
 	Palette() Palette
+	PowerCycle()
+	Reset()
 	Step(cycles int)
 }
 
@@ -18,6 +18,8 @@ type Config struct {
 	PrgBase int
 	PRGSize int
 	CHRSize int
+
+	DebugFile string // optional, file that the linker writes the debug info to
 }
 
 // AssembleUsingExternalApp calls the external assembler and linker to generate a .nes
@@ -30,7 +32,12 @@ func AssembleUsingExternalApp(asmFile, objectFile, outputFile string, conf Confi
 		return fmt.Errorf("%s is not installed", linker)
 	}
 
-	cmd := exec.Command(assembler, asmFile, "-o", objectFile)
+	assemblerArgs := []string{asmFile, "-o", objectFile}
+	if conf.DebugFile != "" {
+		assemblerArgs = append(assemblerArgs, "-g")
+	}
+
+	cmd := exec.Command(assembler, assemblerArgs...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("assembling file: %s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -49,7 +56,13 @@ func AssembleUsingExternalApp(asmFile, objectFile, outputFile string, conf Confi
 		return fmt.Errorf("writing linker config: %w", err)
 	}
 
-	cmd = exec.Command(linker, "-C", configFile.Name(), "-o", outputFile, objectFile)
+	linkerArgs := []string{"-C", configFile.Name(), "-o", outputFile}
+	if conf.DebugFile != "" {
+		linkerArgs = append(linkerArgs, "--dbgfile", conf.DebugFile)
+	}
+	linkerArgs = append(linkerArgs, objectFile)
+
+	cmd = exec.Command(linker, linkerArgs...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("linking file: %s: %w", string(out), err)
 	}
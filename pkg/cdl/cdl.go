@@ -0,0 +1,182 @@
+// Package cdl implements a code/data logger that records which bytes of the
+// PRG ROM were executed or read as data and which bytes of the CHR ROM were
+// rendered, in the FCEUX/Mesen compatible CDL format.
+package cdl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/retroenv/retrogolib/arch/nes/codedatalog"
+)
+
+const (
+	codeBaseAddress    = 0x8000
+	maxInstructionSize = 3
+)
+
+// ErrInvalidSize is returned when loading a CDL file that does not match the cartridge.
+var ErrInvalidSize = errors.New("invalid CDL file size")
+
+// Logger records the accesses to the PRG and CHR ROM of a cartridge.
+// The CDL file contains one flag byte for every PRG ROM byte, followed by
+// one flag byte for every CHR ROM byte.
+type Logger struct {
+	mu sync.Mutex
+
+	prg []codedatalog.PrgFlag
+	chr []codedatalog.ChrFlag
+
+	codeFetch   bool   // whether an instruction is being fetched
+	codeAddress uint16 // address of the instruction that is being fetched
+	chrDataRead bool   // whether the CPU is reading CHR data through the PPU
+}
+
+// Usage contains the amount of logged bytes of a PRG bank.
+type Usage struct {
+	Code   int // bytes that were executed as opcode or operand
+	Data   int // bytes that were only read as data
+	Unused int // bytes that were not accessed
+}
+
+// New returns a new logger for a cartridge with the given PRG and CHR ROM sizes.
+// The CHR size is 0 for cartridges that use CHR RAM.
+func New(prgSize, chrSize int) *Logger {
+	return &Logger{
+		prg: make([]codedatalog.PrgFlag, prgSize),
+		chr: make([]codedatalog.ChrFlag, chrSize),
+	}
+}
+
+// SetCodeFetch marks the start of fetching the instruction at the given address,
+// the PRG reads of the instruction bytes are logged as code.
+func (l *Logger) SetCodeFetch(address uint16) {
+	l.mu.Lock()
+	l.codeFetch = true
+	l.codeAddress = address
+	l.mu.Unlock()
+}
+
+// ClearCodeFetch marks the end of fetching an instruction, all following PRG
+// reads are logged as data.
+func (l *Logger) ClearCodeFetch() {
+	l.mu.Lock()
+	l.codeFetch = false
+	l.mu.Unlock()
+}
+
+// SetChrDataRead sets whether the following CHR reads are done by the CPU
+// through the PPU data register instead of being rendered.
+func (l *Logger) SetChrDataRead(enabled bool) {
+	l.mu.Lock()
+	l.chrDataRead = enabled
+	l.mu.Unlock()
+}
+
+// LogPrgRead logs a read of the PRG ROM byte at the given offset that is mapped
+// to the given CPU address. The address defines the bank bits of the flags.
+func (l *Logger) LogPrgRead(offset int, address uint16) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if offset < 0 || offset >= len(l.prg) {
+		return
+	}
+
+	flag := codedatalog.Data
+	if l.codeFetch && address-l.codeAddress < maxInstructionSize {
+		flag = codedatalog.Code
+	}
+	if address >= codeBaseAddress {
+		// the bank bits contain the 8K window of the address space that the byte was mapped to
+		flag |= codedatalog.PrgFlag((address-codeBaseAddress)>>13) * codedatalog.RomBankMappedLow
+	}
+	l.prg[offset] |= flag
+}
+
+// LogChrRead logs a read of the CHR ROM byte at the given offset.
+func (l *Logger) LogChrRead(offset int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if offset < 0 || offset >= len(l.chr) {
+		return
+	}
+
+	if l.chrDataRead {
+		l.chr[offset] |= codedatalog.ReadProgrammatically
+	} else {
+		l.chr[offset] |= codedatalog.DrawnOnScreen
+	}
+}
+
+// Load merges the flags of a previously written CDL file into the log, which
+// allows to accumulate the log over multiple emulation sessions.
+func (l *Logger) Load(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("reading CDL data: %w", err)
+	}
+	if expected := len(l.prg) + len(l.chr); len(data) != expected {
+		return fmt.Errorf("%w: size %d does not match the expected size %d", ErrInvalidSize, len(data), expected)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.prg {
+		l.prg[i] |= codedatalog.PrgFlag(data[i])
+	}
+	for i := range l.chr {
+		l.chr[i] |= codedatalog.ChrFlag(data[len(l.prg)+i])
+	}
+	return nil
+}
+
+// Write writes the log in CDL format.
+func (l *Logger) Write(writer io.Writer) error {
+	l.mu.Lock()
+	data := make([]byte, 0, len(l.prg)+len(l.chr))
+	for _, flag := range l.prg {
+		data = append(data, byte(flag))
+	}
+	for _, flag := range l.chr {
+		data = append(data, byte(flag))
+	}
+	l.mu.Unlock()
+
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("writing CDL data: %w", err)
+	}
+	return nil
+}
+
+// Usage returns the code and data usage of every PRG bank of the given size.
+func (l *Logger) Usage(bankSize int) []Usage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var banks []Usage
+	for start := 0; start < len(l.prg); start += bankSize {
+		end := start + bankSize
+		if end > len(l.prg) {
+			end = len(l.prg)
+		}
+
+		var usage Usage
+		for _, flag := range l.prg[start:end] {
+			switch {
+			case flag&codedatalog.Code != 0:
+				usage.Code++
+			case flag&codedatalog.Data != 0:
+				usage.Data++
+			default:
+				usage.Unused++
+			}
+		}
+		banks = append(banks, usage)
+	}
+	return banks
+}
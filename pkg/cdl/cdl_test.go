@@ -0,0 +1,64 @@
+package cdl
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/arch/nes/codedatalog"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	l := New(0x8000, 0x2000)
+
+	l.SetCodeFetch(0xC000)
+	l.LogPrgRead(0x4000, 0xC000)
+	l.LogPrgRead(0x4001, 0xC001)
+	l.LogPrgRead(0x4100, 0xC100) // pointer of an indirect jmp
+	l.ClearCodeFetch()
+	l.LogPrgRead(0x4001, 0xC001)
+	l.LogPrgRead(0x0010, 0x8010)
+	l.LogPrgRead(0x8000, 0x8000) // out of range
+
+	l.LogChrRead(0x0010)
+	l.SetChrDataRead(true)
+	l.LogChrRead(0x0020)
+	l.SetChrDataRead(false)
+
+	var buf bytes.Buffer
+	assert.NoError(t, l.Write(&buf))
+	data := buf.Bytes()
+	assert.Equal(t, 0xA000, len(data))
+
+	bank := codedatalog.PrgFlag(2) * codedatalog.RomBankMappedLow
+	assert.Equal(t, byte(codedatalog.Code|bank), data[0x4000])
+	assert.Equal(t, byte(codedatalog.Code|codedatalog.Data|bank), data[0x4001])
+	assert.Equal(t, byte(codedatalog.Data|bank), data[0x4100])
+	assert.Equal(t, byte(codedatalog.Data), data[0x0010])
+	assert.Equal(t, byte(codedatalog.DrawnOnScreen), data[0x8010])
+	assert.Equal(t, byte(codedatalog.ReadProgrammatically), data[0x8020])
+
+	usage := l.Usage(0x4000)
+	assert.Equal(t, []Usage{
+		{Data: 1, Unused: 0x3FFF},
+		{Code: 2, Data: 1, Unused: 0x3FFD},
+	}, usage)
+}
+
+func TestLoggerLoad(t *testing.T) {
+	t.Parallel()
+
+	l := New(4, 2)
+	l.LogPrgRead(1, 0x8001)
+	assert.NoError(t, l.Load(bytes.NewReader([]byte{1, 0, 0, 0, 2, 0})))
+
+	var buf bytes.Buffer
+	assert.NoError(t, l.Write(&buf))
+	assert.Equal(t, []byte{1, 2, 0, 0, 2, 0}, buf.Bytes())
+
+	err := l.Load(bytes.NewReader([]byte{1}))
+	assert.True(t, errors.Is(err, ErrInvalidSize))
+}
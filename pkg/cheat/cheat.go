@@ -0,0 +1,209 @@
+// Package cheat implements RAM cheats and loading and saving of cheat files in
+// the FCEUX .cht format.
+package cheat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+)
+
+// ErrInvalidLine is returned when a line of a cheat file can not be parsed.
+var ErrInvalidLine = errors.New("invalid cheat line")
+
+// ErrNotConvertible is returned when a cheat can not be represented as Game Genie code.
+var ErrNotConvertible = errors.New("cheat can not be converted to a Game Genie code")
+
+// ErrInvalidAddress is returned when a cheat can not be applied to its address.
+var ErrInvalidAddress = errors.New("invalid cheat address")
+
+const (
+	ramMirrorSize     = 0x2000 // internal RAM including its mirrors at $0000-$1FFF
+	cartridgeAddress  = 0x4020 // start of the cartridge address space
+	freezeCheatRanges = "$0000-$1FFF and $6000-$7FFF"
+)
+
+// Cheat defines a cheat that either freezes a memory address to a value by writing
+// it every frame, or substitutes the value of all reads from the address.
+type Cheat struct {
+	Name       string
+	Address    uint16
+	Value      byte
+	Compare    byte
+	HasCompare bool // the cheat only applies if the current value matches the compare value
+	Substitute bool // the cheat substitutes reads instead of writing the memory every frame
+	Enabled    bool
+}
+
+// FromGameGenie converts a Game Genie code to a substitute cheat.
+func FromGameGenie(code string) (Cheat, error) {
+	patch, err := gamegenie.Decode(code)
+	if err != nil {
+		return Cheat{}, fmt.Errorf("decoding code: %w", err)
+	}
+
+	return Cheat{
+		Name:       strings.ToUpper(code),
+		Address:    patch.Address,
+		Value:      patch.Data,
+		Compare:    patch.Compare,
+		HasCompare: patch.HasCompare,
+		Substitute: true,
+		Enabled:    true,
+	}, nil
+}
+
+// GameGenie returns the Game Genie code of a substitute cheat of the cartridge
+// address space.
+func (c Cheat) GameGenie() (string, error) {
+	if !c.Substitute {
+		return "", fmt.Errorf("%w: RAM freeze cheat", ErrNotConvertible)
+	}
+
+	patch := gamegenie.Patch{
+		Address:    c.Address,
+		Data:       c.Value,
+		Compare:    c.Compare,
+		HasCompare: c.HasCompare,
+	}
+	code, err := gamegenie.Encode(patch)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNotConvertible, err.Error())
+	}
+	return code, nil
+}
+
+// Validate returns an error if the cheat can not be applied to its address.
+// Freeze cheats write the internal RAM or the PRG RAM, substitute cheats patch
+// reads from the cartridge address space starting at $4020.
+func (c Cheat) Validate() error {
+	if c.Substitute {
+		if c.Address < cartridgeAddress {
+			return fmt.Errorf("%w: substitute cheat at $%04X is outside of the cartridge address space",
+				ErrInvalidAddress, c.Address)
+		}
+		return nil
+	}
+
+	if c.Address < ramMirrorSize || (c.Address >= prgRAMAddress && c.Address < prgRAMAddress+prgRAMSize) {
+		return nil
+	}
+	return fmt.Errorf("%w: freeze cheat at $%04X is outside of %s",
+		ErrInvalidAddress, c.Address, freezeCheatRanges)
+}
+
+// String returns the cheat in the FCEUX .cht line format.
+func (c Cheat) String() string {
+	buf := strings.Builder{}
+	if c.Substitute {
+		buf.WriteByte('S')
+	}
+	if c.HasCompare {
+		buf.WriteByte('C')
+	}
+	if !c.Enabled {
+		buf.WriteByte(':')
+	}
+
+	buf.WriteString(fmt.Sprintf("%04x:%02x:", c.Address, c.Value))
+	if c.HasCompare {
+		buf.WriteString(fmt.Sprintf("%02x:", c.Compare))
+	}
+	buf.WriteString(c.Name)
+	return buf.String()
+}
+
+// Parse parses a cheat line in the FCEUX .cht format "[S][C][:]AAAA:VV[:CC]:Name".
+// The optional prefixes mark a substitute cheat, a cheat with compare value and
+// a disabled cheat. Cheats with an address that they can not be applied to are
+// rejected, see Validate.
+func Parse(line string) (Cheat, error) {
+	c := Cheat{Enabled: true}
+	s := line
+
+	if strings.HasPrefix(s, "S") {
+		c.Substitute = true
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, "C") {
+		c.HasCompare = true
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, ":") {
+		c.Enabled = false
+		s = s[1:]
+	}
+
+	fields := 3 // address, value, name
+	if c.HasCompare {
+		fields = 4
+	}
+	parts := strings.SplitN(s, ":", fields)
+	if len(parts) != fields {
+		return Cheat{}, fmt.Errorf("%w: %s", ErrInvalidLine, line)
+	}
+
+	address, err := strconv.ParseUint(parts[0], 16, 16)
+	if err != nil {
+		return Cheat{}, fmt.Errorf("%w: parsing address '%s': %s", ErrInvalidLine, parts[0], err.Error())
+	}
+	value, err := strconv.ParseUint(parts[1], 16, 8)
+	if err != nil {
+		return Cheat{}, fmt.Errorf("%w: parsing value '%s': %s", ErrInvalidLine, parts[1], err.Error())
+	}
+	c.Address = uint16(address)
+	c.Value = byte(value)
+
+	if c.HasCompare {
+		compare, err := strconv.ParseUint(parts[2], 16, 8)
+		if err != nil {
+			return Cheat{}, fmt.Errorf("%w: parsing compare '%s': %s", ErrInvalidLine, parts[2], err.Error())
+		}
+		c.Compare = byte(compare)
+	}
+
+	c.Name = parts[fields-1]
+	if err := c.Validate(); err != nil {
+		return Cheat{}, err
+	}
+	return c, nil
+}
+
+// Load reads all cheats of a .cht file, empty lines are ignored.
+func Load(reader io.Reader) ([]Cheat, error) {
+	var cheats []Cheat
+	scanner := bufio.NewScanner(reader)
+
+	for lineNr := 1; scanner.Scan(); lineNr++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		c, err := Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNr, err)
+		}
+		cheats = append(cheats, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading cheats: %w", err)
+	}
+	return cheats, nil
+}
+
+// Save writes the cheats in the .cht format.
+func Save(writer io.Writer, cheats []Cheat) error {
+	for _, c := range cheats {
+		if _, err := fmt.Fprintln(writer, c.String()); err != nil {
+			return fmt.Errorf("writing cheat: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cheat
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+const testFile = `0075:09:Infinite lives
+SC:9148:51:a5:Swim: in any level
+
+C0020:ff:00:Max energy
+`
+
+func TestLoadSave(t *testing.T) {
+	t.Parallel()
+
+	cheats, err := Load(strings.NewReader(testFile))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(cheats))
+
+	assert.Equal(t, Cheat{Name: "Infinite lives", Address: 0x0075, Value: 0x09, Enabled: true}, cheats[0])
+	assert.Equal(t, Cheat{
+		Name:       "Swim: in any level",
+		Address:    0x9148,
+		Value:      0x51,
+		Compare:    0xA5,
+		HasCompare: true,
+		Substitute: true,
+		Enabled:    false,
+	}, cheats[1])
+	assert.Equal(t, 0x0020, cheats[2].Address)
+	assert.True(t, cheats[2].HasCompare)
+	assert.Equal(t, 0x00, cheats[2].Compare)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Save(buf, cheats))
+	assert.Equal(t, strings.ReplaceAll(testFile, "\n\n", "\n"), buf.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, line := range []string{"0075:09", "007X:09:name", "C0075:09:name", "0075:100:name"} {
+		_, err := Parse(line)
+		assert.True(t, errors.Is(err, ErrInvalidLine))
+	}
+}
+
+func TestParseInvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	for _, line := range []string{"2000:09:PPU", "4016:01:Controller", "8000:ea:ROM", "S0075:09:RAM"} {
+		_, err := Parse(line)
+		assert.True(t, errors.Is(err, ErrInvalidAddress))
+	}
+	for _, line := range []string{"1fff:09:RAM mirror", "7fff:09:PRG RAM", "S6000:09:PRG RAM", "S4020:09:FDS"} {
+		_, err := Parse(line)
+		assert.NoError(t, err)
+	}
+}
+
+func TestGameGenie(t *testing.T) {
+	t.Parallel()
+
+	c, err := FromGameGenie("zexpygla")
+	assert.NoError(t, err)
+	assert.Equal(t, "ZEXPYGLA", c.Name)
+	assert.Equal(t, 0x94A7, c.Address)
+	assert.Equal(t, 0x02, c.Value)
+	assert.Equal(t, 0x03, c.Compare)
+	assert.True(t, c.HasCompare)
+	assert.True(t, c.Substitute)
+
+	code, err := c.GameGenie()
+	assert.NoError(t, err)
+	assert.Equal(t, "ZEXPYGLA", code)
+
+	_, err = Cheat{Address: 0x0075, Value: 0x09}.GameGenie()
+	assert.True(t, errors.Is(err, ErrNotConvertible))
+	_, err = Cheat{Address: 0x0075, Value: 0x09, Substitute: true}.GameGenie()
+	assert.True(t, errors.Is(err, ErrNotConvertible))
+}
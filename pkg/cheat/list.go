@@ -0,0 +1,122 @@
+package cheat
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+)
+
+// ErrInvalidIndex is returned when a cheat of the list is changed that does not exist.
+var ErrInvalidIndex = errors.New("invalid cheat index")
+
+// List contains cheats and applies the enabled ones. It is safe for concurrent use.
+type List struct {
+	mu     sync.Mutex
+	cheats []Cheat
+
+	freeze     atomic.Value // []Cheat of all enabled freeze cheats
+	substitute atomic.Value // map[uint16][]Cheat of all enabled substitute cheats
+}
+
+// NewList returns a new empty list.
+func NewList() *List {
+	l := &List{}
+	l.updateActive()
+	return l
+}
+
+// Add adds a cheat to the list. Cheats with an address that they can not be
+// applied to are rejected, see Cheat.Validate.
+func (l *List) Add(c Cheat) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cheats = append(l.cheats, c)
+	l.updateActive()
+	return nil
+}
+
+// SetEnabled enables or disables the cheat with the given index.
+func (l *List) SetEnabled(index int, enabled bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index < 0 || index >= len(l.cheats) {
+		return fmt.Errorf("%w: %d", ErrInvalidIndex, index)
+	}
+	l.cheats[index].Enabled = enabled
+	l.updateActive()
+	return nil
+}
+
+// Toggle enables a disabled or disables an enabled cheat and returns whether
+// the cheat is enabled now.
+func (l *List) Toggle(index int) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index < 0 || index >= len(l.cheats) {
+		return false, fmt.Errorf("%w: %d", ErrInvalidIndex, index)
+	}
+	l.cheats[index].Enabled = !l.cheats[index].Enabled
+	l.updateActive()
+	return l.cheats[index].Enabled, nil
+}
+
+// Cheats returns a copy of all cheats of the list.
+func (l *List) Cheats() []Cheat {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Cheat(nil), l.cheats...)
+}
+
+// Freeze writes the values of all enabled freeze cheats to the memory, it is
+// called once per frame. Cheats with a compare value only write the value if
+// the current value matches.
+func (l *List) Freeze(memory bus.BasicMemory) {
+	for _, c := range l.freeze.Load().([]Cheat) {
+		if c.HasCompare && memory.Read(c.Address) != c.Compare {
+			continue
+		}
+		memory.Write(c.Address, c.Value)
+	}
+}
+
+// Apply returns the value to read from the given address, the value is replaced
+// by enabled substitute cheats of the address.
+func (l *List) Apply(address uint16, value byte) byte {
+	substitute := l.substitute.Load().(map[uint16][]Cheat)
+	for _, c := range substitute[address] {
+		if !c.HasCompare || c.Compare == value {
+			return c.Value
+		}
+	}
+	return value
+}
+
+// updateActive replaces the active cheats, the caller has to hold the lock.
+func (l *List) updateActive() {
+	var freeze []Cheat
+	substitute := map[uint16][]Cheat{}
+
+	for _, c := range l.cheats {
+		switch {
+		case !c.Enabled:
+		case c.Substitute:
+			substitute[c.Address] = append(substitute[c.Address], c)
+		default:
+			freeze = append(freeze, c)
+		}
+	}
+
+	l.freeze.Store(freeze)
+	l.substitute.Store(substitute)
+}
@@ -0,0 +1,59 @@
+package cheat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+type testMemory map[uint16]uint8
+
+func (m testMemory) Read(address uint16) uint8         { return m[address] }
+func (m testMemory) Write(address uint16, value uint8) { m[address] = value }
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	l := NewList()
+	for _, c := range []Cheat{
+		{Address: 0x0075, Value: 0x09, Enabled: true},
+		{Address: 0x0076, Value: 0x10, Compare: 0x01, HasCompare: true, Enabled: true},
+		{Address: 0x8000, Value: 0xEA, Substitute: true, Enabled: true},
+		{Address: 0x0077, Value: 0x20},
+	} {
+		assert.NoError(t, l.Add(c))
+	}
+
+	memory := testMemory{0x0076: 0x02}
+	l.Freeze(memory)
+	assert.Equal(t, 0x09, memory[0x0075])
+	assert.Equal(t, 0x02, memory[0x0076])
+	assert.Equal(t, 0x00, memory[0x0077])
+
+	memory[0x0076] = 0x01
+	l.Freeze(memory)
+	assert.Equal(t, 0x10, memory[0x0076])
+
+	assert.Equal(t, 0xEA, l.Apply(0x8000, 0x00))
+	assert.Equal(t, 0x00, l.Apply(0x8001, 0x00))
+
+	enabled, err := l.Toggle(2)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	assert.Equal(t, 0x00, l.Apply(0x8000, 0x00))
+
+	assert.NoError(t, l.SetEnabled(3, true))
+	l.Freeze(memory)
+	assert.Equal(t, 0x20, memory[0x0077])
+	assert.Equal(t, 4, len(l.Cheats()))
+
+	err = l.SetEnabled(4, true)
+	assert.True(t, errors.Is(err, ErrInvalidIndex))
+
+	err = l.Add(Cheat{Address: 0x2000, Value: 0x01, Enabled: true})
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+	err = l.Add(Cheat{Address: 0x0075, Value: 0x01, Substitute: true, Enabled: true})
+	assert.True(t, errors.Is(err, ErrInvalidAddress))
+	assert.Equal(t, 4, len(l.Cheats()))
+}
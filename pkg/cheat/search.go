@@ -0,0 +1,192 @@
+package cheat
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	ramSize       = 0x0800
+	prgRAMAddress = 0x6000
+	prgRAMSize    = 0x2000 // only the PRG RAM that is mapped at $6000-$7FFF is searched
+)
+
+// ErrUnknownFilter is returned when an unsupported search filter is used.
+var ErrUnknownFilter = errors.New("unknown search filter")
+
+// Filter defines a search filter that compares the current value of a memory
+// address with the value of the previous search step.
+type Filter string
+
+// Supported search filters.
+const (
+	Equal     Filter = "equal"     // value did not change
+	Changed   Filter = "changed"   // value changed
+	Increased Filter = "increased" // value increased
+	Decreased Filter = "decreased" // value decreased
+	Value     Filter = "value"     // value matches the given value
+)
+
+// Snapshot contains the content of the searchable memory.
+type Snapshot struct {
+	RAM    []byte // 2K internal RAM at $0000
+	PrgRAM []byte // PRG RAM of the cartridge that is mapped at $6000-$7FFF
+}
+
+// Result is a search candidate with its value of the previous and current search step.
+type Result struct {
+	Address  uint16
+	Previous byte
+	Value    byte
+}
+
+// Search implements a RAM search that narrows down the addresses of game variables
+// by comparing memory snapshots with filters. It is safe for concurrent use.
+type Search struct {
+	mu         sync.Mutex
+	previous   []byte
+	current    []byte
+	candidates []int // indexes of the remaining addresses in the snapshot data
+}
+
+// NewSearch returns a new search that uses the snapshot as start values
+// with all addresses being candidates.
+func NewSearch(snapshot Snapshot) *Search {
+	s := &Search{}
+	s.Reset(snapshot)
+	return s
+}
+
+// Reset restarts the search with the snapshot as start values.
+func (s *Search) Reset(snapshot Snapshot) {
+	data := snapshot.data()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.previous = data
+	s.current = data
+	s.candidates = make([]int, len(data))
+	for i := range s.candidates {
+		s.candidates[i] = i
+	}
+}
+
+// Filter compares the snapshot with the values of the previous search step and
+// removes all candidates that do not match the filter. The value is only used for
+// the value filter. It returns the amount of remaining candidates.
+func (s *Search) Filter(snapshot Snapshot, filter Filter, value byte) (int, error) {
+	match, err := filterFunc(filter, value)
+	if err != nil {
+		return 0, err
+	}
+	data := snapshot.data()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidates := s.candidates[:0]
+	for _, i := range s.candidates {
+		if i < len(data) && match(s.current[i], data[i]) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	s.candidates = candidates
+	s.previous = s.current
+	s.current = data
+	return len(candidates), nil
+}
+
+// Results returns up to limit remaining candidates, a limit of 0 returns all.
+func (s *Search) Results(limit int) []Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.candidates)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	results := make([]Result, 0, count)
+	for _, i := range s.candidates[:count] {
+		results = append(results, Result{
+			Address:  snapshotAddress(i),
+			Previous: s.previous[i],
+			Value:    s.current[i],
+		})
+	}
+	return results
+}
+
+// Value returns the value of the address in the snapshot of the last search
+// step. It returns false if the address is not part of the searched memory.
+func (s *Search) Value(address uint16) (byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, ok := snapshotIndex(address)
+	if !ok || index >= len(s.current) {
+		return 0, false
+	}
+	return s.current[index], true
+}
+
+// Count returns the amount of remaining candidates.
+func (s *Search) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.candidates)
+}
+
+func filterFunc(filter Filter, value byte) (func(previous, current byte) bool, error) {
+	switch filter {
+	case Equal:
+		return func(previous, current byte) bool { return current == previous }, nil
+	case Changed:
+		return func(previous, current byte) bool { return current != previous }, nil
+	case Increased:
+		return func(previous, current byte) bool { return current > previous }, nil
+	case Decreased:
+		return func(previous, current byte) bool { return current < previous }, nil
+	case Value:
+		return func(previous, current byte) bool { return current == value }, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFilter, filter)
+	}
+}
+
+// data returns a copy of the searchable memory, the internal RAM followed by the
+// PRG RAM that is mapped at $6000-$7FFF.
+func (s Snapshot) data() []byte {
+	prgRAM := s.PrgRAM
+	if len(prgRAM) > prgRAMSize {
+		prgRAM = prgRAM[:prgRAMSize]
+	}
+
+	data := make([]byte, 0, len(s.RAM)+len(prgRAM))
+	data = append(data, s.RAM...)
+	return append(data, prgRAM...)
+}
+
+// snapshotAddress returns the memory address of an index of the snapshot data.
+func snapshotAddress(index int) uint16 {
+	if index < ramSize {
+		return uint16(index)
+	}
+	return uint16(prgRAMAddress + index - ramSize)
+}
+
+// snapshotIndex returns the index of the snapshot data of a memory address.
+func snapshotIndex(address uint16) (int, bool) {
+	switch {
+	case address < ramSize:
+		return int(address), true
+	case address >= prgRAMAddress && address < prgRAMAddress+prgRAMSize:
+		return ramSize + int(address-prgRAMAddress), true
+	default:
+		return 0, false
+	}
+}
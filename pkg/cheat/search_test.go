@@ -0,0 +1,71 @@
+package cheat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	ram := make([]byte, ramSize)
+	prgRAM := make([]byte, prgRAMSize)
+	ram[0x0075] = 3
+	ram[0x0076] = 3
+	prgRAM[0x0010] = 3
+	s := NewSearch(Snapshot{RAM: ram, PrgRAM: prgRAM})
+	assert.Equal(t, ramSize+prgRAMSize, s.Count())
+
+	count, err := s.Filter(Snapshot{RAM: ram, PrgRAM: prgRAM}, Value, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	ram[0x0075] = 2
+	prgRAM[0x0010] = 2
+	count, err = s.Filter(Snapshot{RAM: ram, PrgRAM: prgRAM}, Decreased, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	results := s.Results(0)
+	assert.Equal(t, []Result{
+		{Address: 0x0075, Previous: 3, Value: 2},
+		{Address: 0x6010, Previous: 3, Value: 2},
+	}, results)
+
+	prgRAM[0x0010] = 5
+	count, err = s.Filter(Snapshot{RAM: ram, PrgRAM: prgRAM}, Equal, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 0x0075, s.Results(1)[0].Address)
+
+	value, ok := s.Value(0x6010)
+	assert.True(t, ok)
+	assert.Equal(t, 5, value)
+	_, ok = s.Value(0x2002)
+	assert.False(t, ok)
+
+	_, err = s.Filter(Snapshot{RAM: ram, PrgRAM: prgRAM}, "bigger", 0)
+	assert.True(t, errors.Is(err, ErrUnknownFilter))
+
+	s.Reset(Snapshot{RAM: ram})
+	assert.Equal(t, ramSize, s.Count())
+	count, err = s.Filter(Snapshot{RAM: ram}, Changed, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestSearchIncreased(t *testing.T) {
+	t.Parallel()
+
+	ram := make([]byte, ramSize)
+	s := NewSearch(Snapshot{RAM: ram, PrgRAM: make([]byte, 4*prgRAMSize)})
+	assert.Equal(t, ramSize+prgRAMSize, s.Count())
+
+	ram[0x0100] = 1
+	count, err := s.Filter(Snapshot{RAM: ram}, Increased, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []Result{{Address: 0x0100, Previous: 0, Value: 1}}, s.Results(10))
+}
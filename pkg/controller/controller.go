@@ -81,3 +81,14 @@ func (c *Controller) SetButtonState(key Button, pressed bool) {
 	}
 	atomic.StoreUint64(&c.buttons, state)
 }
+
+// Buttons returns the state of all buttons.
+func (c *Controller) Buttons() Button {
+	return Button(atomic.LoadUint64(&c.buttons))
+}
+
+// SetButtons sets the state of all buttons, all passed buttons are pressed,
+// all others are released.
+func (c *Controller) SetButtons(buttons Button) {
+	atomic.StoreUint64(&c.buttons, uint64(buttons))
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/retroenv/retrogolib/assert"
@@ -28,3 +29,13 @@ func TestController(t *testing.T) {
 	assert.Equal(t, 0, c.Read())
 	assert.Equal(t, 0, c.Read())
 }
+
+func TestParseDeviceType(t *testing.T) {
+	d, err := ParseDeviceType("Zapper")
+	assert.NoError(t, err)
+	assert.Equal(t, DeviceZapper, d)
+	assert.Equal(t, "zapper", d.String())
+
+	_, err = ParseDeviceType("keyboard")
+	assert.True(t, errors.Is(err, ErrUnknownDevice))
+}
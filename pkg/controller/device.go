@@ -0,0 +1,44 @@
+//go:build !nesgo
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownDevice is returned when an input device name can not be parsed.
+var ErrUnknownDevice = errors.New("unknown input device")
+
+// DeviceType defines the type of an input device that is connected to a controller port.
+type DeviceType int
+
+const (
+	DeviceGamepad        DeviceType = iota // standard NES controller
+	DeviceZapper                           // light gun
+	DeviceArkanoidPaddle                   // Arkanoid Vaus controller
+	DevicePowerPad                         // Power Pad / Family Fun Fitness mat
+)
+
+var deviceNames = map[DeviceType]string{
+	DeviceGamepad:        "gamepad",
+	DeviceZapper:         "zapper",
+	DeviceArkanoidPaddle: "paddle",
+	DevicePowerPad:       "powerpad",
+}
+
+// String returns the name of the device type.
+func (d DeviceType) String() string {
+	return deviceNames[d]
+}
+
+// ParseDeviceType returns the device type for the given name.
+func ParseDeviceType(name string) (DeviceType, error) {
+	for d, s := range deviceNames {
+		if strings.EqualFold(name, s) {
+			return d, nil
+		}
+	}
+	return DeviceGamepad, fmt.Errorf("%w '%s'", ErrUnknownDevice, name)
+}
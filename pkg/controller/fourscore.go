@@ -0,0 +1,63 @@
+//go:build !nesgo
+
+package controller
+
+const (
+	fourScoreBits       = 24
+	fourScoreSignature1 = 0b0000_1000 // signature returned on port 1, read LSB first as 0,0,0,1,0,0,0,0
+	fourScoreSignature2 = 0b0000_0100 // signature returned on port 2, read LSB first as 0,0,1,0,0,0,0,0
+)
+
+// FourScore implements the Four Score and NES Satellite multitap adapters that
+// connect 4 gamepads to both controller ports. Every port returns the buttons
+// of 2 gamepads, followed by a signature that identifies the adapter.
+type FourScore struct {
+	ports [2]*FourScorePort
+}
+
+// FourScorePort implements a controller port of a Four Score adapter.
+type FourScorePort struct {
+	first      *Controller
+	second     *Controller
+	signature  uint32
+	strobeMode bool
+	index      int
+}
+
+// NewFourScore returns a new Four Score adapter for the given 4 gamepads.
+func NewFourScore(gamepads [4]*Controller) *FourScore {
+	return &FourScore{
+		ports: [2]*FourScorePort{
+			{first: gamepads[0], second: gamepads[2], signature: fourScoreSignature1},
+			{first: gamepads[1], second: gamepads[3], signature: fourScoreSignature2},
+		},
+	}
+}
+
+// Port returns the device that is connected to the given controller port (1 or 2).
+func (f *FourScore) Port(port int) *FourScorePort {
+	return f.ports[port-1]
+}
+
+// SetStrobeMode sets the strobe mode flag of the port.
+func (p *FourScorePort) SetStrobeMode(mode uint8) {
+	p.strobeMode = mode&1 == 1
+	if p.strobeMode {
+		p.index = 0
+	}
+}
+
+// Read returns the next bit of the serial data of the port.
+func (p *FourScorePort) Read() uint8 {
+	if p.strobeMode {
+		return uint8(p.first.Buttons() & A)
+	}
+	if p.index >= fourScoreBits {
+		return 1
+	}
+
+	data := uint32(p.first.Buttons()&0xff) | uint32(p.second.Buttons()&0xff)<<8 | p.signature<<16
+	bit := uint8(data>>p.index) & 1
+	p.index++
+	return bit
+}
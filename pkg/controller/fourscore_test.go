@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func readBits(device interface{ Read() uint8 }, count int) []uint8 {
+	bits := make([]uint8, count)
+	for i := range bits {
+		bits[i] = device.Read()
+	}
+	return bits
+}
+
+func TestFourScore(t *testing.T) {
+	gamepads := [4]*Controller{New(), New(), New(), New()}
+	gamepads[0].SetButtons(A)
+	gamepads[2].SetButtons(Start)
+	gamepads[3].SetButtons(B | Right)
+	f := NewFourScore(gamepads)
+
+	port1 := f.Port(1)
+	port1.SetStrobeMode(1)
+	assert.Equal(t, 1, port1.Read())
+	port1.SetStrobeMode(0)
+	expected := []uint8{
+		1, 0, 0, 0, 0, 0, 0, 0, // player 1
+		0, 0, 0, 1, 0, 0, 0, 0, // player 3
+		0, 0, 0, 1, 0, 0, 0, 0, // signature
+		1, 1,
+	}
+	assert.Equal(t, expected, readBits(port1, len(expected)))
+
+	port2 := f.Port(2)
+	port2.SetStrobeMode(1)
+	port2.SetStrobeMode(0)
+	expected = []uint8{
+		0, 0, 0, 0, 0, 0, 0, 0, // player 2
+		0, 1, 0, 0, 0, 0, 0, 1, // player 4
+		0, 0, 1, 0, 0, 0, 0, 0, // signature
+		1,
+	}
+	assert.Equal(t, expected, readBits(port2, len(expected)))
+}
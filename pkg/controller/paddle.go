@@ -0,0 +1,65 @@
+//go:build !nesgo
+
+package controller
+
+import "sync/atomic"
+
+const (
+	paddleData   = 0b0000_1000 // serial potentiometer data
+	paddleButton = 0b0001_0000 // 1 if the fire button is pressed
+)
+
+// ArkanoidPaddle implements the NES Arkanoid Vaus controller. The position of
+// the potentiometer gets latched by the strobe and is returned inverted and
+// with the most significant bit first.
+type ArkanoidPaddle struct {
+	// set by the GUI goroutine and read by the emulator, accessed atomically
+	position uint32
+	button   uint32
+
+	latched byte
+	index   int
+}
+
+// NewArkanoidPaddle returns a new Arkanoid paddle.
+func NewArkanoidPaddle() *ArkanoidPaddle {
+	return &ArkanoidPaddle{}
+}
+
+// SetPosition sets the position of the potentiometer. Arkanoid expects values
+// in the range of about 98 for the leftmost to 242 for the rightmost position.
+func (p *ArkanoidPaddle) SetPosition(position byte) {
+	atomic.StoreUint32(&p.position, uint32(position))
+}
+
+// SetButton sets the state of the fire button.
+func (p *ArkanoidPaddle) SetButton(pressed bool) {
+	var value uint32
+	if pressed {
+		value = 1
+	}
+	atomic.StoreUint32(&p.button, value)
+}
+
+// SetStrobeMode latches the current position if the strobe mode is set.
+func (p *ArkanoidPaddle) SetStrobeMode(mode uint8) {
+	if mode&1 == 1 {
+		p.latched = ^byte(atomic.LoadUint32(&p.position))
+		p.index = 0
+	}
+}
+
+// Read returns the next bit of the latched position and the button state.
+func (p *ArkanoidPaddle) Read() uint8 {
+	var value uint8
+	if p.index < 8 {
+		if p.latched&(0x80>>p.index) != 0 {
+			value |= paddleData
+		}
+		p.index++
+	}
+	if atomic.LoadUint32(&p.button) == 1 {
+		value |= paddleButton
+	}
+	return value
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestArkanoidPaddle(t *testing.T) {
+	p := NewArkanoidPaddle()
+	p.SetPosition(0b1010_1100)
+	p.SetButton(true)
+
+	p.SetStrobeMode(1)
+	p.SetStrobeMode(0)
+
+	// position is returned inverted with the most significant bit first
+	expected := []uint8{0, 1, 0, 1, 0, 0, 1, 1, 0}
+	for _, bit := range expected {
+		assert.Equal(t, bit<<3|paddleButton, p.Read())
+	}
+
+	p.SetButton(false)
+	p.SetPosition(0xff)
+	assert.Equal(t, 0, p.Read())
+	p.SetStrobeMode(1)
+	assert.Equal(t, 0, p.Read())
+}
@@ -0,0 +1,73 @@
+//go:build !nesgo
+
+package controller
+
+import "sync/atomic"
+
+const (
+	powerPadButtons = 12
+	powerPadD3      = 0b0000_1000
+	powerPadD4      = 0b0001_0000
+)
+
+// order of the buttons that are returned serially on the data lines D3 and D4,
+// after the listed buttons the lines return 1.
+var (
+	powerPadOrderD3 = [8]int{2, 1, 5, 9, 6, 10, 11, 7}
+	powerPadOrderD4 = [4]int{4, 3, 12, 8}
+)
+
+// PowerPad implements the Power Pad and Family Fun Fitness mat with 12 buttons,
+// numbered 1 to 12 as on side B of the mat.
+type PowerPad struct {
+	buttons uint32 // button bits, accessed atomically
+
+	strobeMode bool
+	index      int
+}
+
+// NewPowerPad returns a new Power Pad.
+func NewPowerPad() *PowerPad {
+	return &PowerPad{}
+}
+
+// SetButtonState sets the state of the given button 1 to 12.
+func (p *PowerPad) SetButtonState(button int, pressed bool) {
+	if button < 1 || button > powerPadButtons {
+		return
+	}
+
+	state := atomic.LoadUint32(&p.buttons)
+	if pressed {
+		state |= 1 << button
+	} else {
+		state &= ^uint32(1 << button)
+	}
+	atomic.StoreUint32(&p.buttons, state)
+}
+
+// SetStrobeMode sets the strobe mode flag of the Power Pad.
+func (p *PowerPad) SetStrobeMode(mode uint8) {
+	p.strobeMode = mode&1 == 1
+	if p.strobeMode {
+		p.index = 0
+	}
+}
+
+// Read returns the next bits of both data lines.
+func (p *PowerPad) Read() uint8 {
+	index := p.index
+	if !p.strobeMode && p.index < len(powerPadOrderD3) {
+		p.index++
+	}
+
+	state := atomic.LoadUint32(&p.buttons)
+	var value uint8
+	if index >= len(powerPadOrderD3) || state&(1<<powerPadOrderD3[index]) != 0 {
+		value |= powerPadD3
+	}
+	if index >= len(powerPadOrderD4) || state&(1<<powerPadOrderD4[index]) != 0 {
+		value |= powerPadD4
+	}
+	return value
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestPowerPad(t *testing.T) {
+	p := NewPowerPad()
+	p.SetButtonState(1, true)
+	p.SetButtonState(3, true)
+	p.SetButtonState(7, true)
+	p.SetButtonState(12, true)
+	p.SetButtonState(12, false)
+
+	p.SetStrobeMode(1)
+	p.SetStrobeMode(0)
+
+	// D3 returns buttons 2, 1, 5, 9, 6, 10, 11, 7 and D4 returns buttons 4, 3, 12, 8
+	expected := []uint8{
+		0,
+		powerPadD3 | powerPadD4,
+		0,
+		0,
+		powerPadD4,
+		powerPadD4,
+		powerPadD4,
+		powerPadD3 | powerPadD4,
+		powerPadD3 | powerPadD4,
+	}
+	assert.Equal(t, expected, readBits(p, len(expected)))
+}
@@ -0,0 +1,114 @@
+//go:build !nesgo
+
+package controller
+
+import (
+	"image/color"
+	"sync/atomic"
+)
+
+const (
+	zapperLightSense = 0b0000_1000 // 0 if light is detected
+	zapperTrigger    = 0b0001_0000 // 1 if the trigger is pulled
+
+	// amount of scanlines that the light sensor detects the light after the
+	// beam has passed the aimed position
+	zapperLightScanLines = 26
+	zapperRadius         = 2
+	zapperBrightness     = 0xC0
+
+	screenWidth  = 256
+	screenHeight = 240
+)
+
+// LightSource provides access to the picture that is currently being rendered.
+type LightSource interface {
+	ScanLine() int
+	Pixel(x, y int) color.RGBA
+}
+
+// Zapper implements the Zapper light gun. It detects light if the aimed
+// position of the picture is bright and has been rendered recently.
+type Zapper struct {
+	light LightSource
+
+	// set by the GUI goroutine and read by the emulator, accessed atomically
+	x       int32
+	y       int32
+	trigger uint32
+}
+
+// NewZapper returns a new Zapper that aims at the picture of the given light source.
+func NewZapper(light LightSource) *Zapper {
+	return &Zapper{
+		light: light,
+		x:     -1,
+		y:     -1,
+	}
+}
+
+// Aim sets the screen position that the Zapper aims at, positions outside
+// of the screen aim away from the screen.
+func (z *Zapper) Aim(x, y int) {
+	atomic.StoreInt32(&z.x, int32(x))
+	atomic.StoreInt32(&z.y, int32(y))
+}
+
+// SetTrigger sets the state of the trigger.
+func (z *Zapper) SetTrigger(pulled bool) {
+	var value uint32
+	if pulled {
+		value = 1
+	}
+	atomic.StoreUint32(&z.trigger, value)
+}
+
+// SetStrobeMode is ignored by the Zapper.
+func (z *Zapper) SetStrobeMode(mode uint8) {
+}
+
+// Read returns the state of the light sensor and the trigger.
+func (z *Zapper) Read() uint8 {
+	var value uint8
+	if !z.lightDetected() {
+		value |= zapperLightSense
+	}
+	if atomic.LoadUint32(&z.trigger) == 1 {
+		value |= zapperTrigger
+	}
+	return value
+}
+
+// lightDetected returns whether a bright pixel is close to the aimed position
+// that the beam has passed within the last scanlines.
+func (z *Zapper) lightDetected() bool {
+	x := int(atomic.LoadInt32(&z.x))
+	y := int(atomic.LoadInt32(&z.y))
+	if x < 0 || y < 0 || x >= screenWidth || y >= screenHeight {
+		return false
+	}
+
+	scanLine := z.light.ScanLine()
+	if y > scanLine || y < scanLine-zapperLightScanLines {
+		return false
+	}
+
+	for py := y - zapperRadius; py <= y+zapperRadius; py++ {
+		if py < 0 || py > scanLine || py >= screenHeight {
+			continue
+		}
+		for px := x - zapperRadius; px <= x+zapperRadius; px++ {
+			if px < 0 || px >= screenWidth {
+				continue
+			}
+			if brightness(z.light.Pixel(px, py)) >= zapperBrightness {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func brightness(c color.RGBA) int {
+	return (int(c.R)*299 + int(c.G)*587 + int(c.B)*114) / 1000
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+type testLightSource struct {
+	scanLine int
+	bright   map[[2]int]bool
+}
+
+func (l *testLightSource) ScanLine() int {
+	return l.scanLine
+}
+
+func (l *testLightSource) Pixel(x, y int) color.RGBA {
+	if l.bright[[2]int{x, y}] {
+		return color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	return color.RGBA{A: 0xff}
+}
+
+func TestZapper(t *testing.T) {
+	light := &testLightSource{
+		scanLine: 100,
+		bright:   map[[2]int]bool{{50, 90}: true},
+	}
+	z := NewZapper(light)
+	assert.Equal(t, zapperLightSense, z.Read())
+
+	z.Aim(51, 91)
+	assert.Equal(t, 0, z.Read())
+
+	z.SetTrigger(true)
+	assert.Equal(t, zapperTrigger, z.Read())
+	z.SetTrigger(false)
+
+	// the beam has not reached the aimed position yet
+	light.scanLine = 80
+	assert.Equal(t, zapperLightSense, z.Read())
+
+	// the light has faded
+	light.scanLine = 91 + zapperLightScanLines + 1
+	assert.Equal(t, zapperLightSense, z.Read())
+
+	light.scanLine = 100
+	z.Aim(60, 90)
+	assert.Equal(t, zapperLightSense, z.Read())
+	z.Aim(-1, -1)
+	assert.Equal(t, zapperLightSense, z.Read())
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/region"
 	"github.com/retroenv/retrogolib/arch/nes/parameter"
 )
 
@@ -40,17 +41,36 @@ type CPU struct {
 	triggerIrq bool
 	triggerNmi bool
 
-	cycles      uint64
-	stallCycles uint16 // TODO stall cycles, use a Step() function
+	// state for the interrupt polling, the interrupt flag is polled before the
+	// last cycle of an instruction, which delays the effect of CLI, SEI and PLP
+	irqInhibit      uint8
+	nmiTriggerCycle uint64
+
+	cycles       uint64
+	syncedCycles uint64 // cycles that the PPU has been advanced for
+	stallCycles  uint16
+
+	// ratio of PPU cycles per CPU cycles and the remainder of PPU cycles
+	// that were not executed yet for ratios that are not a whole number
+	ppuCycles    int
+	ppuCPUCycles int
+	ppuRemainder int
 
 	tracing        TracingMode
 	tracingTarget  io.Writer
 	TraceStep      TraceStep
 	paramConverter parameter.Converter
 	lastFunction   string
+
+	traceOptions     TraceOptions
+	traceWaiting     bool     // tracing waits for the start address to be executed
+	traceBuffer      []string // ring buffer of the last trace lines
+	traceBufferIndex int      // index of the oldest line of the full ring buffer
 }
 
-// New creates a new CPU.
+// New creates a new CPU. The cycles of the reset sequence are counted but not
+// synchronized to the PPU yet, which has to be done by calling SyncPPU once
+// the PPU is connected to the bus.
 func New(bus *bus.Bus, nmiHandler, irqHandler *func(), emulator bool) *CPU {
 	c := &CPU{
 		SP:             InitialStack,
@@ -59,6 +79,8 @@ func New(bus *bus.Bus, nmiHandler, irqHandler *func(), emulator bool) *CPU {
 		irqHandler:     irqHandler,
 		nmiHandler:     nmiHandler,
 		cycles:         initialCycles,
+		ppuCycles:      3,
+		ppuCPUCycles:   1,
 		paramConverter: parameter.New(),
 	}
 
@@ -68,6 +90,7 @@ func New(bus *bus.Bus, nmiHandler, irqHandler *func(), emulator bool) *CPU {
 	c.irqAddress = bus.Memory.ReadWord(0xFFFE)
 
 	c.setFlags(initialFlags)
+	c.irqInhibit = c.Flags.I
 	return c
 }
 
@@ -77,10 +100,19 @@ func (c *CPU) SetTracing(mode TracingMode, target io.Writer) {
 	c.tracingTarget = target
 }
 
+// SetRegion sets the region that defines the ratio of PPU cycles per CPU cycle.
+func (c *CPU) SetRegion(r region.Region) {
+	timing := r.Timing()
+	c.ppuCycles = timing.PPUCycles
+	c.ppuCPUCycles = timing.CPUCycles
+	c.ppuRemainder = 0
+}
+
 // ResetCycles sets the cycle counter to 0.
 // This is useful for counting used CPU cycles for a function.
 func (c *CPU) ResetCycles() {
 	c.cycles = 0
+	c.syncedCycles = 0
 }
 
 // Cycles returns the amount of CPU cycles executed since system start.
@@ -98,9 +130,17 @@ func (c *CPU) TriggerIrq() {
 	c.triggerIrq = true
 }
 
+// ClearIrq clears a pending interrupt request that has not been executed yet.
+func (c *CPU) ClearIrq() {
+	c.triggerIrq = false
+}
+
 // TriggerNMI causes a non-maskable interrupt to occur on the next cycle.
+// If the NMI is triggered in the last cycle of an instruction, the NMI gets
+// executed after the following instruction.
 func (c *CPU) TriggerNMI() {
 	c.triggerNmi = true
+	c.nmiTriggerCycle = c.syncedCycles
 }
 
 // writeLock takes the mutex write lock and returns a function to write unlock to allow an easy use for
@@ -474,6 +474,8 @@ func (c *CPU) Rti() {
 	b |= 0b0010_0000 // unused flag is set
 	c.setFlags(b)
 	c.PC = c.Pop16()
+	// the restored interrupt flag takes effect immediately, unlike for PLP
+	c.irqInhibit = c.Flags.I
 
 	// lock is already taken
 	c.irqRunning = false
@@ -1,13 +1,21 @@
 package cpu
 
 // CheckInterrupts checks for triggered interrupts and executes them.
-func (c *CPU) CheckInterrupts() {
-	if c.triggerNmi {
+// It returns whether an interrupt was executed.
+// The interrupt lines are polled before the last cycle of an instruction,
+// an NMI that was triggered in the last cycle of the previous instruction is
+// delayed by one instruction. An IRQ is only executed if the interrupt flag
+// was cleared at the time of polling.
+func (c *CPU) CheckInterrupts() bool {
+	if c.triggerNmi && c.nmiTriggerCycle+1 < c.cycles {
 		c.nmi()
+		return true
 	}
-	if c.triggerIrq {
+	if c.triggerIrq && c.irqInhibit == 0 {
 		c.irq()
+		return true
 	}
+	return false
 }
 
 func (c *CPU) nmi() {
@@ -34,6 +42,7 @@ func (c *CPU) executeInterrupt(goFun *func(), funAddress uint16) {
 
 	if *goFun != nil {
 		c.Flags.I = 1
+		c.irqInhibit = 1
 		c.cycles += 7
 		f := *goFun
 		f()
@@ -42,6 +51,7 @@ func (c *CPU) executeInterrupt(goFun *func(), funAddress uint16) {
 
 	if funAddress != 0 {
 		c.Flags.I = 1
+		c.irqInhibit = 1
 		c.cycles += 7
 		c.PC = funAddress
 	}
@@ -0,0 +1,46 @@
+package cpu
+
+// Reset executes a soft reset of the CPU, as triggered by the reset button.
+// The registers A, X and Y keep their values, the interrupt disable flag is set,
+// the stack pointer is decremented by 3 and the program counter is loaded from
+// the reset vector. Pending interrupts and DMA stalls are discarded.
+func (c *CPU) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.SP -= 3
+	c.Flags.I = 1
+	c.resetState()
+}
+
+// PowerCycle resets the CPU to its power up state. The interrupt vectors and
+// the program counter are read again from the memory, which has to be reset
+// before calling this function.
+func (c *CPU) PowerCycle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.A = 0
+	c.X = 0
+	c.Y = 0
+	c.SP = InitialStack
+	c.setFlags(initialFlags)
+	c.resetState()
+}
+
+// resetState resets the internal state and executes the reset sequence that
+// reads the interrupt vectors.
+func (c *CPU) resetState() {
+	c.irqInhibit = c.Flags.I
+	c.irqRunning = false
+	c.nmiRunning = false
+	c.triggerIrq = false
+	c.triggerNmi = false
+	c.stallCycles = 0
+
+	c.nmiAddress = c.bus.Memory.ReadWord(0xFFFA)
+	c.PC = c.bus.Memory.ReadWord(0xFFFC)
+	c.irqAddress = c.bus.Memory.ReadWord(0xFFFE)
+
+	c.cycles += initialCycles
+}
@@ -15,11 +15,14 @@ import (
 // At the end of the function the write lock is taken and a unlocker function returned.
 func (c *CPU) instructionHook(instruction *cpu.Instruction, params ...any) func() {
 	if !c.emulator {
-		// trigger interrupt checking here as the system is not looping through the instructions in go mode
+		// trigger interrupt checking and stalling here as the system is not looping
+		// through the instructions in go mode
+		c.SyncPPU()
+		c.ExecuteStallCycles()
 		c.CheckInterrupts()
 	}
 
-	startCycles := c.cycles
+	c.irqInhibit = c.Flags.I
 
 	if c.tracing == NoTracing {
 		addressing := c.addressModeFromCall(instruction, params...)
@@ -42,13 +45,39 @@ func (c *CPU) instructionHook(instruction *cpu.Instruction, params ...any) func(
 	}
 
 	// this executes the ppu steps before the instruction
-	cpuCycles := c.cycles - startCycles
-	ppuCycles := cpuCycles * 3
-	c.bus.PPU.Step(int(ppuCycles))
+	c.SyncPPU()
 
 	return c.writeLock()
 }
 
+// SyncPPU advances the PPU for all CPU cycles that have been executed since the
+// last synchronization. Every CPU cycle equals 3 PPU cycles for NTSC and 3.2 for PAL,
+// the PPU is stepped cycle by cycle to allow interrupts to record the CPU cycle
+// that they occurred in. The mapper is stepped once per CPU cycle to clock
+// cycle based IRQ counters.
+func (c *CPU) SyncPPU() {
+	for c.syncedCycles < c.cycles {
+		c.bus.Mapper.Step()
+		c.ppuRemainder += c.ppuCycles
+		c.bus.PPU.Step(c.ppuRemainder / c.ppuCPUCycles)
+		c.ppuRemainder %= c.ppuCPUCycles
+		c.syncedCycles++
+	}
+}
+
+// ExecuteStallCycles executes all pending stall cycles of a DMA transfer and
+// returns whether the CPU was stalled.
+func (c *CPU) ExecuteStallCycles() bool {
+	if c.stallCycles == 0 {
+		return false
+	}
+
+	c.cycles += uint64(c.stallCycles)
+	c.stallCycles = 0
+	c.SyncPPU()
+	return true
+}
+
 // AccountBranchingPageCrossCycle accounts for a branch page crossing extra CPU cycle.
 func (c *CPU) AccountBranchingPageCrossCycle(ins *cpu.Instruction) {
 	if _, ok := m6502.BranchingInstructions[ins.Name]; !ok {
@@ -23,6 +23,35 @@ const (
 	EmulatorTracing
 )
 
+// TraceFormat defines the format of the trace output.
+type TraceFormat int
+
+// trace formats, either the nestest.log format without PPU state or the
+// extended format that adds the PPU scanline and cycle and the mapper banks.
+const (
+	NestestTraceFormat TraceFormat = iota
+	ExtendedTraceFormat
+)
+
+// TraceFilter limits the traced instructions, all conditions need to match.
+type TraceFilter struct {
+	PCStart uint16 // first traced program counter address
+	PCEnd   uint16 // last traced program counter address, 0 disables the address filter
+
+	Banks []int // PRG banks that the program counter has to be mapped to, empty for all banks
+
+	FrameStart uint64 // first traced frame
+	FrameEnd   uint64 // last traced frame, 0 traces all frames after the start frame
+}
+
+// TraceOptions contains the options of the trace output.
+type TraceOptions struct {
+	Filter     TraceFilter
+	Format     TraceFormat
+	StartAt    int // address that starts the tracing when it gets executed, -1 to trace from the start
+	BufferSize int // amount of trace lines to keep in a ring buffer instead of outputting them, 0 disables it
+}
+
 // TraceStep contains all info needed to print a trace step.
 type TraceStep struct {
 	PC             uint16
@@ -35,8 +64,13 @@ type TraceStep struct {
 	Instruction    string
 }
 
-// print outputs current trace step in Nintendulator / nestest.log compatible format.
+// print outputs current trace step in Nintendulator / nestest.log compatible format
+// if it matches the trace filter.
 func (t TraceStep) print(cpu *CPU) {
+	if !cpu.traceFilterMatches(t.PC) {
+		return
+	}
+
 	var opcodes [3]string
 	for i := 0; i < 3; i++ {
 		s := "  "
@@ -52,16 +86,127 @@ func (t TraceStep) print(cpu *CPU) {
 		unofficial = "*"
 	}
 
-	s := fmt.Sprintf("%04X  %s %s %s %s%-31s A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d\n",
+	s := fmt.Sprintf("%04X  %s %s %s %s%-31s A:%02X X:%02X Y:%02X P:%02X SP:%02X",
 		t.PC, opcodes[0], opcodes[1], opcodes[2], unofficial, t.Instruction,
-		cpu.A, cpu.X, cpu.Y, cpu.GetFlags(), cpu.SP, cpu.cycles)
-	if cpu.tracingTarget != nil {
-		_, _ = fmt.Fprint(cpu.tracingTarget, s)
+		cpu.A, cpu.X, cpu.Y, cpu.GetFlags(), cpu.SP)
+
+	if cpu.traceOptions.Format == ExtendedTraceFormat {
+		state := cpu.bus.Mapper.State()
+		s = fmt.Sprintf("%s PPU:%3d,%3d CYC:%d PRG:%s CHR:%s\n", s,
+			cpu.bus.PPU.ScanLine(), cpu.bus.PPU.Cycle(), cpu.cycles,
+			bankList(state.PrgWindows), bankList(state.ChrWindows))
+	} else {
+		s = fmt.Sprintf("%s CYC:%d\n", s, cpu.cycles)
+	}
+
+	cpu.writeTrace(s)
+}
+
+// SetTraceOptions sets the options of the trace output.
+func (c *CPU) SetTraceOptions(options TraceOptions) {
+	c.traceOptions = options
+	c.traceWaiting = options.StartAt >= 0
+	c.traceBuffer = make([]string, 0, options.BufferSize)
+	c.traceBufferIndex = 0
+}
+
+// DumpTrace writes all trace lines of the ring buffer to the trace output
+// and clears the buffer.
+func (c *CPU) DumpTrace() {
+	lines := make([]string, 0, len(c.traceBuffer))
+	lines = append(lines, c.traceBuffer[c.traceBufferIndex:]...)
+	lines = append(lines, c.traceBuffer[:c.traceBufferIndex]...)
+	c.traceBuffer = c.traceBuffer[:0]
+	c.traceBufferIndex = 0
+
+	for _, line := range lines {
+		c.outputTrace(line)
+	}
+}
+
+// writeTrace outputs the trace line or adds it to the ring buffer if enabled.
+func (c *CPU) writeTrace(s string) {
+	size := c.traceOptions.BufferSize
+	switch {
+	case size == 0:
+		c.outputTrace(s)
+
+	case len(c.traceBuffer) < size:
+		c.traceBuffer = append(c.traceBuffer, s)
+
+	default:
+		c.traceBuffer[c.traceBufferIndex] = s
+		c.traceBufferIndex = (c.traceBufferIndex + 1) % size
+	}
+}
+
+func (c *CPU) outputTrace(s string) {
+	if c.tracingTarget != nil {
+		_, _ = fmt.Fprint(c.tracingTarget, s)
 	} else {
 		fmt.Print(s)
 	}
 }
 
+// traceFilterMatches returns whether the instruction at the given program
+// counter address matches the trace start address and filter.
+func (c *CPU) traceFilterMatches(pc uint16) bool {
+	if c.traceWaiting {
+		if pc != uint16(c.traceOptions.StartAt) {
+			return false
+		}
+		c.traceWaiting = false
+	}
+
+	filter := &c.traceOptions.Filter
+	if filter.PCEnd != 0 && (pc < filter.PCStart || pc > filter.PCEnd) {
+		return false
+	}
+
+	if filter.FrameStart > 0 || filter.FrameEnd > 0 {
+		frame := c.bus.PPU.Frame()
+		if frame < filter.FrameStart || (filter.FrameEnd > 0 && frame > filter.FrameEnd) {
+			return false
+		}
+	}
+
+	if len(filter.Banks) > 0 {
+		bank, ok := c.prgBank(pc)
+		if !ok || !containsInt(filter.Banks, bank) {
+			return false
+		}
+	}
+	return true
+}
+
+// prgBank returns the PRG bank that is mapped to the given address.
+func (c *CPU) prgBank(address uint16) (int, bool) {
+	windows := c.bus.Mapper.State().PrgWindows
+	if address < nes.CodeBaseAddress || len(windows) == 0 {
+		return 0, false
+	}
+
+	window := int(address-nes.CodeBaseAddress) * len(windows) / (0x10000 - nes.CodeBaseAddress)
+	return windows[window], true
+}
+
+func bankList(banks []int) string {
+	values := make([]string, 0, len(banks))
+	for _, bank := range banks {
+		values = append(values, fmt.Sprintf("%02X", bank))
+	}
+	return strings.Join(values, ",")
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // Trace logs the trace information of the passed instruction and its parameters.
 // Params can be of length 0 to 2.
 func (c *CPU) trace(instruction *cpu.Instruction, params ...any) error {
@@ -149,7 +294,7 @@ func shouldOutputMemoryContent(address uint16) bool {
 	switch {
 	case address < 0x0800:
 		return true
-	case address >= 0x4000 && address <= 0x4020:
+	case address >= 0x4000 && address < 0x4020:
 		return true
 	case address >= nes.CodeBaseAddress:
 		return true
@@ -0,0 +1,329 @@
+//go:build !nesgo
+
+// Package disasm implements a NES ROM disassembler that outputs ca65 compatible
+// assembly which reassembles to a byte-identical ROM.
+package disasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/retroenv/nesgo/pkg/ca65"
+	"github.com/retroenv/nesgo/pkg/ines"
+	"github.com/retroenv/nesgo/pkg/nes"
+	. "github.com/retroenv/retrogolib/addressing"
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	cpulib "github.com/retroenv/retrogolib/cpu"
+)
+
+const (
+	romStart      = 0x8000
+	vectorsStart  = 0xFFFA
+	nmiVector     = 0xFFFA
+	resetVector   = 0xFFFC
+	irqVector     = 0xFFFE
+	supportMapper = 0
+)
+
+var (
+	// ErrUnsupportedMapper is returned for ROMs using a mapper that can not be reassembled.
+	ErrUnsupportedMapper = errors.New("unsupported mapper")
+	// ErrUnsupportedROM is returned for ROMs that contain data that can not be reassembled.
+	ErrUnsupportedROM = errors.New("unsupported ROM")
+)
+
+type offsetType int
+
+const (
+	dataOffset        offsetType = iota // not reached by the code flow
+	codeOffset                          // first byte of an instruction
+	codeOperandOffset                   // operand byte of an instruction or jump table entry
+	jumpTableOffset                     // first byte of a jump table entry
+)
+
+// offset contains the disassembly information of a PRG byte.
+type offset struct {
+	typ    offsetType
+	label  string
+	opcode cpulib.Opcode
+	data   []byte // all bytes of the instruction or jump table entry
+	params []any  // parameters of the instruction as returned by nes.ReadOpParams
+}
+
+// Options contains the disassembler options.
+type Options struct {
+	HexComments bool // add the address and bytes of every instruction as comment
+}
+
+// Disasm implements a disassembler for NES ROMs.
+type Disasm struct {
+	opts   Options
+	sys    *nes.System
+	header []byte
+	cart   *cartridge.Cartridge
+
+	codeBase uint16 // start address of the PRG in the CPU address space
+	offsets  []offset
+
+	targets     []uint16              // addresses of code to process
+	returns     []subroutineCall      // subroutine calls to process the code after
+	jumpEngines map[uint16]bool       // subroutines that were checked for being a jump engine
+	vectors     [3]uint16             // nmi, reset and irq handler addresses
+	registers   map[uint16][]constant // hardware register names by address
+	constants   map[string]uint16     // hardware register constants that are used
+}
+
+// subroutineCall contains the target of a jsr instruction and the address of
+// the following instruction.
+type subroutineCall struct {
+	subroutine uint16
+	next       uint16
+}
+
+// New returns a new disassembler for the given iNES ROM file data.
+func New(data []byte, opts Options) (*Disasm, error) {
+	header, err := ines.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing header: %w", err)
+	}
+	if header.Mapper != supportMapper {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedMapper, header.Mapper)
+	}
+	if header.Trainer {
+		return nil, fmt.Errorf("%w: trainer is not supported", ErrUnsupportedROM)
+	}
+	if expected := ines.HeaderSize + header.PRGSize + header.CHRSize; len(data) != expected {
+		return nil, fmt.Errorf("%w: file size %d does not match the expected size %d",
+			ErrUnsupportedROM, len(data), expected)
+	}
+	if header.PRGSize != 0x4000 && header.PRGSize != 0x8000 {
+		return nil, fmt.Errorf("%w: PRG size %d", ErrUnsupportedROM, header.PRGSize)
+	}
+
+	cart, err := cartridge.LoadFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("loading cartridge: %w", err)
+	}
+
+	sys := nes.NewSystem(nes.NewOptions(nes.WithEmulator(), nes.WithCartridge(cart), nes.WithDisabledGUI()))
+	sys.LinkAliases()
+
+	return &Disasm{
+		opts:        opts,
+		sys:         sys,
+		header:      data[:ines.HeaderSize],
+		cart:        cart,
+		codeBase:    uint16(0x10000 - len(cart.PRG)),
+		offsets:     make([]offset, len(cart.PRG)),
+		jumpEngines: map[uint16]bool{},
+		registers:   registerNames(),
+		constants:   map[string]uint16{},
+	}, nil
+}
+
+// Process disassembles the ROM by following the code flow from the vectors
+// and returns the ca65 compatible assembly.
+func (dis *Disasm) Process() []byte {
+	for i, address := range []uint16{nmiVector, resetVector, irqVector} {
+		dis.vectors[i] = dis.readWord(address)
+	}
+	dis.addVectorLabel(dis.vectors[1], "reset")
+	dis.addVectorLabel(dis.vectors[0], "nmi")
+	dis.addVectorLabel(dis.vectors[2], "irq")
+
+	dis.followCodeFlow()
+	dis.addDataLabels()
+
+	buf := &bytes.Buffer{}
+	dis.output(buf)
+	return buf.Bytes()
+}
+
+// followCodeFlow processes all code targets. Return addresses of subroutine
+// calls are processed after all other targets, so that the bytes following
+// a call of a jump engine can be detected as jump table.
+func (dis *Disasm) followCodeFlow() {
+	for {
+		for len(dis.targets) > 0 {
+			address := dis.targets[0]
+			dis.targets = dis.targets[1:]
+			dis.processCode(address)
+		}
+
+		if len(dis.returns) == 0 {
+			return
+		}
+		call := dis.returns[0]
+		dis.returns = dis.returns[1:]
+		dis.processReturn(call)
+	}
+}
+
+// processCode disassembles all instructions starting at the address until
+// an instruction is reached that does not continue with the next one.
+func (dis *Disasm) processCode(address uint16) {
+	for {
+		index, ok := dis.prgIndex(address)
+		if !ok || dis.offsets[index].typ != dataOffset || address >= vectorsStart {
+			return
+		}
+
+		b := dis.sys.Bus.Memory.Read(address)
+		opcode := m6502.Opcodes[b]
+		if opcode.Instruction == nil {
+			return // not an opcode, stop at the invalid instruction
+		}
+
+		dis.sys.PC = address
+		params, operands, _ := nes.ReadOpParams(dis.sys.Bus.Memory, opcode.Addressing, false)
+		size := 1 + len(operands)
+		if int(address)+size > vectorsStart || !dis.isUnprocessed(index, size) {
+			return
+		}
+
+		dis.offsets[index] = offset{
+			typ:    codeOffset,
+			label:  dis.offsets[index].label,
+			opcode: opcode,
+			data:   append([]byte{b}, operands...),
+			params: params,
+		}
+		for i := 1; i < size; i++ {
+			dis.offsets[index+i].typ = codeOperandOffset
+		}
+
+		next := address + uint16(size)
+		if !dis.processBranching(next, opcode, params) {
+			return
+		}
+		address = next
+	}
+}
+
+// processBranching adds the targets of branching instructions and returns
+// whether the instruction continues with the next instruction.
+func (dis *Disasm) processBranching(next uint16, opcode cpulib.Opcode, params []any) bool {
+	name := opcode.Instruction.Name
+	if _, ok := m6502.BranchingInstructions[name]; ok && opcode.Addressing != IndirectAddressing {
+		target := uint16(params[0].(Absolute))
+		dis.addCodeTarget(target)
+
+		if name == m6502.Jsr.Name {
+			dis.returns = append(dis.returns, subroutineCall{subroutine: target, next: next})
+			return false
+		}
+	}
+
+	// brk is not followed as it is usually executed by jumping into zero filled data
+	_, stops := m6502.NotExecutingFollowingOpcodeInstructions[name]
+	return !stops && name != m6502.Brk.Name
+}
+
+// processReturn processes the code after a subroutine call, unless the called
+// subroutine is a jump engine that uses the bytes after the call as jump table.
+func (dis *Disasm) processReturn(call subroutineCall) {
+	if dis.isJumpEngine(call.subroutine) {
+		dis.processJumpTable(call.next)
+		return
+	}
+	dis.targets = append(dis.targets, call.next)
+}
+
+// addCodeTarget adds a label for the address and queues it for processing.
+// Addresses of the mirrored 16K PRG are processed at the PRG base address
+// but are not labeled.
+func (dis *Disasm) addCodeTarget(address uint16) {
+	index, ok := dis.prgIndex(address)
+	if !ok {
+		return
+	}
+	if address >= dis.codeBase && !dis.setLabel(address, fmt.Sprintf("_label_%04x", address)) {
+		return
+	}
+	dis.targets = append(dis.targets, dis.codeBase+uint16(index))
+}
+
+func (dis *Disasm) addVectorLabel(address uint16, name string) {
+	dis.setLabel(address, name)
+	dis.addCodeTarget(address)
+}
+
+// setLabel sets the label of an address if it does not have one yet. It returns
+// false if the address can not be labeled as it is outside of the PRG or not
+// the start of an instruction or jump table entry.
+func (dis *Disasm) setLabel(address uint16, label string) bool {
+	index, ok := dis.prgIndex(address)
+	if !ok || address < dis.codeBase || address >= vectorsStart ||
+		dis.offsets[index].typ == codeOperandOffset {
+		return false
+	}
+
+	if dis.offsets[index].label == "" {
+		dis.offsets[index].label = label
+	}
+	return true
+}
+
+// addDataLabels adds labels for all data addresses of the PRG that are
+// referenced by instructions.
+func (dis *Disasm) addDataLabels() {
+	for _, offset := range dis.offsets {
+		if offset.typ != codeOffset {
+			continue
+		}
+		switch offset.opcode.Addressing {
+		case AbsoluteAddressing, AbsoluteXAddressing, AbsoluteYAddressing:
+		default:
+			continue
+		}
+		if _, ok := m6502.BranchingInstructions[offset.opcode.Instruction.Name]; ok {
+			continue
+		}
+
+		address := uint16(offset.params[0].(Absolute))
+		index, ok := dis.prgIndex(address)
+		if ok && dis.offsets[index].typ == dataOffset {
+			dis.setLabel(address, fmt.Sprintf("_data_%04x", address))
+		}
+	}
+}
+
+// isUnprocessed returns whether all bytes of an instruction of the given size
+// at the index have not been processed yet.
+func (dis *Disasm) isUnprocessed(index, size int) bool {
+	if index+size > len(dis.offsets) {
+		return false
+	}
+	for i := index; i < index+size; i++ {
+		if dis.offsets[i].typ != dataOffset {
+			return false
+		}
+	}
+	return true
+}
+
+// prgIndex returns the PRG index of an address in the CPU address space,
+// a 16K PRG is mirrored at $8000 and $C000.
+func (dis *Disasm) prgIndex(address uint16) (int, bool) {
+	if address < romStart {
+		return 0, false
+	}
+	return int(address-romStart) % len(dis.offsets), true
+}
+
+func (dis *Disasm) readWord(address uint16) uint16 {
+	low := uint16(dis.sys.Bus.Memory.Read(address))
+	high := uint16(dis.sys.Bus.Memory.Read(address + 1))
+	return high<<8 | low
+}
+
+// AssemblerConfig returns the ca65 configuration to reassemble the output.
+func (dis *Disasm) AssemblerConfig() ca65.Config {
+	return ca65.Config{
+		PrgBase: int(dis.codeBase),
+		PRGSize: len(dis.cart.PRG),
+		CHRSize: len(dis.cart.CHR),
+	}
+}
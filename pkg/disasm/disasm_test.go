@@ -0,0 +1,124 @@
+//go:build !nesgo
+
+package disasm
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/ca65"
+	"github.com/retroenv/nesgo/pkg/ines"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+var testProgram = []byte{
+	0x78,       // $8000 reset: sei
+	0xA9, 0x00, //       lda #$00
+	0x8D, 0x00, 0x20, // sta PPU_CTRL
+	0xAD, 0x10, 0x00, // lda a:$0010
+	0xBD, 0x00, 0x81, // lda _data_8100,x
+	0x20, 0x50, 0x80, // jsr jump engine
+	0x20, 0x80, // .addr nmi
+	0x24, 0x80, // .addr _label_8024
+	0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, // $8013: unused
+	0x02, 0x02, 0x02, 0x02, 0x02, 0x02,
+	0xCA,       // $8020: dex
+	0xD0, 0xFD, // bne $8020
+	0x60,       // rts
+	0x04, 0x10, // $8024: unofficial nop $10
+	0x60, // rts
+}
+
+var testJumpEngine = []byte{
+	0x0A,       // $8050: asl a
+	0xA8,       // tay
+	0x68,       // pla
+	0x85, 0x04, // sta $04
+	0x68,       // pla
+	0x85, 0x05, // sta $05
+	0xC8,       // iny
+	0xB1, 0x04, // lda ($04),y
+	0x85, 0x06, // sta $06
+	0xC8,       // iny
+	0xB1, 0x04, // lda ($04),y
+	0x85, 0x07, // sta $07
+	0x6C, 0x06, 0x00, // jmp ($0006)
+}
+
+func testROM() []byte {
+	prg := make([]byte, 0x8000)
+	copy(prg, testProgram)
+	copy(prg[0x50:], testJumpEngine)
+	copy(prg[0x100:], []byte{1, 2, 3, 4})
+	copy(prg[0x7FFA:], []byte{0x20, 0x80, 0x00, 0x80, 0x00, 0x80})
+
+	chr := make([]byte, 0x2000)
+	chr[0] = 0xFF
+
+	data := []byte{'N', 'E', 'S', 0x1A, 2, 1, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	data = append(data, prg...)
+	return append(data, chr...)
+}
+
+func TestDisasm(t *testing.T) {
+	dis, err := New(testROM(), Options{})
+	assert.NoError(t, err)
+	output := string(dis.Process())
+
+	for _, line := range []string{
+		"PPU_CTRL = $2000\n",
+		".segment \"HEADER\"\n.byte $4E, $45, $53, $1A, $02, $01, $01,",
+		"reset:\n  sei\n  lda #$00\n  sta PPU_CTRL\n  lda a:$0010\n  lda _data_8100,x\n  jsr _label_8050\n",
+		"_jump_table_800f:\n.addr nmi\n.addr _label_8024\n.byte $02, $02,",
+		"nmi:\n  dex\n  bne nmi\n  rts\n",
+		"_label_8024:\n  .byte $04, $10",
+		"  jmp ($0006)\n",
+		"_data_8100:\n.byte $01, $02, $03, $04, $00,",
+		".segment \"VECTORS\"\n.addr nmi, reset, reset\n",
+		".segment \"TILES\"\n.byte $FF, $00,",
+	} {
+		assert.True(t, strings.Contains(output, line), "missing output: "+line)
+	}
+}
+
+func TestDisasmReassemble(t *testing.T) {
+	if _, err := exec.LookPath("ca65"); err != nil {
+		t.Skip("ca65 is not installed")
+	}
+
+	rom := testROM()
+	dis, err := New(rom, Options{HexComments: true})
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	asmFile := filepath.Join(dir, "test.asm")
+	outputFile := filepath.Join(dir, "test.nes")
+	assert.NoError(t, os.WriteFile(asmFile, dis.Process(), 0644))
+	assert.NoError(t, ca65.AssembleUsingExternalApp(asmFile, filepath.Join(dir, "test.o"), outputFile, dis.AssemblerConfig()))
+
+	data, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(rom, data))
+}
+
+func TestDisasmUnsupported(t *testing.T) {
+	rom := testROM()
+	rom[6] |= 0x10
+	_, err := New(rom, Options{})
+	assert.True(t, errors.Is(err, ErrUnsupportedMapper))
+
+	rom = testROM()
+	_, err = New(rom[:len(rom)-1], Options{})
+	assert.True(t, errors.Is(err, ErrUnsupportedROM))
+
+	// NES 2.0 header with a CHR-ROM size of 2^63 bytes
+	rom = testROM()
+	rom[5], rom[7], rom[9] = 0xFC, 0x08, 0xF0
+	_, err = New(rom, Options{})
+	assert.True(t, errors.Is(err, ines.ErrInvalidROMSize))
+}
@@ -0,0 +1,95 @@
+//go:build !nesgo
+
+package disasm
+
+import (
+	"fmt"
+
+	. "github.com/retroenv/retrogolib/addressing"
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+)
+
+// maxJumpEngineInstructions limits the amount of instructions of a subroutine
+// that are checked for the jump engine pattern.
+const maxJumpEngineInstructions = 32
+
+// isJumpEngine returns whether the subroutine is a jump engine. A jump engine
+// pulls its return address from the stack to read the address to jump to from
+// the jump table that follows the jsr instruction, and jumps to it using an
+// indirect jmp:
+//
+//	asl a
+//	tay
+//	pla
+//	sta $04
+//	pla
+//	sta $05
+//	...
+//	jmp ($06)
+func (dis *Disasm) isJumpEngine(address uint16) bool {
+	if result, ok := dis.jumpEngines[address]; ok {
+		return result
+	}
+
+	result := dis.checkJumpEngine(address)
+	dis.jumpEngines[address] = result
+	return result
+}
+
+func (dis *Disasm) checkJumpEngine(address uint16) bool {
+	index, ok := dis.prgIndex(address)
+	if !ok {
+		return false
+	}
+
+	pulls := 0
+	for i := 0; i < maxJumpEngineInstructions && index < len(dis.offsets); i++ {
+		offset := dis.offsets[index]
+		if offset.typ != codeOffset {
+			return false
+		}
+
+		name := offset.opcode.Instruction.Name
+		switch {
+		case name == m6502.Pla.Name:
+			pulls++
+		case name == m6502.Jmp.Name && offset.opcode.Addressing == IndirectAddressing:
+			return pulls >= 2
+		}
+		if _, ok := m6502.NotExecutingFollowingOpcodeInstructions[name]; ok {
+			return false
+		}
+
+		index += len(offset.data)
+	}
+	return false
+}
+
+// processJumpTable processes the jump table at the address and adds all table
+// entries as code targets. The table ends at the first entry that does not
+// point to code in the PRG or that is referenced by a label.
+func (dis *Disasm) processJumpTable(address uint16) {
+	dis.setLabel(address, fmt.Sprintf("_jump_table_%04x", address))
+
+	for entry := address; int(entry)+2 <= vectorsStart; entry += 2 {
+		index, ok := dis.prgIndex(entry)
+		if !ok || !dis.isUnprocessed(index, 2) {
+			return
+		}
+		if entry != address && dis.offsets[index].label != "" {
+			return
+		}
+
+		target := dis.readWord(entry)
+		targetIndex, ok := dis.prgIndex(target)
+		if !ok || dis.offsets[targetIndex].typ == codeOperandOffset ||
+			dis.offsets[targetIndex].typ == jumpTableOffset {
+			return
+		}
+
+		dis.offsets[index].typ = jumpTableOffset
+		dis.offsets[index].data = []byte{dis.sys.Bus.Memory.Read(entry), dis.sys.Bus.Memory.Read(entry + 1)}
+		dis.offsets[index+1].typ = codeOperandOffset
+		dis.addCodeTarget(target)
+	}
+}
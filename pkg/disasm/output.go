@@ -0,0 +1,269 @@
+//go:build !nesgo
+
+package disasm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/apu"
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/ppu"
+	. "github.com/retroenv/retrogolib/addressing"
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+)
+
+const (
+	bytesPerLine   = 16
+	commentPadding = 32
+)
+
+// constant is a hardware register name and the access mode that it applies to.
+type constant struct {
+	name string
+	mode AccessMode
+}
+
+// registerNames returns the names of all hardware registers by address.
+func registerNames() map[uint16][]constant {
+	registers := map[uint16][]constant{}
+	for _, names := range []map[uint16]AccessModeConstant{ppu.AddressToName, apu.AddressToName, controller.AddressToName} {
+		for address, name := range names {
+			registers[address] = append(registers[address], constant{name: name.Constant, mode: name.Mode})
+		}
+	}
+
+	for _, constants := range registers {
+		sort.Slice(constants, func(i, j int) bool {
+			return constants[i].name < constants[j].name
+		})
+	}
+	return registers
+}
+
+// output writes the ca65 assembly of the processed ROM.
+func (dis *Disasm) output(buf *bytes.Buffer) {
+	code := &bytes.Buffer{}
+	dis.outputCode(code)
+
+	// the constants are collected while writing the code
+	if len(dis.constants) > 0 {
+		names := make([]string, 0, len(dis.constants))
+		for name := range dis.constants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(buf, "%s = $%04X\n", name, dis.constants[name])
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(".segment \"HEADER\"\n")
+	outputBytes(buf, dis.header)
+
+	buf.WriteString("\n.segment \"CODE\"\n")
+	buf.Write(code.Bytes())
+
+	buf.WriteString("\n.segment \"VECTORS\"\n")
+	fmt.Fprintf(buf, ".addr %s, %s, %s\n", dis.vectorName(dis.vectors[0]),
+		dis.vectorName(dis.vectors[1]), dis.vectorName(dis.vectors[2]))
+
+	if len(dis.cart.CHR) > 0 {
+		buf.WriteString("\n.segment \"TILES\"\n")
+		outputBytes(buf, dis.cart.CHR)
+	}
+}
+
+// outputCode writes all instructions and data of the PRG up to the vectors.
+func (dis *Disasm) outputCode(buf *bytes.Buffer) {
+	end := len(dis.offsets) - (0x10000 - vectorsStart)
+	var data []byte
+
+	for index := 0; index < end; index++ {
+		offset := dis.offsets[index]
+		if offset.label != "" || offset.typ != dataOffset {
+			outputBytes(buf, data)
+			data = data[:0]
+		}
+		if offset.label != "" {
+			fmt.Fprintf(buf, "%s:\n", offset.label)
+		}
+
+		address := dis.codeBase + uint16(index)
+		switch offset.typ {
+		case codeOffset:
+			dis.outputInstruction(buf, address, offset)
+		case jumpTableOffset:
+			target := uint16(offset.data[1])<<8 | uint16(offset.data[0])
+			dis.outputLine(buf, address, offset.data, ".addr %s", dis.vectorName(target))
+		case dataOffset:
+			data = append(data, dis.sys.Bus.Memory.Read(address))
+		}
+	}
+	outputBytes(buf, data)
+}
+
+// outputInstruction writes an instruction, unofficial instructions are written
+// as bytes as ca65 does not support them by default.
+func (dis *Disasm) outputInstruction(buf *bytes.Buffer, address uint16, offset offset) {
+	ins := offset.opcode.Instruction
+	text := ins.Name
+	if operand := dis.operand(offset); operand != "" {
+		text = fmt.Sprintf("%s %s", ins.Name, operand)
+	}
+
+	info, ok := ins.Addressing[offset.opcode.Addressing]
+	if ins.Unofficial || !ok || info.Opcode != offset.data[0] {
+		line := fmt.Sprintf("  .byte %s", hexBytes(offset.data))
+		fmt.Fprintf(buf, "%-*s ; %s\n", commentPadding, line, text)
+		return
+	}
+
+	dis.outputLine(buf, address, offset.data, "  %s", text)
+}
+
+// outputLine writes a formatted line and adds the address and bytes as comment
+// if enabled.
+func (dis *Disasm) outputLine(buf *bytes.Buffer, address uint16, data []byte, format string, a ...any) {
+	line := fmt.Sprintf(format, a...)
+	if !dis.opts.HexComments {
+		fmt.Fprintln(buf, line)
+		return
+	}
+
+	hex := make([]string, 0, len(data))
+	for _, b := range data {
+		hex = append(hex, fmt.Sprintf("%02X", b))
+	}
+	fmt.Fprintf(buf, "%-*s ; $%04X %s\n", commentPadding, line, address, strings.Join(hex, " "))
+}
+
+// operand returns the ca65 operand of an instruction.
+func (dis *Disasm) operand(offset offset) string {
+	mode := offset.opcode.Addressing
+	switch mode {
+	case ImpliedAddressing:
+		return ""
+	case AccumulatorAddressing:
+		return "a"
+	case ImmediateAddressing:
+		return fmt.Sprintf("#$%02X", offset.params[0].(int))
+	case ZeroPageAddressing:
+		return fmt.Sprintf("$%02X", offset.data[1])
+	case ZeroPageXAddressing:
+		return fmt.Sprintf("$%02X,x", offset.data[1])
+	case ZeroPageYAddressing:
+		return fmt.Sprintf("$%02X,y", offset.data[1])
+	case IndirectXAddressing:
+		return fmt.Sprintf("($%02X,x)", offset.data[1])
+	case IndirectYAddressing:
+		return fmt.Sprintf("($%02X),y", offset.data[1])
+	case RelativeAddressing:
+		return dis.vectorName(uint16(offset.params[0].(Absolute)))
+	}
+
+	address := uint16(offset.data[2])<<8 | uint16(offset.data[1])
+	name := dis.addressName(address, offset)
+	switch mode {
+	case AbsoluteXAddressing:
+		return name + ",x"
+	case AbsoluteYAddressing:
+		return name + ",y"
+	case IndirectAddressing:
+		return "(" + name + ")"
+	default:
+		return name
+	}
+}
+
+// zeroPageModes maps absolute addressing modes to their zero page equivalent.
+var zeroPageModes = map[Mode]Mode{
+	AbsoluteAddressing:  ZeroPageAddressing,
+	AbsoluteXAddressing: ZeroPageXAddressing,
+	AbsoluteYAddressing: ZeroPageYAddressing,
+}
+
+// addressName returns the label or hardware register name of an absolute
+// address. Zero page addresses get prefixed by a: if the instruction supports
+// zero page addressing, to force ca65 to keep the absolute addressing.
+func (dis *Disasm) addressName(address uint16, offset offset) string {
+	if label := dis.label(address); label != "" {
+		return label
+	}
+
+	if constants, ok := dis.registers[address]; ok {
+		name := constants[0].name
+		access := accessMode(offset)
+		for _, c := range constants {
+			if c.mode&access == access {
+				name = c.name
+				break
+			}
+		}
+		dis.constants[name] = address
+		return name
+	}
+
+	zeroPageMode, ok := zeroPageModes[offset.opcode.Addressing]
+	if address < 0x100 && ok && offset.opcode.Instruction.HasAddressing(zeroPageMode) {
+		return fmt.Sprintf("a:$%04X", address)
+	}
+	return fmt.Sprintf("$%04X", address)
+}
+
+// vectorName returns the label of an address or the address itself.
+func (dis *Disasm) vectorName(address uint16) string {
+	if label := dis.label(address); label != "" {
+		return label
+	}
+	return fmt.Sprintf("$%04X", address)
+}
+
+// label returns the label of an address of the PRG, addresses of the mirrored
+// 16K PRG are not labeled.
+func (dis *Disasm) label(address uint16) string {
+	if address < dis.codeBase {
+		return ""
+	}
+	index, ok := dis.prgIndex(address)
+	if !ok {
+		return ""
+	}
+	return dis.offsets[index].label
+}
+
+// accessMode returns the memory access mode of an instruction.
+func accessMode(offset offset) AccessMode {
+	switch {
+	case offset.opcode.ReadWritesMemory(m6502.MemoryReadWriteInstructions):
+		return ReadWriteAccess
+	case offset.opcode.WritesMemory(m6502.MemoryWriteInstructions):
+		return WriteAccess
+	default:
+		return ReadAccess
+	}
+}
+
+// outputBytes writes the data as .byte lines.
+func outputBytes(buf *bytes.Buffer, data []byte) {
+	for len(data) > 0 {
+		n := bytesPerLine
+		if len(data) < n {
+			n = len(data)
+		}
+		fmt.Fprintf(buf, ".byte %s\n", hexBytes(data[:n]))
+		data = data[n:]
+	}
+}
+
+func hexBytes(data []byte) string {
+	values := make([]string, 0, len(data))
+	for _, b := range data {
+		values = append(values, fmt.Sprintf("$%02X", b))
+	}
+	return strings.Join(values, ", ")
+}
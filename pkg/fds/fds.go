@@ -0,0 +1,168 @@
+// Package fds implements loading of Famicom Disk System disk images in the .fds format.
+package fds
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// MapperNumber is the iNES mapper number that is reserved for the Famicom Disk System.
+// It is used for the cartridge that wraps the BIOS.
+const MapperNumber = 20
+
+const (
+	// BIOSSize is the size of the FDS BIOS in bytes.
+	BIOSSize = 0x2000
+	// HeaderSize is the size of the optional fwNES header of .fds files in bytes.
+	HeaderSize = 16
+	// SideSize is the size of a single disk side in the .fds format in bytes.
+	SideSize = 65500
+)
+
+// Sizes of the raw disk format as read by the drive, which contains gaps between the
+// blocks that are not stored in .fds files.
+const (
+	leadingGapSize = 28300 / 8 // gap before the first block
+	blockGapSize   = 976 / 8   // gap after every block
+	blockStartMark = 0x80
+)
+
+// block types of the file system on a disk side.
+const (
+	blockDiskInfo   = 1
+	blockFileAmount = 2
+	blockFileHeader = 3
+	blockFileData   = 4
+)
+
+var (
+	// ErrInvalidBIOS is returned when the BIOS does not have the expected size.
+	ErrInvalidBIOS = errors.New("invalid BIOS size")
+	// ErrInvalidImage is returned when the data is not a valid disk image.
+	ErrInvalidImage = errors.New("invalid disk image")
+	// ErrInvalidSide is returned when a disk side is inserted that does not exist.
+	ErrInvalidSide = errors.New("invalid disk side")
+)
+
+var (
+	headerMagic   = []byte{'F', 'D', 'S', 0x1a}
+	diskInfoMagic = []byte("*NINTENDO-HVC*")
+)
+
+// Drive is implemented by the FDS mapper and allows changing the disk side
+// that is inserted in the drive.
+type Drive interface {
+	// Sides returns the amount of disk sides.
+	Sides() int
+	// InsertedSide returns the index of the inserted side, or -1 if no disk is inserted.
+	InsertedSide() int
+	// InsertSide inserts the side with the given index. If a disk is inserted, it gets
+	// ejected first and the new side is inserted after a delay that allows the BIOS to
+	// detect the disk change.
+	InsertSide(side int) error
+	// Eject ejects the disk from the drive.
+	Eject()
+}
+
+// Disk contains all sides of a disk image.
+type Disk struct {
+	// Sides contains the data of every disk side in the raw format as read by the drive,
+	// including the gaps, block start marks and checksums.
+	Sides [][]byte
+}
+
+// IsImage returns whether the data is a disk image, either with an fwNES header
+// or as a headerless image that starts with a disk info block.
+func IsImage(data []byte) bool {
+	if bytes.HasPrefix(data, headerMagic) {
+		return true
+	}
+	return len(data) > 0 && len(data)%SideSize == 0 && isDiskInfoBlock(data)
+}
+
+// Load parses the given .fds file data and returns the disk.
+func Load(data []byte) (*Disk, error) {
+	if bytes.HasPrefix(data, headerMagic) {
+		if len(data) < HeaderSize {
+			return nil, fmt.Errorf("%w: header too short", ErrInvalidImage)
+		}
+		data = data[HeaderSize:]
+	}
+
+	if len(data) == 0 || len(data)%SideSize != 0 {
+		return nil, fmt.Errorf("%w: size %d is not a multiple of the side size", ErrInvalidImage, len(data))
+	}
+
+	disk := &Disk{}
+	for offset := 0; offset < len(data); offset += SideSize {
+		side := data[offset : offset+SideSize]
+		if !isDiskInfoBlock(side) {
+			return nil, fmt.Errorf("%w: side %d does not start with a disk info block", ErrInvalidImage, len(disk.Sides))
+		}
+		disk.Sides = append(disk.Sides, rawSide(side))
+	}
+	return disk, nil
+}
+
+// NewCartridge returns a cartridge that contains the BIOS as PRG ROM and is
+// mapped by the FDS mapper. The disk needs to be passed separately to the system.
+func NewCartridge(bios []byte) (*cartridge.Cartridge, error) {
+	if len(bios) != BIOSSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidBIOS, len(bios))
+	}
+
+	cart := cartridge.New()
+	cart.PRG = append([]byte(nil), bios...)
+	cart.CHR = nil
+	cart.Mapper = MapperNumber
+	cart.Mirror = cartridge.MirrorHorizontal
+	return cart, nil
+}
+
+func isDiskInfoBlock(data []byte) bool {
+	return len(data) > len(diskInfoMagic) && data[0] == blockDiskInfo &&
+		bytes.Equal(data[1:1+len(diskInfoMagic)], diskInfoMagic)
+}
+
+// rawSide converts a side of the .fds format to the raw format as read by the drive.
+// Every block is prefixed by a gap and a start mark and followed by a checksum.
+// The checksum is not calculated as the drive emulation does not verify it.
+func rawSide(side []byte) []byte {
+	raw := make([]byte, 0, leadingGapSize+SideSize)
+	raw = append(raw, make([]byte, leadingGapSize)...)
+	var fileSize int
+
+	for offset := 0; offset < len(side); {
+		length := 0
+		switch side[offset] {
+		case blockDiskInfo:
+			length = 56
+		case blockFileAmount:
+			length = 2
+		case blockFileHeader:
+			length = 16
+			if offset+14 < len(side) {
+				fileSize = int(side[offset+13]) | int(side[offset+14])<<8
+			}
+		case blockFileData:
+			length = 1 + fileSize
+		}
+		if length == 0 || offset+length > len(side) {
+			break // end of the file system
+		}
+
+		raw = append(raw, blockStartMark)
+		raw = append(raw, side[offset:offset+length]...)
+		raw = append(raw, 0x4D, 0x62) // dummy checksum
+		raw = append(raw, make([]byte, blockGapSize)...)
+		offset += length
+	}
+
+	if len(raw) < SideSize {
+		raw = append(raw, make([]byte, SideSize-len(raw))...)
+	}
+	return raw
+}
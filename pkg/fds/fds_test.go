@@ -0,0 +1,91 @@
+package fds
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testSide returns a disk side with a single file that contains 4 bytes.
+func testSide() []byte {
+	side := make([]byte, SideSize)
+	side[0] = blockDiskInfo
+	copy(side[1:], diskInfoMagic)
+
+	offset := 56
+	side[offset] = blockFileAmount
+	side[offset+1] = 1
+
+	offset += 2
+	side[offset] = blockFileHeader
+	side[offset+13] = 4 // file size
+
+	offset += 16
+	copy(side[offset:], []byte{blockFileData, 1, 2, 3, 4})
+	return side
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	data := append(testSide(), testSide()...)
+	assert.True(t, IsImage(data))
+
+	disk, err := Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(disk.Sides))
+
+	raw := disk.Sides[0]
+	assert.Equal(t, SideSize, len(raw))
+	assert.Equal(t, 0, raw[leadingGapSize-1])
+	assert.Equal(t, blockStartMark, raw[leadingGapSize])
+	assert.Equal(t, blockDiskInfo, raw[leadingGapSize+1])
+
+	// start of the file data block after the 3 blocks with start marks, checksums and gaps
+	offset := leadingGapSize + 3*(1+2+blockGapSize) + 56 + 2 + 16
+	assert.Equal(t, blockStartMark, raw[offset])
+	assert.Equal(t, []byte{blockFileData, 1, 2, 3, 4}, raw[offset+1:offset+6])
+	assert.Equal(t, []byte{0x4D, 0x62}, raw[offset+6:offset+8])
+}
+
+func TestLoadHeader(t *testing.T) {
+	t.Parallel()
+
+	header := []byte{'F', 'D', 'S', 0x1a, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	data := append(header, testSide()...)
+	assert.True(t, IsImage(data))
+
+	disk, err := Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(disk.Sides))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, SideSize)
+	assert.False(t, IsImage(data))
+
+	_, err := Load(data)
+	assert.True(t, errors.Is(err, ErrInvalidImage))
+
+	_, err = Load(testSide()[:1000])
+	assert.True(t, errors.Is(err, ErrInvalidImage))
+}
+
+func TestNewCartridge(t *testing.T) {
+	t.Parallel()
+
+	bios := make([]byte, BIOSSize)
+	bios[0] = 0x12
+
+	cart, err := NewCartridge(bios)
+	assert.NoError(t, err)
+	assert.Equal(t, MapperNumber, cart.Mapper)
+	assert.Equal(t, 0x12, cart.PRG[0])
+	assert.Equal(t, 0, len(cart.CHR))
+
+	_, err = NewCartridge(bios[:0x1000])
+	assert.True(t, errors.Is(err, ErrInvalidBIOS))
+}
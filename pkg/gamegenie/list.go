@@ -0,0 +1,134 @@
+package gamegenie
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrUnknownCode is returned when a code is changed that is not part of the list.
+var ErrUnknownCode = errors.New("unknown code")
+
+// Code defines a Game Genie code of a list.
+type Code struct {
+	Code    string
+	Patch   Patch
+	Enabled bool
+}
+
+// List contains Game Genie codes and applies the patches of the enabled codes
+// to values read from the cartridge. It is safe for concurrent use.
+type List struct {
+	mu    sync.Mutex
+	codes []Code
+
+	active atomic.Value // map[uint16][]Patch of all enabled codes
+}
+
+// NewList returns a new empty code list.
+func NewList() *List {
+	l := &List{}
+	l.active.Store(map[uint16][]Patch{})
+	return l
+}
+
+// Add decodes the code and adds it enabled to the list. If the list already
+// contains the code, it gets enabled.
+func (l *List) Add(code string) error {
+	code = strings.ToUpper(code)
+	patch, err := Decode(code)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if i := l.index(code); i >= 0 {
+		l.codes[i].Enabled = true
+	} else {
+		l.codes = append(l.codes, Code{
+			Code:    code,
+			Patch:   patch,
+			Enabled: true,
+		})
+	}
+	l.updateActive()
+	return nil
+}
+
+// SetEnabled enables or disables the code.
+func (l *List) SetEnabled(code string, enabled bool) error {
+	code = strings.ToUpper(code)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := l.index(code)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+	l.codes[i].Enabled = enabled
+	l.updateActive()
+	return nil
+}
+
+// Toggle enables a disabled or disables an enabled code and returns whether
+// the code is enabled now.
+func (l *List) Toggle(code string) (bool, error) {
+	code = strings.ToUpper(code)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := l.index(code)
+	if i < 0 {
+		return false, fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+	l.codes[i].Enabled = !l.codes[i].Enabled
+	l.updateActive()
+	return l.codes[i].Enabled, nil
+}
+
+// Codes returns a copy of all codes of the list.
+func (l *List) Codes() []Code {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Code(nil), l.codes...)
+}
+
+// Apply returns the value to read from the given cartridge address, the value
+// is the byte of the currently mapped PRG bank. Patches with a compare value are
+// only applied if the value matches, which allows patching bank switched code.
+func (l *List) Apply(address uint16, value byte) byte {
+	active := l.active.Load().(map[uint16][]Patch)
+	for _, patch := range active[address] {
+		if !patch.HasCompare || patch.Compare == value {
+			return patch.Data
+		}
+	}
+	return value
+}
+
+func (l *List) index(code string) int {
+	for i, c := range l.codes {
+		if c.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateActive replaces the map of active patches, the caller has to hold the lock.
+func (l *List) updateActive() {
+	active := map[uint16][]Patch{}
+	for _, c := range l.codes {
+		if c.Enabled {
+			active[c.Patch.Address] = append(active[c.Patch.Address], c.Patch)
+		}
+	}
+	l.active.Store(active)
+}
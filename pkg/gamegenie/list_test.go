@@ -0,0 +1,40 @@
+package gamegenie
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	l := NewList()
+	assert.Equal(t, 0x12, l.Apply(0xD1DD, 0x12))
+
+	assert.NoError(t, l.Add("gossip"))
+	assert.NoError(t, l.Add("ZEXPYGLA"))
+	assert.True(t, l.Add("TEST") != nil)
+
+	codes := l.Codes()
+	assert.Equal(t, 2, len(codes))
+	assert.Equal(t, "GOSSIP", codes[0].Code)
+	assert.True(t, codes[0].Enabled)
+
+	assert.Equal(t, 0x14, l.Apply(0xD1DD, 0x12))
+	assert.Equal(t, 0x02, l.Apply(0x94A7, 0x03))
+	assert.Equal(t, 0x04, l.Apply(0x94A7, 0x04)) // compare value does not match
+	assert.Equal(t, 0x12, l.Apply(0xD1DE, 0x12))
+
+	enabled, err := l.Toggle("GOSSIP")
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	assert.Equal(t, 0x12, l.Apply(0xD1DD, 0x12))
+
+	assert.NoError(t, l.SetEnabled("gossip", true))
+	assert.Equal(t, 0x14, l.Apply(0xD1DD, 0x12))
+
+	err = l.SetEnabled("PIGOAP", true)
+	assert.True(t, errors.Is(err, ErrUnknownCode))
+}
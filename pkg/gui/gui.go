@@ -0,0 +1,23 @@
+//go:build !nesgo
+
+// Package gui implements the GUI renderers of the emulator. Unlike the renderers
+// of the retrogolib gui package, which only forward the keys of a single NES
+// controller, all keyboard keys and the buttons of host gamepads are forwarded
+// to the backend.
+package gui
+
+import (
+	"github.com/retroenv/retrogolib/gui"
+)
+
+// GamepadBackend is implemented by backends that handle host gamepad input.
+// The gamepads are numbered starting at 0, the buttons follow the SDL game
+// controller button numbering.
+type GamepadBackend interface {
+	GamepadButtonDown(gamepad, button int)
+	GamepadButtonUp(gamepad, button int)
+}
+
+// Setup will be set by the GUI renderer that is selected by the build tags,
+// it is nil if the GUI is disabled by the nogui tag.
+var Setup gui.Initializer
@@ -0,0 +1,288 @@
+//go:build !nesgo && !nogui && sdl
+
+package gui
+
+import (
+	"fmt"
+
+	"github.com/retroenv/retrogolib/gui"
+	"github.com/retroenv/retrogolib/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func init() {
+	Setup = setupSDLGui
+}
+
+var sdlKeyMapping = map[sdl.Keycode]input.Key{
+	sdl.K_SPACE:        input.Space,
+	sdl.K_QUOTE:        input.Apostrophe,
+	sdl.K_COMMA:        input.Comma,
+	sdl.K_MINUS:        input.Minus,
+	sdl.K_PERIOD:       input.Period,
+	sdl.K_SLASH:        input.Slash,
+	sdl.K_0:            input.Key0,
+	sdl.K_1:            input.Key1,
+	sdl.K_2:            input.Key2,
+	sdl.K_3:            input.Key3,
+	sdl.K_4:            input.Key4,
+	sdl.K_5:            input.Key5,
+	sdl.K_6:            input.Key6,
+	sdl.K_7:            input.Key7,
+	sdl.K_8:            input.Key8,
+	sdl.K_9:            input.Key9,
+	sdl.K_SEMICOLON:    input.Semicolon,
+	sdl.K_EQUALS:       input.Equal,
+	sdl.K_a:            input.A,
+	sdl.K_b:            input.B,
+	sdl.K_c:            input.C,
+	sdl.K_d:            input.D,
+	sdl.K_e:            input.E,
+	sdl.K_f:            input.F,
+	sdl.K_g:            input.G,
+	sdl.K_h:            input.H,
+	sdl.K_i:            input.I,
+	sdl.K_j:            input.J,
+	sdl.K_k:            input.K,
+	sdl.K_l:            input.L,
+	sdl.K_m:            input.M,
+	sdl.K_n:            input.N,
+	sdl.K_o:            input.O,
+	sdl.K_p:            input.P,
+	sdl.K_q:            input.Q,
+	sdl.K_r:            input.R,
+	sdl.K_s:            input.S,
+	sdl.K_t:            input.T,
+	sdl.K_u:            input.U,
+	sdl.K_v:            input.V,
+	sdl.K_w:            input.W,
+	sdl.K_x:            input.X,
+	sdl.K_y:            input.Y,
+	sdl.K_z:            input.Z,
+	sdl.K_LEFTBRACKET:  input.LeftBracket,
+	sdl.K_BACKSLASH:    input.Backslash,
+	sdl.K_RIGHTBRACKET: input.RightBracket,
+	sdl.K_RETURN:       input.Enter,
+	sdl.K_TAB:          input.Tab,
+	sdl.K_BACKSPACE:    input.Backspace,
+	sdl.K_INSERT:       input.Insert,
+	sdl.K_DELETE:       input.Delete,
+	sdl.K_RIGHT:        input.Right,
+	sdl.K_LEFT:         input.Left,
+	sdl.K_DOWN:         input.Down,
+	sdl.K_UP:           input.Up,
+	sdl.K_PAGEUP:       input.PageUp,
+	sdl.K_PAGEDOWN:     input.PageDown,
+	sdl.K_HOME:         input.Home,
+	sdl.K_END:          input.End,
+	sdl.K_CAPSLOCK:     input.CapsLock,
+	sdl.K_SCROLLLOCK:   input.ScrollLock,
+	sdl.K_NUMLOCKCLEAR: input.NumLock,
+	sdl.K_PRINTSCREEN:  input.PrintScreen,
+	sdl.K_PAUSE:        input.Pause,
+	sdl.K_F1:           input.F1,
+	sdl.K_F2:           input.F2,
+	sdl.K_F3:           input.F3,
+	sdl.K_F4:           input.F4,
+	sdl.K_F5:           input.F5,
+	sdl.K_F6:           input.F6,
+	sdl.K_F7:           input.F7,
+	sdl.K_F8:           input.F8,
+	sdl.K_F9:           input.F9,
+	sdl.K_F10:          input.F10,
+	sdl.K_F11:          input.F11,
+	sdl.K_F12:          input.F12,
+	sdl.K_F13:          input.F13,
+	sdl.K_F14:          input.F14,
+	sdl.K_F15:          input.F15,
+	sdl.K_F16:          input.F16,
+	sdl.K_F17:          input.F17,
+	sdl.K_F18:          input.F18,
+	sdl.K_F19:          input.F19,
+	sdl.K_F20:          input.F20,
+	sdl.K_F21:          input.F21,
+	sdl.K_F22:          input.F22,
+	sdl.K_F23:          input.F23,
+	sdl.K_F24:          input.F24,
+	sdl.K_KP_0:         input.KP0,
+	sdl.K_KP_1:         input.KP1,
+	sdl.K_KP_2:         input.KP2,
+	sdl.K_KP_3:         input.KP3,
+	sdl.K_KP_4:         input.KP4,
+	sdl.K_KP_5:         input.KP5,
+	sdl.K_KP_6:         input.KP6,
+	sdl.K_KP_7:         input.KP7,
+	sdl.K_KP_8:         input.KP8,
+	sdl.K_KP_9:         input.KP9,
+	sdl.K_KP_PERIOD:    input.KPDecimal,
+	sdl.K_KP_DIVIDE:    input.KPDivide,
+	sdl.K_KP_MULTIPLY:  input.KPMultiply,
+	sdl.K_KP_MINUS:     input.KPSubtract,
+	sdl.K_KP_PLUS:      input.KPAdd,
+	sdl.K_KP_ENTER:     input.KPEnter,
+	sdl.K_KP_EQUALS:    input.KPEqual,
+	sdl.K_LSHIFT:       input.LeftShift,
+	sdl.K_LCTRL:        input.LeftControl,
+	sdl.K_LALT:         input.LeftAlt,
+	sdl.K_LGUI:         input.LeftSuper,
+	sdl.K_RSHIFT:       input.RightShift,
+	sdl.K_RCTRL:        input.RightControl,
+	sdl.K_RALT:         input.RightAlt,
+	sdl.K_RGUI:         input.RightSuper,
+	sdl.K_MENU:         input.Menu,
+}
+
+// sdlGamepad is an opened game controller and the index it is reported as.
+type sdlGamepad struct {
+	controller *sdl.GameController
+	index      int
+}
+
+// sdlGamepads contains the opened game controllers by their joystick instance id.
+type sdlGamepads map[sdl.JoystickID]sdlGamepad
+
+func setupSDLGui(backend gui.Backend) (guiRender func() (bool, error), guiCleanup func(), err error) {
+	dimensions := backend.Dimensions()
+
+	window, renderer, tex, err := setupSDL(dimensions, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gamepads := sdlGamepads{}
+
+	render := func() (bool, error) {
+		return renderSDL(backend, gamepads, renderer, tex)
+	}
+
+	cleanup := func() {
+		for _, gamepad := range gamepads {
+			gamepad.controller.Close()
+		}
+		_ = tex.Destroy()
+		_ = renderer.Destroy()
+		_ = window.Destroy()
+		sdl.Quit()
+	}
+	return render, cleanup, nil
+}
+
+func setupSDL(dimensions gui.Dimensions, backend gui.Backend) (*sdl.Window, *sdl.Renderer, *sdl.Texture, error) {
+	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
+		return nil, nil, nil, fmt.Errorf("initializing SDL: %w", err)
+	}
+
+	height := int32(float64(dimensions.Height) * dimensions.ScaleFactor)
+	width := int32(float64(dimensions.Width) * dimensions.ScaleFactor)
+
+	window, err := sdl.CreateWindow(backend.WindowTitle(), sdl.WINDOWPOS_CENTERED,
+		sdl.WINDOWPOS_CENTERED, width, height,
+		sdl.WINDOW_SHOWN|sdl.WINDOW_ALLOW_HIGHDPI)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating SDL window: %w", err)
+	}
+
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating SDL renderer: %w", err)
+	}
+
+	tex, err := renderer.CreateTexture(uint32(sdl.PIXELFORMAT_ABGR8888),
+		sdl.TEXTUREACCESS_STREAMING, int32(dimensions.Width), int32(dimensions.Height))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating SDL texture: %w", err)
+	}
+
+	return window, renderer, tex, nil
+}
+
+func renderSDL(backend gui.Backend, gamepads sdlGamepads, renderer *sdl.Renderer, tex *sdl.Texture) (bool, error) {
+	running := true
+
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		switch et := event.(type) {
+		case *sdl.QuitEvent:
+			running = false
+
+		case *sdl.KeyboardEvent:
+			if et.Type == sdl.KEYDOWN && et.Keysym.Sym == sdl.K_ESCAPE {
+				running = false
+				break
+			}
+			onSDLKey(backend, et)
+
+		case *sdl.ControllerDeviceEvent:
+			gamepads.onDeviceEvent(et)
+
+		case *sdl.ControllerButtonEvent:
+			if gamepadBackend, ok := backend.(GamepadBackend); ok {
+				gamepads.onButtonEvent(gamepadBackend, et)
+			}
+		}
+	}
+
+	image := backend.Image()
+	if err := tex.Update(nil, image.Pix, image.Stride); err != nil {
+		return false, err
+	}
+
+	if err := renderer.Copy(tex, nil, nil); err != nil {
+		return false, err
+	}
+	renderer.Present()
+
+	return running, nil
+}
+
+func onSDLKey(backend gui.Backend, event *sdl.KeyboardEvent) {
+	hostKey, ok := sdlKeyMapping[event.Keysym.Sym]
+	if !ok || event.Repeat != 0 {
+		return
+	}
+
+	switch event.Type {
+	case sdl.KEYDOWN:
+		backend.KeyDown(hostKey)
+
+	case sdl.KEYUP:
+		backend.KeyUp(hostKey)
+	}
+}
+
+// onDeviceEvent opens connected game controllers and closes disconnected ones.
+// The device index of a connected controller is used as gamepad index.
+func (g sdlGamepads) onDeviceEvent(event *sdl.ControllerDeviceEvent) {
+	switch event.Type {
+	case sdl.CONTROLLERDEVICEADDED:
+		index := int(event.Which)
+		controller := sdl.GameControllerOpen(index)
+		if controller == nil {
+			return
+		}
+		g[controller.Joystick().InstanceID()] = sdlGamepad{
+			controller: controller,
+			index:      index,
+		}
+
+	case sdl.CONTROLLERDEVICEREMOVED:
+		if gamepad, ok := g[event.Which]; ok {
+			gamepad.controller.Close()
+			delete(g, event.Which)
+		}
+	}
+}
+
+func (g sdlGamepads) onButtonEvent(backend GamepadBackend, event *sdl.ControllerButtonEvent) {
+	gamepad, ok := g[event.Which]
+	if !ok {
+		return
+	}
+
+	switch event.Type {
+	case sdl.CONTROLLERBUTTONDOWN:
+		backend.GamepadButtonDown(gamepad.index, int(event.Button))
+
+	case sdl.CONTROLLERBUTTONUP:
+		backend.GamepadButtonUp(gamepad.index, int(event.Button))
+	}
+}
@@ -0,0 +1,202 @@
+// Package ines implements parsing of iNES and NES 2.0 file headers.
+package ines
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// HeaderSize is the size of an iNES header in bytes.
+const HeaderSize = 16
+
+const (
+	prgBankSize = 16384
+	chrBankSize = 8192
+	trainerSize = 512
+
+	// maxROMSize limits the ROM sizes of the exponent-multiplier notation, which
+	// can define sizes that overflow an int. The largest size of the regular
+	// notation is slightly below this limit.
+	maxROMSize = 64 * 1024 * 1024
+)
+
+// Console types of the NES 2.0 header.
+const (
+	ConsoleNES        = 0
+	ConsoleVsSystem   = 1
+	ConsolePlaychoice = 2
+	ConsoleExtended   = 3
+)
+
+var (
+	// ErrInvalidMagic is returned when the data does not start with the iNES magic bytes.
+	ErrInvalidMagic = errors.New("invalid file header magic")
+	// ErrHeaderTooShort is returned when the data is shorter than the header size.
+	ErrHeaderTooShort = errors.New("header too short")
+	// ErrInvalidROMSize is returned when a NES 2.0 header defines a ROM size above the supported limit.
+	ErrInvalidROMSize = errors.New("invalid ROM size")
+)
+
+var fileMagic = []byte{'N', 'E', 'S', 0x1a}
+
+// Header contains the fields of an iNES or NES 2.0 file header.
+type Header struct {
+	NES2 bool // whether the header is in NES 2.0 format
+
+	Mapper    uint16
+	SubMapper byte // only set for NES 2.0 headers
+
+	PRGSize      int // PRG-ROM size in bytes
+	CHRSize      int // CHR-ROM size in bytes
+	PRGRAMSize   int // volatile PRG-RAM size in bytes
+	PRGNVRAMSize int // battery backed PRG-RAM size in bytes
+	CHRRAMSize   int // volatile CHR-RAM size in bytes
+	CHRNVRAMSize int // battery backed CHR-RAM size in bytes
+
+	Mirror     cartridge.MirrorMode
+	FourScreen bool
+	Battery    bool
+	Trainer    bool
+
+	ConsoleType     byte
+	Region          region.Region
+	MultiRegion     bool // whether the game supports multiple regions
+	MiscROMs        byte // amount of miscellaneous ROMs, only set for NES 2.0 headers
+	ExpansionDevice byte // default expansion device, only set for NES 2.0 headers
+}
+
+// Parse parses the header at the start of the given iNES file data.
+func Parse(data []byte) (Header, error) {
+	if len(data) < HeaderSize {
+		return Header{}, fmt.Errorf("%w: %d bytes", ErrHeaderTooShort, len(data))
+	}
+	if !bytes.Equal(data[:4], fileMagic) {
+		return Header{}, ErrInvalidMagic
+	}
+
+	h := Header{
+		NES2:        data[7]&0x0C == 0x08,
+		Battery:     data[6]&0x02 != 0,
+		Trainer:     data[6]&0x04 != 0,
+		FourScreen:  data[6]&0x08 != 0,
+		ConsoleType: data[7] & 0x03,
+	}
+
+	if data[6]&0x01 != 0 {
+		h.Mirror = cartridge.MirrorVertical
+	} else {
+		h.Mirror = cartridge.MirrorHorizontal
+	}
+	if h.FourScreen {
+		h.Mirror = cartridge.Mirror4
+	}
+
+	if !h.NES2 {
+		h.parseINES(data)
+		return h, nil
+	}
+	if err := h.parseNES2(data); err != nil {
+		return Header{}, err
+	}
+	return h, nil
+}
+
+// parseINES parses the fields that are specific to the original iNES format.
+func (h *Header) parseINES(data []byte) {
+	h.Mapper = uint16(data[6] >> 4)
+	// old headers can contain garbage like "DiskDude!" in the bytes 7-15,
+	// in that case the upper mapper nibble is ignored
+	if bytes.Equal(data[12:16], []byte{0, 0, 0, 0}) {
+		h.Mapper |= uint16(data[7] & 0xF0)
+	}
+
+	h.PRGSize = int(data[4]) * prgBankSize
+	h.CHRSize = int(data[5]) * chrBankSize
+	if h.CHRSize == 0 {
+		h.CHRRAMSize = chrBankSize
+	}
+
+	ramSize := int(data[8]) * 8192
+	if ramSize == 0 {
+		ramSize = 8192
+	}
+	if h.Battery {
+		h.PRGNVRAMSize = ramSize
+	} else {
+		h.PRGRAMSize = ramSize
+	}
+
+	if data[9]&0x01 != 0 {
+		h.Region = region.PAL
+	}
+}
+
+// parseNES2 parses the fields that are specific to the NES 2.0 format.
+func (h *Header) parseNES2(data []byte) error {
+	h.Mapper = uint16(data[6]>>4) | uint16(data[7]&0xF0) | uint16(data[8]&0x0F)<<8
+	h.SubMapper = data[8] >> 4
+
+	var err error
+	h.PRGSize, err = romSize(data[4], data[9]&0x0F, prgBankSize)
+	if err != nil {
+		return fmt.Errorf("parsing PRG-ROM size: %w", err)
+	}
+	h.CHRSize, err = romSize(data[5], data[9]>>4, chrBankSize)
+	if err != nil {
+		return fmt.Errorf("parsing CHR-ROM size: %w", err)
+	}
+
+	h.PRGRAMSize = ramSize(data[10] & 0x0F)
+	h.PRGNVRAMSize = ramSize(data[10] >> 4)
+	h.CHRRAMSize = ramSize(data[11] & 0x0F)
+	h.CHRNVRAMSize = ramSize(data[11] >> 4)
+
+	switch data[12] & 0x03 {
+	case 1:
+		h.Region = region.PAL
+	case 2:
+		h.MultiRegion = true
+	case 3:
+		h.Region = region.Dendy
+	}
+
+	h.MiscROMs = data[14] & 0x03
+	h.ExpansionDevice = data[15] & 0x3F
+	return nil
+}
+
+// romSize returns the ROM size based on the LSB and MSB nibble of the NES 2.0 header.
+// If the MSB nibble is $F, the exponent-multiplier notation is used.
+func romSize(lsb, msb byte, bankSize int) (int, error) {
+	if msb != 0x0F {
+		return (int(msb)<<8 | int(lsb)) * bankSize, nil
+	}
+
+	exponent := lsb >> 2
+	multiplier := int(lsb&0x03)*2 + 1
+	if int(exponent) >= bits.Len(maxROMSize) || (1<<exponent)*multiplier > maxROMSize {
+		return 0, fmt.Errorf("%w: 2^%d * %d bytes", ErrInvalidROMSize, exponent, multiplier)
+	}
+	return (1 << exponent) * multiplier, nil
+}
+
+// ramSize returns the RAM size of a NES 2.0 shift count.
+func ramSize(shift byte) int {
+	if shift == 0 {
+		return 0
+	}
+	return 64 << shift
+}
+
+// TrainerSize returns the size of the trainer in bytes.
+func (h Header) TrainerSize() int {
+	if h.Trainer {
+		return trainerSize
+	}
+	return 0
+}
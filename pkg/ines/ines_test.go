@@ -0,0 +1,94 @@
+package ines
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestParseINES(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{'N', 'E', 'S', 0x1a, 2, 1, 0x13, 0x00, 0, 1, 0, 0, 0, 0, 0, 0}
+	h, err := Parse(data)
+	assert.NoError(t, err)
+	assert.False(t, h.NES2)
+	assert.Equal(t, 1, h.Mapper)
+	assert.Equal(t, 2*16384, h.PRGSize)
+	assert.Equal(t, 8192, h.CHRSize)
+	assert.Equal(t, 8192, h.PRGNVRAMSize)
+	assert.Equal(t, cartridge.MirrorVertical, h.Mirror)
+	assert.True(t, h.Battery)
+	assert.False(t, h.Trainer)
+	assert.Equal(t, region.PAL, h.Region)
+}
+
+func TestParseINESGarbage(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{'N', 'E', 'S', 0x1a, 1, 0, 0x40, 'D', 'i', 's', 'k', 'D', 'u', 'd', 'e', '!'}
+	h, err := Parse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, h.Mapper)
+	assert.Equal(t, 8192, h.CHRRAMSize)
+}
+
+func TestParseNES2(t *testing.T) {
+	t.Parallel()
+
+	data := []byte{'N', 'E', 'S', 0x1a, 0x10, 0x00, 0x4A, 0x18, 0x21, 0x01, 0x70, 0x07, 0x03, 0x00, 0x00, 0x01}
+	h, err := Parse(data)
+	assert.NoError(t, err)
+	assert.True(t, h.NES2)
+	assert.Equal(t, 0x114, h.Mapper)
+	assert.Equal(t, 2, h.SubMapper)
+	assert.Equal(t, 0x110*16384, h.PRGSize)
+	assert.Equal(t, 0, h.CHRSize)
+	assert.Equal(t, 0, h.PRGRAMSize)
+	assert.Equal(t, 8192, h.PRGNVRAMSize)
+	assert.Equal(t, 8192, h.CHRRAMSize)
+	assert.Equal(t, cartridge.Mirror4, h.Mirror)
+	assert.Equal(t, region.Dendy, h.Region)
+	assert.Equal(t, 1, h.ExpansionDevice)
+}
+
+func TestParseNES2ExponentSize(t *testing.T) {
+	t.Parallel()
+
+	// 2^3 * 3 = 24 bytes
+	data := []byte{'N', 'E', 'S', 0x1a, 0x0D, 0x00, 0x00, 0x08, 0x00, 0x0F, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}
+	h, err := Parse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, h.PRGSize)
+	assert.True(t, h.MultiRegion)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte{'N', 'E', 'S'})
+	assert.True(t, errors.Is(err, ErrHeaderTooShort))
+
+	_, err = Parse(make([]byte, HeaderSize))
+	assert.True(t, errors.Is(err, ErrInvalidMagic))
+
+	// CHR-ROM size of 2^63 bytes
+	data := []byte{'N', 'E', 'S', 0x1a, 0x01, 0xFC, 0x00, 0x08, 0x00, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	_, err = Parse(data)
+	assert.True(t, errors.Is(err, ErrInvalidROMSize))
+
+	// PRG-ROM size of 2^26 * 3 bytes
+	data[5], data[9] = 0x01, 0x0F
+	data[4] = 26<<2 | 1
+	_, err = Parse(data)
+	assert.True(t, errors.Is(err, ErrInvalidROMSize))
+
+	// PRG-ROM size of 2^26 bytes is the largest supported size
+	data[4] = 26 << 2
+	h, err := Parse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1<<26, h.PRGSize)
+}
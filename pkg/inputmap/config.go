@@ -0,0 +1,259 @@
+//go:build !nesgo
+
+package inputmap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/retrogolib/input"
+)
+
+// ErrInvalidConfig is returned for config files that can not be parsed.
+var ErrInvalidConfig = errors.New("invalid input config")
+
+// FileName is the name of the config file in the nesgo config directory.
+const FileName = "input.toml"
+
+var buttonNames = map[string]controller.Button{
+	"a":      controller.A,
+	"b":      controller.B,
+	"select": controller.Select,
+	"start":  controller.Start,
+	"up":     controller.Up,
+	"down":   controller.Down,
+	"left":   controller.Left,
+	"right":  controller.Right,
+}
+
+// DefaultPath returns the path of the config file in the user config directory,
+// for example ~/.config/nesgo/input.toml on Linux.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("getting user config directory: %w", err)
+	}
+	return filepath.Join(dir, "nesgo", FileName), nil
+}
+
+// Load reads the mapping from the given config file.
+func Load(fileName string) (*Mapping, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening file '%s': %w", fileName, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return Read(file)
+}
+
+// Read reads a mapping config in a TOML subset that supports tables and
+// string and integer values:
+//
+//	[player1]
+//	a = "Z"
+//	start = "Enter"
+//
+//	[player1.gamepad]
+//	index = 0
+//	a = 1
+//
+//	[hotkeys]
+//	pause = "P"
+//	fast_forward = "Tab"
+//
+// Every table replaces the default bindings of its player or the hotkeys,
+// tables that are not specified keep the default bindings.
+func Read(reader io.Reader) (*Mapping, error) {
+	tables, err := parseTables(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	m := Default()
+	for _, t := range tables {
+		if err := m.applyTable(t); err != nil {
+			return nil, fmt.Errorf("table [%s]: %w", t.name, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *Mapping) applyTable(t table) error {
+	switch t.name {
+	case "player1", "player2":
+		return m.applyPlayerKeys(int(t.name[6]-'0'), t)
+	case "player1.gamepad", "player2.gamepad":
+		return m.applyPlayerGamepad(int(t.name[6]-'0'), t)
+	case "hotkeys":
+		return m.applyHotkeys(t)
+	default:
+		return fmt.Errorf("%w: unknown table", ErrInvalidConfig)
+	}
+}
+
+func (m *Mapping) applyPlayerKeys(player int, t table) error {
+	m.removeKeys(player)
+	for _, entry := range t.entries {
+		button, ok := buttonNames[entry.key]
+		if !ok {
+			return fmt.Errorf("%w: unknown button '%s'", ErrInvalidConfig, entry.key)
+		}
+		key, ok := parseKey(entry.value)
+		if !ok {
+			return fmt.Errorf("%w: unknown key '%s'", ErrInvalidConfig, entry.value)
+		}
+		if key != input.Unknown {
+			m.Keys[key] = Binding{Player: player, Button: button}
+		}
+	}
+	return nil
+}
+
+func (m *Mapping) applyPlayerGamepad(player int, t table) error {
+	m.removeGamepadButtons(player)
+	gamepad := player - 1
+	buttons := map[controller.Button]int{}
+
+	for _, entry := range t.entries {
+		index, err := strconv.Atoi(entry.value)
+		if err != nil {
+			return fmt.Errorf("%w: invalid number '%s'", ErrInvalidConfig, entry.value)
+		}
+		if entry.key == "index" {
+			gamepad = index
+			continue
+		}
+
+		button, ok := buttonNames[entry.key]
+		if !ok {
+			return fmt.Errorf("%w: unknown button '%s'", ErrInvalidConfig, entry.key)
+		}
+		buttons[button] = index
+	}
+
+	for button, index := range buttons {
+		m.Gamepad[GamepadButton{Gamepad: gamepad, Button: index}] = Binding{
+			Player: player,
+			Button: button,
+		}
+	}
+	return nil
+}
+
+func (m *Mapping) applyHotkeys(t table) error {
+	m.removeKeys(0)
+	for _, entry := range t.entries {
+		action := NoAction
+		for a, name := range actionNames {
+			if name == entry.key {
+				action = a
+			}
+		}
+		if action == NoAction {
+			return fmt.Errorf("%w: unknown hotkey '%s'", ErrInvalidConfig, entry.key)
+		}
+
+		key, ok := parseKey(entry.value)
+		if !ok {
+			return fmt.Errorf("%w: unknown key '%s'", ErrInvalidConfig, entry.value)
+		}
+		if key != input.Unknown {
+			m.Keys[key] = Binding{Action: action}
+		}
+	}
+	return nil
+}
+
+type entry struct {
+	key   string
+	value string
+}
+
+type table struct {
+	name    string
+	entries []entry
+}
+
+// parseTables parses the tables of a TOML subset. Strings are returned without
+// quotes, all other values are returned unmodified.
+func parseTables(reader io.Reader) ([]table, error) {
+	var tables []table
+	scanner := bufio.NewScanner(reader)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: %w: unterminated table header", lineNumber, ErrInvalidConfig)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			tables = append(tables, table{name: strings.ToLower(name)})
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || len(tables) == 0 {
+			return nil, fmt.Errorf("line %d: %w: expected key = value in a table", lineNumber, ErrInvalidConfig)
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		t := &tables[len(tables)-1]
+		t.entries = append(t.entries, entry{
+			key:   strings.ToLower(strings.TrimSpace(key)),
+			value: value,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	return tables, nil
+}
+
+// stripComment removes a comment that is not part of a string from the line.
+func stripComment(line string) string {
+	quoted := false
+	for i, c := range line {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '#':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func parseValue(value string) (string, error) {
+	if !strings.HasPrefix(value, `"`) {
+		if value == "" {
+			return "", fmt.Errorf("%w: missing value", ErrInvalidConfig)
+		}
+		return value, nil
+	}
+
+	s, err := strconv.Unquote(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid string %s", ErrInvalidConfig, value)
+	}
+	return s, nil
+}
@@ -0,0 +1,145 @@
+//go:build !nesgo
+
+// Package inputmap implements the mapping of host keyboard keys and gamepad
+// buttons to NES controller buttons and emulator hotkeys.
+package inputmap
+
+import (
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/retrogolib/input"
+)
+
+// Action defines an emulator action that can be triggered by a hotkey.
+type Action int
+
+const (
+	NoAction       Action = iota
+	Pause                 // toggle between paused and running emulation
+	Reset                 // reset the system
+	PowerCycle            // power cycle the system
+	SaveState             // save the emulation state
+	LoadState             // load the emulation state
+	Screenshot            // save a screenshot of the current frame
+	FastForward           // run the emulation as fast as possible while held
+	SwitchDiskSide        // insert the next side of the Famicom Disk System disk
+)
+
+var actionNames = map[Action]string{
+	Pause:          "pause",
+	Reset:          "reset",
+	PowerCycle:     "power_cycle",
+	SaveState:      "save_state",
+	LoadState:      "load_state",
+	Screenshot:     "screenshot",
+	FastForward:    "fast_forward",
+	SwitchDiskSide: "switch_disk_side",
+}
+
+// String returns the name of the action as used in the config file.
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// Binding defines the target of a host key or gamepad button. It either
+// references a button of a NES controller or an emulator action.
+type Binding struct {
+	Player int // controller port 1 or 2, 0 for hotkeys
+	Button controller.Button
+	Action Action
+}
+
+// GamepadButton defines a button of a host gamepad.
+type GamepadButton struct {
+	Gamepad int // index of the host gamepad
+	Button  int // index of the button
+}
+
+// Mapping contains the bindings of all host keys and gamepad buttons.
+type Mapping struct {
+	Keys    map[input.Key]Binding
+	Gamepad map[GamepadButton]Binding
+}
+
+// New returns a new mapping without any bindings.
+func New() *Mapping {
+	return &Mapping{
+		Keys:    map[input.Key]Binding{},
+		Gamepad: map[GamepadButton]Binding{},
+	}
+}
+
+// Default returns the default mapping. The gamepad buttons follow the SDL game
+// controller button numbering.
+func Default() *Mapping {
+	m := New()
+	m.Keys = map[input.Key]Binding{
+		input.Up:        {Player: 1, Button: controller.Up},
+		input.Down:      {Player: 1, Button: controller.Down},
+		input.Left:      {Player: 1, Button: controller.Left},
+		input.Right:     {Player: 1, Button: controller.Right},
+		input.Z:         {Player: 1, Button: controller.A},
+		input.X:         {Player: 1, Button: controller.B},
+		input.Enter:     {Player: 1, Button: controller.Start},
+		input.Backspace: {Player: 1, Button: controller.Select},
+
+		input.P:   {Action: Pause},
+		input.F1:  {Action: Reset},
+		input.F2:  {Action: PowerCycle},
+		input.F3:  {Action: SwitchDiskSide},
+		input.F5:  {Action: SaveState},
+		input.F7:  {Action: LoadState},
+		input.F12: {Action: Screenshot},
+		input.Tab: {Action: FastForward},
+	}
+
+	for player := 1; player <= 2; player++ {
+		for button, index := range defaultGamepadButtons {
+			m.Gamepad[GamepadButton{Gamepad: player - 1, Button: index}] = Binding{
+				Player: player,
+				Button: button,
+			}
+		}
+	}
+	return m
+}
+
+var defaultGamepadButtons = map[controller.Button]int{
+	controller.A:      1,
+	controller.B:      0,
+	controller.Select: 4,
+	controller.Start:  6,
+	controller.Up:     11,
+	controller.Down:   12,
+	controller.Left:   13,
+	controller.Right:  14,
+}
+
+// Key returns the binding of the given host key.
+func (m *Mapping) Key(key input.Key) (Binding, bool) {
+	binding, ok := m.Keys[key]
+	return binding, ok
+}
+
+// GamepadButton returns the binding of the button of the given host gamepad.
+func (m *Mapping) GamepadButton(gamepad, button int) (Binding, bool) {
+	binding, ok := m.Gamepad[GamepadButton{Gamepad: gamepad, Button: button}]
+	return binding, ok
+}
+
+// removeKeys removes all key bindings of the given player, 0 removes all hotkeys.
+func (m *Mapping) removeKeys(player int) {
+	for key, binding := range m.Keys {
+		if binding.Player == player {
+			delete(m.Keys, key)
+		}
+	}
+}
+
+// removeGamepadButtons removes all gamepad bindings of the given player.
+func (m *Mapping) removeGamepadButtons(player int) {
+	for button, binding := range m.Gamepad {
+		if binding.Player == player {
+			delete(m.Gamepad, button)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package inputmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/retrogolib/assert"
+	"github.com/retroenv/retrogolib/input"
+)
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	m := Default()
+	binding, ok := m.Key(input.Z)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Player: 1, Button: controller.A}, binding)
+
+	binding, ok = m.Key(input.Tab)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Action: FastForward}, binding)
+
+	binding, ok = m.GamepadButton(1, 6)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Player: 2, Button: controller.Start}, binding)
+
+	_, ok = m.Key(input.Q)
+	assert.False(t, ok)
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	config := `# custom mapping
+[player2]
+a = "k"  # comment
+b = "J"
+left = ""
+
+[player1.gamepad]
+index = 2
+a = 0
+
+[hotkeys]
+pause = "Space"
+fast_forward = "KPAdd"
+`
+	m, err := Read(strings.NewReader(config))
+	assert.NoError(t, err)
+
+	// player 1 keys keep the defaults
+	binding, ok := m.Key(input.Z)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Player: 1, Button: controller.A}, binding)
+
+	binding, ok = m.Key(input.K)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Player: 2, Button: controller.A}, binding)
+
+	binding, ok = m.GamepadButton(2, 0)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Player: 1, Button: controller.A}, binding)
+	_, ok = m.GamepadButton(0, 6)
+	assert.False(t, ok)
+	_, ok = m.GamepadButton(1, 6)
+	assert.True(t, ok)
+
+	binding, ok = m.Key(input.Space)
+	assert.True(t, ok)
+	assert.Equal(t, Binding{Action: Pause}, binding)
+	_, ok = m.Key(input.P)
+	assert.False(t, ok)
+	_, ok = m.Key(input.F12)
+	assert.False(t, ok)
+}
+
+func TestReadErrors(t *testing.T) {
+	t.Parallel()
+
+	configs := []string{
+		"a = \"Z\"",
+		"[player3]",
+		"[player1\na = \"Z\"",
+		"[player1]\nc = \"Z\"",
+		"[player1]\na = \"NoKey\"",
+		"[player1]\na =",
+		"[player1]\na = \"Z",
+		"[player1.gamepad]\na = \"Z\"",
+		"[hotkeys]\nrewind = \"R\"",
+	}
+	for _, config := range configs {
+		_, err := Read(strings.NewReader(config))
+		assert.True(t, errors.Is(err, ErrInvalidConfig), config)
+	}
+}
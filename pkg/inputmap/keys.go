@@ -0,0 +1,45 @@
+//go:build !nesgo
+
+package inputmap
+
+import (
+	"strings"
+
+	"github.com/retroenv/retrogolib/input"
+)
+
+// keyNames contains the config file names of the host keys, they match the
+// constant names of the retrogolib input package.
+var keyNames = map[string]input.Key{}
+
+func init() {
+	names := []string{
+		"Unknown", "Space", "Apostrophe", "Comma", "Minus", "Period", "Slash",
+		"0", "1", "2", "3", "4", "5", "6", "7", "8", "9",
+		"Semicolon", "Equal",
+		"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
+		"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z",
+		"LeftBracket", "Backslash", "RightBracket", "Escape", "Enter", "Tab", "Backspace",
+		"Insert", "Delete", "Right", "Left", "Down", "Up", "PageUp", "PageDown", "Home", "End",
+		"CapsLock", "ScrollLock", "NumLock", "PrintScreen", "Pause",
+		"F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12", "F13",
+		"F14", "F15", "F16", "F17", "F18", "F19", "F20", "F21", "F22", "F23", "F24", "F25",
+		"KP0", "KP1", "KP2", "KP3", "KP4", "KP5", "KP6", "KP7", "KP8", "KP9",
+		"KPDecimal", "KPDivide", "KPMultiply", "KPSubtract", "KPAdd", "KPEnter", "KPEqual",
+		"LeftShift", "LeftControl", "LeftAlt", "LeftSuper",
+		"RightShift", "RightControl", "RightAlt", "RightSuper", "Menu",
+	}
+	for i, name := range names {
+		keyNames[strings.ToLower(name)] = input.Key(i)
+	}
+}
+
+// parseKey returns the host key for the given name, an empty name returns
+// input.Unknown to unbind a key.
+func parseKey(name string) (input.Key, bool) {
+	if name == "" {
+		return input.Unknown, true
+	}
+	key, ok := keyNames[strings.ToLower(name)]
+	return key, ok && key != input.Unknown
+}
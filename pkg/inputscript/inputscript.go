@@ -0,0 +1,196 @@
+//go:build !nesgo
+
+// Package inputscript implements a simple script format to define controller
+// input at specific frames, for example for headless test runs.
+//
+// A script consists of statements that are separated by newlines or semicolons,
+// everything following a # is a comment:
+//
+//	frame 120: press Start
+//	frame 125: release Start
+//	frame 200-260: hold Right+A
+//	frame 300: press 2:Select
+//
+// The buttons can be prefixed by the controller port 1 to 4, the default port is 1.
+// Ports 3 and 4 address the gamepads of a Four Score adapter.
+// A hold action presses the buttons at the first frame and releases them after the last frame.
+package inputscript
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+)
+
+// ErrInvalidStatement is returned for statements that can not be parsed.
+var ErrInvalidStatement = errors.New("invalid statement")
+
+var buttonNames = map[string]controller.Button{
+	"a":      controller.A,
+	"b":      controller.B,
+	"select": controller.Select,
+	"start":  controller.Start,
+	"up":     controller.Up,
+	"down":   controller.Down,
+	"left":   controller.Left,
+	"right":  controller.Right,
+}
+
+// Event defines a change of button states at a frame.
+type Event struct {
+	Frame   uint64
+	Port    int // controller port 1 to 4
+	Buttons controller.Button
+	Pressed bool
+}
+
+// Script contains all input events of a script, sorted by frame.
+type Script struct {
+	Events []Event
+}
+
+// Parse parses an input script.
+func Parse(reader io.Reader) (*Script, error) {
+	s := &Script{}
+	scanner := bufio.NewScanner(reader)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		for _, statement := range strings.Split(line, ";") {
+			statement = strings.TrimSpace(statement)
+			if statement == "" {
+				continue
+			}
+			if err := s.parseStatement(statement); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading script: %w", err)
+	}
+
+	sort.SliceStable(s.Events, func(i, j int) bool {
+		return s.Events[i].Frame < s.Events[j].Frame
+	})
+	return s, nil
+}
+
+// EventsAt returns all events of the given frame.
+func (s *Script) EventsAt(frame uint64) []Event {
+	start := sort.Search(len(s.Events), func(i int) bool {
+		return s.Events[i].Frame >= frame
+	})
+	end := start
+	for end < len(s.Events) && s.Events[end].Frame == frame {
+		end++
+	}
+	return s.Events[start:end]
+}
+
+// parseStatement parses a statement in the form "frame 200-260: hold Right+A".
+func (s *Script) parseStatement(statement string) error {
+	frameDefinition, action, ok := strings.Cut(statement, ":")
+	if !ok {
+		return fmt.Errorf("%w: '%s' is missing ':'", ErrInvalidStatement, statement)
+	}
+
+	fields := strings.Fields(frameDefinition)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "frame") {
+		return fmt.Errorf("%w: '%s' does not start with a frame definition", ErrInvalidStatement, statement)
+	}
+	first, last, err := parseFrames(fields[1])
+	if err != nil {
+		return err
+	}
+
+	fields = strings.Fields(action)
+	if len(fields) != 2 {
+		return fmt.Errorf("%w: '%s' has an invalid action", ErrInvalidStatement, statement)
+	}
+	port, buttons, err := parseButtons(fields[1])
+	if err != nil {
+		return err
+	}
+
+	action = strings.ToLower(fields[0])
+	if first != last && action != "hold" {
+		return fmt.Errorf("%w: frame ranges are only supported for hold", ErrInvalidStatement)
+	}
+
+	event := Event{
+		Frame:   first,
+		Port:    port,
+		Buttons: buttons,
+	}
+
+	switch action {
+	case "press":
+		event.Pressed = true
+		s.Events = append(s.Events, event)
+
+	case "release":
+		s.Events = append(s.Events, event)
+
+	case "hold":
+		event.Pressed = true
+		s.Events = append(s.Events, event)
+		event.Frame = last + 1
+		event.Pressed = false
+		s.Events = append(s.Events, event)
+
+	default:
+		return fmt.Errorf("%w: unknown action '%s'", ErrInvalidStatement, fields[0])
+	}
+	return nil
+}
+
+// parseFrames parses a single frame or a frame range like 200-260.
+func parseFrames(s string) (uint64, uint64, error) {
+	firstString, lastString, isRange := strings.Cut(s, "-")
+
+	first, err := strconv.ParseUint(firstString, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid frame '%s'", ErrInvalidStatement, firstString)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+
+	last, err := strconv.ParseUint(lastString, 10, 64)
+	if err != nil || last < first {
+		return 0, 0, fmt.Errorf("%w: invalid frame range '%s'", ErrInvalidStatement, s)
+	}
+	return first, last, nil
+}
+
+// parseButtons parses a button list like 2:Right+A.
+func parseButtons(s string) (int, controller.Button, error) {
+	port := 1
+	if portString, buttonList, ok := strings.Cut(s, ":"); ok {
+		switch portString {
+		case "1", "2", "3", "4":
+			port = int(portString[0] - '0')
+		default:
+			return 0, 0, fmt.Errorf("%w: invalid port '%s'", ErrInvalidStatement, portString)
+		}
+		s = buttonList
+	}
+
+	var buttons controller.Button
+	for _, name := range strings.Split(s, "+") {
+		button, ok := buttonNames[strings.ToLower(name)]
+		if !ok {
+			return 0, 0, fmt.Errorf("%w: unknown button '%s'", ErrInvalidStatement, name)
+		}
+		buttons |= button
+	}
+	return port, buttons, nil
+}
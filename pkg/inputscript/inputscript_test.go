@@ -0,0 +1,56 @@
+package inputscript
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	script := `frame 120: press Start; frame 125: release Start
+# comment
+frame 200-260: hold Right+A # run
+frame 130: press 2:select
+frame 140: press 4:B
+`
+	s, err := Parse(strings.NewReader(script))
+	assert.NoError(t, err)
+
+	expected := []Event{
+		{Frame: 120, Port: 1, Buttons: controller.Start, Pressed: true},
+		{Frame: 125, Port: 1, Buttons: controller.Start},
+		{Frame: 130, Port: 2, Buttons: controller.Select, Pressed: true},
+		{Frame: 140, Port: 4, Buttons: controller.B, Pressed: true},
+		{Frame: 200, Port: 1, Buttons: controller.Right | controller.A, Pressed: true},
+		{Frame: 261, Port: 1, Buttons: controller.Right | controller.A},
+	}
+	assert.Equal(t, expected, s.Events)
+
+	assert.Equal(t, expected[2:3], s.EventsAt(130))
+	assert.Equal(t, 0, len(s.EventsAt(121)))
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	scripts := []string{
+		"frame 10 press A",
+		"frames 10: press A",
+		"frame x: press A",
+		"frame 10-5: hold A",
+		"frame 10-20: press A",
+		"frame 10: push A",
+		"frame 10: press C",
+		"frame 10: press 5:A",
+		"frame 10: press",
+	}
+	for _, script := range scripts {
+		_, err := Parse(strings.NewReader(script))
+		assert.True(t, errors.Is(err, ErrInvalidStatement), script)
+	}
+}
@@ -19,13 +19,36 @@ var mappers = map[byte]mapperInitializer{
 	1:   mapperdb.NewMMC1,
 	2:   mapperdb.NewUxROMOr,
 	3:   mapperdb.NewCNROM,
+	5:   mapperdb.NewMMC5,
 	7:   mapperdb.NewAxROM,
+	9:   mapperdb.NewMMC2,
+	10:  mapperdb.NewMMC4,
+	11:  mapperdb.NewColorDreams,
+	20:  mapperdb.NewFDS,
+	21:  mapperdb.NewVRC4a,
+	22:  mapperdb.NewVRC2a,
+	23:  mapperdb.NewVRC2b,
+	24:  mapperdb.NewVRC6a,
+	25:  mapperdb.NewVRC2c,
+	26:  mapperdb.NewVRC6b,
 	30:  mapperdb.NewUNROM512,
+	34:  mapperdb.NewMapper34,
+	66:  mapperdb.NewGxROM,
+	69:  mapperdb.NewFME7,
 	94:  mapperdb.NewUN1ROM,
 	111: mapperdb.NewGTROM,
 	180: mapperdb.NewUxROMAnd,
 }
 
+// Supported returns whether the mapper with the given number is supported.
+func Supported(mapperNumber uint16) bool {
+	if mapperNumber > 0xff {
+		return false
+	}
+	_, ok := mappers[byte(mapperNumber)]
+	return ok
+}
+
 // New creates a new mapper for the mapper defined by the cartridge.
 func New(bus *bus.Bus) (bus.Mapper, error) {
 	mapperNumber := bus.Cartridge.Mapper
@@ -3,6 +3,7 @@ package mapperbase
 type bank struct {
 	data   []byte
 	length int
+	offset int // offset of the bank data in the PRG or CHR ROM
 }
 
 // setDefaultBankSizes sets the default CHR and PRG sizes based on the set window size.
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/fds"
 	"github.com/retroenv/retrogolib/arch/nes"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 )
@@ -29,8 +30,9 @@ type Base struct {
 	bus  *bus.Bus
 	name string // optional
 
-	chrRAM []byte
-	prgRAM []byte
+	chrRAM         []byte
+	prgRAM         []byte
+	batteryRAMSize int
 
 	mirrorModeTranslation MirrorModeTranslation
 	nameTableCount        int
@@ -48,8 +50,12 @@ type Base struct {
 	chrBankMapper bankMapper
 	prgBankMapper bankMapper
 
-	readHooks  []readHook
-	writeHooks []writeHook
+	readHooks     []*readHook
+	writeHooks    []*writeHook
+	ppuReadHooks  []*readHook
+	ppuWriteHooks []*writeHook
+	cycleHook     func()
+	irqSources    IrqSource // pending interrupt request sources
 }
 
 // New creates a new mapper base.
@@ -86,26 +92,16 @@ func (b *Base) SetName(name string) {
 	b.mu.Unlock()
 }
 
-// Read a byte from a CHR or PRG memory address.
+// Read a byte from a CHR or PRG memory address. The PPU uses ReadPPU instead.
 func (b *Base) Read(address uint16) uint8 {
-	var value byte
-
-	for _, hook := range b.readHooks {
-		if address >= hook.startAddress && address <= hook.endAddress {
-			value = hook.hookFunc(address)
-			if !hook.onlyProxy {
-				return value
-			}
-		}
+	value, handled := callReadHooks(b.readHooks, address)
+	if handled {
+		return value
 	}
 
 	switch {
 	case address < 0x2000:
-		bankNr, offset := b.chrBankMapper(address)
-		b.mu.RLock()
-		bank := &b.chrBanks[bankNr]
-		b.mu.RUnlock()
-		value = bank.data[offset]
+		value = b.readChr(address)
 
 	case address >= prgRAMStart && address <= prgRAMEnd && len(b.prgRAM) > 0:
 		offset := address - prgRAMStart
@@ -118,6 +114,10 @@ func (b *Base) Read(address uint16) uint8 {
 		b.mu.RUnlock()
 		value = bank.data[offset]
 
+		if b.bus.CodeDataLog != nil {
+			b.bus.CodeDataLog.LogPrgRead(bank.offset+int(offset), address)
+		}
+
 	default:
 		panic(fmt.Sprintf("invalid read from address #%0000x", address))
 	}
@@ -126,20 +126,13 @@ func (b *Base) Read(address uint16) uint8 {
 
 // Write a byte to a CHR or PRG memory address.
 func (b *Base) Write(address uint16, value uint8) {
-	for _, hook := range b.writeHooks {
-		if address >= hook.startAddress && address <= hook.endAddress {
-			hook.hookFunc(address, value)
-			if !hook.onlyProxy {
-				return
-			}
-		}
+	if callWriteHooks(b.writeHooks, address, value) {
+		return
 	}
 
 	switch {
 	case address < 0x2000 && len(b.chrRAM) > 0:
-		bankNr, offset := b.chrBankMapper(address)
-		bank := &b.chrBanks[bankNr]
-		bank.data[offset] = value
+		b.writeChr(address, value)
 
 	case address >= prgRAMStart && address <= prgRAMEnd && len(b.prgRAM) > 0:
 		offset := address - prgRAMStart
@@ -150,6 +143,38 @@ func (b *Base) Write(address uint16, value uint8) {
 	}
 }
 
+// ReadPPU reads a byte from a pattern table or nametable address of the PPU address space.
+// All PPU fetches pass through this function, which allows mappers to observe them.
+func (b *Base) ReadPPU(address uint16) uint8 {
+	value, handled := callReadHooks(b.ppuReadHooks, address)
+	if handled {
+		return value
+	}
+
+	if address < 0x2000 {
+		if b.bus.CodeDataLog != nil && len(b.bus.Cartridge.CHR) > 0 {
+			b.logChrRead(address)
+		}
+		return b.readChr(address)
+	}
+	return b.bus.NameTable.Read(address)
+}
+
+// WritePPU writes a byte to a pattern table or nametable address of the PPU address space.
+// Writes to CHR ROM are ignored.
+func (b *Base) WritePPU(address uint16, value uint8) {
+	if callWriteHooks(b.ppuWriteHooks, address, value) {
+		return
+	}
+
+	switch {
+	case address >= 0x2000:
+		b.bus.NameTable.Write(address, value)
+	case len(b.chrRAM) > 0:
+		b.writeChr(address, value)
+	}
+}
+
 // Initialize the mapper base with default settings.
 func (b *Base) Initialize() {
 	b.chrBankMapper = b.defaultChrBankMapper
@@ -165,6 +190,33 @@ func (b *Base) Cartridge() *cartridge.Cartridge {
 	return b.bus.Cartridge
 }
 
+// Disk returns the disk of the Famicom Disk System, it is nil for cartridges.
+func (b *Base) Disk() *fds.Disk {
+	return b.bus.Disk
+}
+
+func (b *Base) readChr(address uint16) uint8 {
+	bankNr, offset := b.chrBankMapper(address)
+	b.mu.RLock()
+	bank := &b.chrBanks[bankNr]
+	b.mu.RUnlock()
+	return bank.data[offset]
+}
+
+func (b *Base) logChrRead(address uint16) {
+	bankNr, offset := b.chrBankMapper(address)
+	b.mu.RLock()
+	bank := &b.chrBanks[bankNr]
+	b.mu.RUnlock()
+	b.bus.CodeDataLog.LogChrRead(bank.offset + int(offset))
+}
+
+func (b *Base) writeChr(address uint16, value uint8) {
+	bankNr, offset := b.chrBankMapper(address)
+	bank := &b.chrBanks[bankNr]
+	bank.data[offset] = value
+}
+
 func (b *Base) defaultChrBankMapper(address uint16) (int, uint16) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
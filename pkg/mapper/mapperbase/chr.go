@@ -31,6 +31,7 @@ func (b *Base) setChrBanks() {
 	for i := 0; i < len(b.chrBanks); i++ {
 		bank := &b.chrBanks[i]
 		endOffset := startOffset + bank.length
+		bank.offset = startOffset
 		bank.data = chr[startOffset:endOffset]
 		startOffset += bank.length
 	}
@@ -0,0 +1,48 @@
+package mapperbase
+
+// SetCycleHook sets a function that gets called for every executed CPU cycle.
+// This is used by mappers that implement CPU cycle based IRQ counters.
+func (b *Base) SetCycleHook(hookFunc func()) {
+	b.cycleHook = hookFunc
+}
+
+// IrqSource is a bit mask of the interrupt request sources of a mapper.
+type IrqSource uint8
+
+// Interrupt request sources, every source is acknowledged separately.
+const (
+	IrqCounter IrqSource = 1 << iota // CPU cycle or scanline counter
+	IrqDisk                          // disk transfer of the Famicom Disk System
+)
+
+// Step is called by the CPU for every executed CPU cycle. The IRQ line is
+// level triggered, the interrupt request is signaled to the CPU again as long
+// as a source is pending.
+func (b *Base) Step() {
+	if b.cycleHook != nil {
+		b.cycleHook()
+	}
+	if b.irqSources != 0 {
+		b.bus.CPU.TriggerIrq()
+	}
+}
+
+// TriggerIrq marks the source as pending and signals an interrupt request to the CPU.
+func (b *Base) TriggerIrq(source IrqSource) {
+	b.irqSources |= source
+	b.bus.CPU.TriggerIrq()
+}
+
+// AcknowledgeIrq clears the pending state of the source. The interrupt request
+// of the CPU is only cleared if no other source is pending.
+func (b *Base) AcknowledgeIrq(source IrqSource) {
+	b.irqSources &^= source
+	if b.irqSources == 0 {
+		b.bus.CPU.ClearIrq()
+	}
+}
+
+// IrqPending returns whether the source has a pending interrupt request.
+func (b *Base) IrqPending(source IrqSource) bool {
+	return b.irqSources&source != 0
+}
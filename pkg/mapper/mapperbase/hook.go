@@ -30,7 +30,7 @@ func (h *hook) SetProxyOnly(proxy bool) {
 
 // AddReadHook adds an address range read hook that gets called when a read from given range is made.
 func (b *Base) AddReadHook(startAddress, endAddress uint16, hookFunc func(address uint16) uint8) Hook {
-	hook := readHook{
+	hook := &readHook{
 		hook: hook{
 			startAddress: startAddress,
 			endAddress:   endAddress,
@@ -43,7 +43,7 @@ func (b *Base) AddReadHook(startAddress, endAddress uint16, hookFunc func(addres
 
 // AddWriteHook adds an address range write hook that gets called when a write into the given range is made.
 func (b *Base) AddWriteHook(startAddress, endAddress uint16, hookFunc func(address uint16, value uint8)) Hook {
-	hook := writeHook{
+	hook := &writeHook{
 		hook: hook{
 			startAddress: startAddress,
 			endAddress:   endAddress,
@@ -53,3 +53,60 @@ func (b *Base) AddWriteHook(startAddress, endAddress uint16, hookFunc func(addre
 	b.writeHooks = append(b.writeHooks, hook)
 	return &hook.hook
 }
+
+// AddPPUReadHook adds an address range read hook that gets called when the PPU reads from the given
+// range of the pattern tables or nametables. This allows mappers to observe the PPU fetches.
+func (b *Base) AddPPUReadHook(startAddress, endAddress uint16, hookFunc func(address uint16) uint8) Hook {
+	hook := &readHook{
+		hook: hook{
+			startAddress: startAddress,
+			endAddress:   endAddress,
+		},
+		hookFunc: hookFunc,
+	}
+	b.ppuReadHooks = append(b.ppuReadHooks, hook)
+	return &hook.hook
+}
+
+// AddPPUWriteHook adds an address range write hook that gets called when the PPU writes into the given
+// range of the pattern tables or nametables.
+func (b *Base) AddPPUWriteHook(startAddress, endAddress uint16, hookFunc func(address uint16, value uint8)) Hook {
+	hook := &writeHook{
+		hook: hook{
+			startAddress: startAddress,
+			endAddress:   endAddress,
+		},
+		hookFunc: hookFunc,
+	}
+	b.ppuWriteHooks = append(b.ppuWriteHooks, hook)
+	return &hook.hook
+}
+
+// callReadHooks calls all hooks that match the address. It returns the value of the first
+// hook that is not a proxy and whether such a hook handled the read.
+func callReadHooks(hooks []*readHook, address uint16) (uint8, bool) {
+	var value uint8
+	for _, hook := range hooks {
+		if address >= hook.startAddress && address <= hook.endAddress {
+			value = hook.hookFunc(address)
+			if !hook.onlyProxy {
+				return value, true
+			}
+		}
+	}
+	return value, false
+}
+
+// callWriteHooks calls all hooks that match the address and returns whether a hook
+// that is not a proxy handled the write.
+func callWriteHooks(hooks []*writeHook, address uint16, value uint8) bool {
+	for _, hook := range hooks {
+		if address >= hook.startAddress && address <= hook.endAddress {
+			hook.hookFunc(address, value)
+			if !hook.onlyProxy {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -23,6 +23,7 @@ func (b *Base) setPrgBanks() {
 	for i := 0; i < len(b.prgBanks); i++ {
 		bank := &b.prgBanks[i]
 		endOffset := startOffset + bank.length
+		bank.offset = startOffset
 		bank.data = prg[startOffset:endOffset]
 		startOffset += bank.length
 	}
@@ -54,3 +55,28 @@ func (b *Base) SetPrgWindowSize(size int) {
 func (b *Base) SetPrgRAM(ram []byte) {
 	b.prgRAM = ram
 }
+
+// PrgRAM returns the PRG RAM buffer, it is empty if the mapper has no PRG RAM.
+func (b *Base) PrgRAM() []byte {
+	return b.prgRAM
+}
+
+// SetBatteryRAMSize sets the size of the part of the PRG RAM that is battery
+// backed on cartridges with a battery. This is used by mappers that can bank
+// more PRG RAM into the $6000-$7FFF window.
+func (b *Base) SetBatteryRAMSize(size int) {
+	b.batteryRAMSize = size
+}
+
+// BatteryRAM returns the part of the PRG RAM that is battery backed on
+// cartridges with a battery, by default the RAM of the $6000-$7FFF window.
+func (b *Base) BatteryRAM() []byte {
+	size := b.batteryRAMSize
+	if size == 0 {
+		size = prgRAMEnd - prgRAMStart + 1
+	}
+	if size > len(b.prgRAM) {
+		size = len(b.prgRAM)
+	}
+	return b.prgRAM[:size]
+}
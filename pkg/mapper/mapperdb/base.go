@@ -3,6 +3,7 @@ package mapperdb
 
 import (
 	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/fds"
 	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 )
@@ -18,6 +19,7 @@ type Base interface {
 
 	PrgBankCount() int
 	SetPrgRAM(ram []byte)
+	SetBatteryRAMSize(size int)
 	SetPrgWindow(window, bank int)
 	SetPrgWindowSize(size int)
 
@@ -30,7 +32,16 @@ type Base interface {
 
 	AddReadHook(startAddress, endAddress uint16, hookFunc func(address uint16) uint8) mapperbase.Hook
 	AddWriteHook(startAddress, endAddress uint16, hookFunc func(address uint16, value uint8)) mapperbase.Hook
+	AddPPUReadHook(startAddress, endAddress uint16, hookFunc func(address uint16) uint8) mapperbase.Hook
+	AddPPUWriteHook(startAddress, endAddress uint16, hookFunc func(address uint16, value uint8)) mapperbase.Hook
+	SetCycleHook(hookFunc func())
+
+	AcknowledgeIrq(source mapperbase.IrqSource)
+	IrqPending(source mapperbase.IrqSource) bool
+	TriggerIrq(source mapperbase.IrqSource)
+
 	Cartridge() *cartridge.Cartridge
+	Disk() *fds.Disk
 	Initialize()
 	SetName(name string)
 }
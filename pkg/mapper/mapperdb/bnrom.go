@@ -0,0 +1,82 @@
+package mapperdb
+
+/*
+BNROM
+PRG ROM capacity: 128K (8M in homebrew)
+PRG ROM window: 32K
+CHR RAM: 8K
+
+NINA-001
+PRG ROM capacity: 64K
+PRG ROM window: 32K
+PRG RAM: 8K
+CHR capacity: 64K
+CHR window: 4K + 4K
+
+Both boards share mapper number 34, a cartridge with CHR RAM is treated as BNROM.
+*/
+
+import "github.com/retroenv/nesgo/pkg/bus"
+
+type mapperBNROM struct {
+	Base
+}
+
+// NewMapper34 returns a new BNROM or NINA-001 mapper instance, depending on whether
+// the cartridge uses CHR RAM.
+func NewMapper34(base Base) bus.Mapper {
+	if len(base.Cartridge().CHR) == 0 {
+		return NewBNROM(base)
+	}
+	return NewNINA001(base)
+}
+
+// NewBNROM returns a new mapper instance.
+func NewBNROM(base Base) bus.Mapper {
+	m := &mapperBNROM{
+		Base: base,
+	}
+	m.SetName("BNROM")
+	m.SetPrgWindowSize(0x8000)        // 32K
+	m.SetChrRAM(make([]byte, 0x2000)) // 8K
+	m.Initialize()
+
+	m.AddWriteHook(0x8000, 0xFFFF, m.setPrgWindow)
+	return m
+}
+
+func (m *mapperBNROM) setPrgWindow(address uint16, value uint8) {
+	m.SetPrgWindow(0, int(value)) // select 32 KB PRG ROM bank for CPU $8000-$FFFF
+}
+
+type mapperNINA001 struct {
+	Base
+}
+
+// NewNINA001 returns a new mapper instance.
+func NewNINA001(base Base) bus.Mapper {
+	m := &mapperNINA001{
+		Base: base,
+	}
+	m.SetName("NINA-001")
+	m.SetPrgWindowSize(0x8000)        // 32K
+	m.SetChrWindowSize(0x1000)        // 4K
+	m.SetPrgRAM(make([]byte, 0x2000)) // 8K
+	m.Initialize()
+
+	// the registers overlay the PRG RAM, written values are also stored in the RAM
+	hook := m.AddWriteHook(0x7FFD, 0x7FFF, m.setBanks)
+	hook.SetProxyOnly(true)
+	return m
+}
+
+func (m *mapperNINA001) setBanks(address uint16, value uint8) {
+	switch address {
+	case 0x7FFD:
+		m.SetPrgWindow(0, int(value&1)) // select 32 KB PRG ROM bank for CPU $8000-$FFFF
+	case 0x7FFE:
+		m.SetChrWindow(0, int(value&0b0000_1111)) // select 4 KB CHR ROM bank for PPU $0000-$0FFF
+	case 0x7FFF:
+		m.SetChrWindow(1, int(value&0b0000_1111)) // select 4 KB CHR ROM bank for PPU $1000-$1FFF
+	}
+}
@@ -0,0 +1,58 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperBNROM(t *testing.T) {
+	prg := make([]byte, 0x8000*4) // 32K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMapper34(base)
+	assert.Equal(t, "BNROM", m.State().Name)
+
+	prg[0x18010] = 0x01 // bank 3
+	m.Write(0x8000, 3)
+	assert.Equal(t, 0x01, m.Read(0x8010))
+
+	m.Write(0x0010, 0x02) // CHR RAM
+	assert.Equal(t, 0x02, m.Read(0x0010))
+}
+
+func TestMapperNINA001(t *testing.T) {
+	chr := make([]byte, 0x1000*4) // 4K banks
+	prg := make([]byte, 0x8000*2) // 32K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMapper34(base)
+	assert.Equal(t, "NINA-001", m.State().Name)
+
+	chr[0x2010] = 0x01 // bank 2
+	chr[0x3010] = 0x02 // bank 3
+	prg[0x8010] = 0x03 // bank 1
+
+	m.Write(0x7FFD, 1)
+	m.Write(0x7FFE, 3)
+	m.Write(0x7FFF, 2)
+	assert.Equal(t, 0x02, m.Read(0x0010))
+	assert.Equal(t, 0x01, m.Read(0x1010))
+	assert.Equal(t, 0x03, m.Read(0x8010))
+	assert.Equal(t, 0x02, m.Read(0x7FFF)) // registers are backed by PRG RAM
+}
@@ -0,0 +1,32 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperColorDreams(t *testing.T) {
+	chr := make([]byte, 0x2000*16) // 8K banks
+	prg := make([]byte, 0x8000*4)  // 32K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewColorDreams(base)
+
+	chr[0x1A010] = 0x01 // bank 13
+	prg[0x10010] = 0x02 // bank 2
+
+	m.Write(0x8000, 13<<4|2) // select CHR bank 13 and PRG bank 2
+	assert.Equal(t, 0x01, m.Read(0x0010))
+	assert.Equal(t, 0x02, m.Read(0x8010))
+}
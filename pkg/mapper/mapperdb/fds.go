@@ -0,0 +1,221 @@
+package mapperdb
+
+/*
+Boards: Famicom Disk System RAM adapter
+PRG ROM: 8K BIOS at $E000-$FFFF
+PRG RAM: 32K at $6000-$DFFF
+CHR RAM: 8K
+Disk drive: single drive with switchable disk sides
+
+The cartridge contains the BIOS as PRG ROM, the disk is passed separately using the bus.
+Not supported are the CRC verification of read blocks and the expansion audio, the
+wavetable RAM is accessible but not played.
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/fds"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const (
+	fdsRAMStart     = 0x6000
+	fdsRAMEnd       = 0xDFFF
+	fdsWaveRAMStart = 0x4040
+	fdsWaveRAMEnd   = 0x407F
+)
+
+type mapperFDS struct {
+	*fdsDrive
+	Base
+
+	ram     []byte
+	waveRAM []byte
+
+	diskRegEnabled  bool
+	soundRegEnabled bool
+
+	timerReload  uint16
+	timerCounter uint16
+	timerRepeat  bool
+	timerEnabled bool
+}
+
+// NewFDS returns a new mapper instance for the Famicom Disk System.
+func NewFDS(base Base) bus.Mapper {
+	m := &mapperFDS{
+		Base:     base,
+		fdsDrive: newFDSDrive(base.Disk()),
+		ram:      make([]byte, fdsRAMEnd-fdsRAMStart+1), // 32K
+		waveRAM:  make([]byte, fdsWaveRAMEnd-fdsWaveRAMStart+1),
+	}
+	m.SetName("FDS")
+	m.SetPrgWindowSize(prgBankSize8K)
+	m.SetPrgRAM(m.ram)
+	m.SetChrRAM(make([]byte, 0x2000)) // 8K
+	m.Initialize()
+
+	m.AddReadHook(0x4020, 0x5FFF, m.readRegister)
+	m.AddWriteHook(0x4020, 0x5FFF, m.writeRegister)
+	m.AddReadHook(fdsRAMStart, fdsRAMEnd, m.readRAM)
+	m.AddWriteHook(fdsRAMStart, fdsRAMEnd, m.writeRAM)
+	m.AddWriteHook(0xE000, 0xFFFF, func(address uint16, value uint8) {}) // BIOS ROM
+	m.SetCycleHook(m.step)
+
+	return m
+}
+
+// interface guard
+var _ fds.Drive = &mapperFDS{}
+
+func (m *mapperFDS) readRAM(address uint16) uint8 {
+	return m.ram[address-fdsRAMStart]
+}
+
+func (m *mapperFDS) writeRAM(address uint16, value uint8) {
+	m.ram[address-fdsRAMStart] = value
+}
+
+func (m *mapperFDS) readRegister(address uint16) uint8 {
+	if !m.diskRegEnabled && address >= 0x4030 && address <= 0x4033 {
+		return 0 // TODO should return open bus value
+	}
+
+	switch {
+	case address == 0x4030:
+		return m.readStatus()
+
+	case address == 0x4031:
+		m.transferComplete = false
+		m.clearIrq(false, true)
+		return m.readData
+
+	case address == 0x4032:
+		return m.status()
+
+	case address == 0x4033:
+		return 0b1000_0000 // battery good
+
+	case address >= fdsWaveRAMStart && address <= fdsWaveRAMEnd:
+		return m.waveRAM[address-fdsWaveRAMStart]
+
+	default:
+		return 0 // TODO should return open bus value
+	}
+}
+
+func (m *mapperFDS) writeRegister(address uint16, value uint8) {
+	if !m.diskRegEnabled && address >= 0x4024 && address <= 0x4026 {
+		return
+	}
+
+	switch {
+	case address == 0x4020:
+		m.timerReload = m.timerReload&0xFF00 | uint16(value)
+
+	case address == 0x4021:
+		m.timerReload = m.timerReload&0x00FF | uint16(value)<<8
+
+	case address == 0x4022:
+		m.setTimerControl(value)
+
+	case address == 0x4023:
+		m.setIOEnable(value)
+
+	case address == 0x4024:
+		m.writeData = value
+		m.transferComplete = false
+		m.clearIrq(false, true)
+
+	case address == 0x4025:
+		m.setControl(value)
+		m.clearIrq(false, true)
+		if value&0b0000_1000 != 0 {
+			m.SetNameTableMirrorMode(cartridge.MirrorHorizontal)
+		} else {
+			m.SetNameTableMirrorMode(cartridge.MirrorVertical)
+		}
+
+	case address >= fdsWaveRAMStart && address <= fdsWaveRAMEnd:
+		if m.soundRegEnabled {
+			m.waveRAM[address-fdsWaveRAMStart] = value
+		}
+	}
+}
+
+// readStatus returns the disk status register and acknowledges all IRQs.
+func (m *mapperFDS) readStatus() uint8 {
+	var value uint8
+	if m.IrqPending(mapperbase.IrqCounter) {
+		value |= 0b0000_0001
+	}
+	if m.transferComplete {
+		value |= 0b0000_0010
+	}
+	if m.endOfHead {
+		value |= 0b0100_0000
+	}
+
+	m.transferComplete = false
+	m.clearIrq(true, true)
+	return value
+}
+
+func (m *mapperFDS) setTimerControl(value uint8) {
+	m.timerRepeat = value&0b0000_0001 != 0
+	m.timerEnabled = value&0b0000_0010 != 0 && m.diskRegEnabled
+	if m.timerEnabled {
+		m.timerCounter = m.timerReload
+	}
+	m.clearIrq(true, false)
+}
+
+func (m *mapperFDS) setIOEnable(value uint8) {
+	m.diskRegEnabled = value&0b0000_0001 != 0
+	m.soundRegEnabled = value&0b0000_0010 != 0
+
+	if !m.diskRegEnabled {
+		m.timerEnabled = false
+		m.clearIrq(true, true)
+	}
+}
+
+// step is called every CPU cycle and clocks the timer IRQ counter and the disk drive.
+func (m *mapperFDS) step() {
+	m.stepTimer()
+
+	if m.fdsDrive.step() {
+		m.TriggerIrq(mapperbase.IrqDisk)
+	}
+}
+
+// stepTimer decrements the timer IRQ counter, an IRQ is triggered when the counter
+// reaches zero, in repeat mode the counter gets reloaded and keeps running.
+func (m *mapperFDS) stepTimer() {
+	if !m.timerEnabled {
+		return
+	}
+
+	if m.timerCounter > 0 {
+		m.timerCounter--
+		return
+	}
+
+	m.timerCounter = m.timerReload
+	if !m.timerRepeat {
+		m.timerEnabled = false
+	}
+	m.TriggerIrq(mapperbase.IrqCounter)
+}
+
+// clearIrq acknowledges the timer and/or disk IRQ, the CPU IRQ line stays active
+// as long as one of the sources is pending.
+func (m *mapperFDS) clearIrq(timer, disk bool) {
+	if timer {
+		m.AcknowledgeIrq(mapperbase.IrqCounter)
+	}
+	if disk {
+		m.AcknowledgeIrq(mapperbase.IrqDisk)
+	}
+}
@@ -0,0 +1,165 @@
+package mapperdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/fds"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func newTestFDS(t *testing.T, cpu *testCPU) *mapperFDS {
+	t.Helper()
+
+	// 2 disk sides that only contain the disk info block
+	data := make([]byte, 2*fds.SideSize)
+	for offset := 0; offset < len(data); offset += fds.SideSize {
+		data[offset] = 0x01
+		copy(data[offset+1:], "*NINTENDO-HVC*")
+	}
+	disk, err := fds.Load(data)
+	assert.NoError(t, err)
+
+	bios := make([]byte, fds.BIOSSize)
+	bios[0] = 0xBE
+	cart, err := fds.NewCartridge(bios)
+	assert.NoError(t, err)
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: cart,
+		CPU:       cpu,
+		Disk:      disk,
+		NameTable: nametable.New(cart.Mirror),
+	})
+	return NewFDS(base).(*mapperFDS)
+}
+
+func TestMapperFDSMemory(t *testing.T) {
+	m := newTestFDS(t, &testCPU{})
+
+	assert.Equal(t, 0xBE, m.Read(0xE000))
+	m.Write(0xE000, 0x12)
+	assert.Equal(t, 0xBE, m.Read(0xE000))
+
+	m.Write(0x6000, 0x01)
+	m.Write(0xDFFF, 0x02)
+	assert.Equal(t, 0x01, m.Read(0x6000))
+	assert.Equal(t, 0x02, m.Read(0xDFFF))
+
+	m.WritePPU(0x1FFF, 0x03)
+	assert.Equal(t, 0x03, m.ReadPPU(0x1FFF))
+
+	m.Write(0x4023, 0b0000_0001) // enable disk registers
+	m.Write(0x4025, 0b0000_0000)
+	assert.Equal(t, cartridge.MirrorVertical, m.MirrorMode())
+	m.Write(0x4025, 0b0000_1000)
+	assert.Equal(t, cartridge.MirrorHorizontal, m.MirrorMode())
+	assert.Equal(t, 0x80, m.Read(0x4033))
+}
+
+func TestMapperFDSTimerIrq(t *testing.T) {
+	cpu := &testCPU{}
+	m := newTestFDS(t, cpu)
+
+	m.Write(0x4023, 0b0000_0001) // enable disk registers
+	m.Write(0x4020, 2)
+	m.Write(0x4021, 0)
+	m.Write(0x4022, 0b0000_0010) // enable timer without repeat
+
+	m.Step()
+	m.Step()
+	assert.False(t, cpu.irq)
+	m.Step()
+	assert.True(t, cpu.irq)
+
+	assert.Equal(t, 0b0000_0001, m.Read(0x4030)&0b0000_0001)
+	assert.False(t, cpu.irq)
+	assert.Equal(t, 0, m.Read(0x4030)&0b0000_0001)
+
+	for i := 0; i < 10; i++ {
+		m.Step()
+	}
+	assert.False(t, cpu.irq)
+}
+
+func TestMapperFDSIrqSources(t *testing.T) {
+	cpu := &testCPU{}
+	m := newTestFDS(t, cpu)
+
+	m.Write(0x4023, 0b0000_0001) // enable disk registers
+	m.Write(0x4020, 0)
+	m.Write(0x4021, 0)
+	m.Write(0x4022, 0b0000_0010) // enable timer without repeat
+	m.Step()
+	assert.True(t, cpu.irq)
+
+	m.Write(0x4025, 0b1110_0101) // IRQ, start transfer, read mode, motor on
+	for i := 0; i < 1_000_000 && !m.IrqPending(mapperbase.IrqDisk); i++ {
+		m.Step()
+	}
+	assert.True(t, m.IrqPending(mapperbase.IrqDisk))
+
+	// reading the data acknowledges only the disk IRQ
+	m.Read(0x4031)
+	assert.False(t, m.IrqPending(mapperbase.IrqDisk))
+	assert.True(t, cpu.irq)
+
+	// the IRQ line stays asserted after the CPU executed the IRQ
+	cpu.irq = false
+	m.Step()
+	assert.True(t, cpu.irq)
+
+	assert.Equal(t, 0b0000_0001, m.Read(0x4030)&0b0000_0001)
+	assert.False(t, cpu.irq)
+}
+
+func TestMapperFDSDiskRead(t *testing.T) {
+	cpu := &testCPU{}
+	m := newTestFDS(t, cpu)
+
+	m.Write(0x4023, 0b0000_0001) // enable disk registers
+	m.Write(0x4025, 0b1110_0101) // IRQ, start transfer, read mode, motor on
+	assert.Equal(t, 0b0000_0010, m.Read(0x4032)&0b0000_0111)
+
+	expected := []byte("\x01*NINTENDO-HVC*")
+	for _, value := range expected {
+		for i := 0; i < 1_000_000 && !cpu.irq; i++ {
+			m.Step()
+		}
+		assert.True(t, cpu.irq)
+		assert.Equal(t, value, m.Read(0x4031))
+		assert.False(t, cpu.irq)
+	}
+	assert.Equal(t, 0, m.Read(0x4032)&0b0000_0111)
+}
+
+func TestMapperFDSSwitchSide(t *testing.T) {
+	m := newTestFDS(t, &testCPU{})
+	assert.Equal(t, 2, m.Sides())
+	assert.Equal(t, 0, m.InsertedSide())
+
+	err := m.InsertSide(2)
+	assert.True(t, errors.Is(err, fds.ErrInvalidSide))
+
+	assert.NoError(t, m.InsertSide(1))
+	m.Step()
+	assert.Equal(t, -1, m.InsertedSide())
+	m.Write(0x4023, 0b0000_0001) // enable disk registers
+	assert.Equal(t, 0b0000_0111, m.Read(0x4032)&0b0000_0111)
+
+	for i := 0; i < fdsInsertCycles; i++ {
+		m.Step()
+	}
+	assert.Equal(t, 1, m.InsertedSide())
+
+	m.Eject()
+	m.Step()
+	assert.Equal(t, -1, m.InsertedSide())
+	assert.NoError(t, m.InsertSide(0))
+	m.Step()
+	assert.Equal(t, 0, m.InsertedSide())
+}
@@ -0,0 +1,240 @@
+package mapperdb
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/retroenv/nesgo/pkg/fds"
+)
+
+const (
+	fdsByteCycles       = 149    // CPU cycles that the drive needs to transfer one byte
+	fdsHeadReturnCycles = 50000  // CPU cycles that the head needs to return to the start of the disk
+	fdsInsertCycles     = 900000 // CPU cycles between ejecting and inserting a disk when switching sides
+
+	fdsNoDisk    = -1 // side value when no disk is inserted
+	fdsNoRequest = -2 // side request value when no change of the disk is requested
+)
+
+// fdsDrive implements the disk drive of the Famicom Disk System. The disk is read and
+// written byte wise, moving the head over the whole side once the motor is turned on.
+type fdsDrive struct {
+	disk *fds.Disk
+
+	side          int32 // inserted side, accessed atomically
+	requestedSide int32 // side change requested by the user, accessed atomically
+	pendingSide   int   // side to insert after the insert delay
+	insertDelay   int
+
+	// $4025 control register
+	motorOn         bool
+	resetTransfer   bool
+	readMode        bool
+	crcControl      bool
+	transferStarted bool
+	irqEnabled      bool
+
+	scanning           bool
+	endOfHead          bool
+	gapEnded           bool
+	previousCrcControl bool
+	position           int
+	delay              int
+
+	readData         uint8
+	writeData        uint8
+	transferComplete bool
+}
+
+func newFDSDrive(disk *fds.Disk) *fdsDrive {
+	d := &fdsDrive{
+		disk:          disk,
+		side:          fdsNoDisk,
+		requestedSide: fdsNoRequest,
+		pendingSide:   fdsNoDisk,
+		endOfHead:     true,
+	}
+	if d.Sides() > 0 {
+		d.side = 0
+	}
+	return d
+}
+
+// Sides returns the amount of disk sides.
+func (d *fdsDrive) Sides() int {
+	if d.disk == nil {
+		return 0
+	}
+	return len(d.disk.Sides)
+}
+
+// InsertedSide returns the index of the inserted side, or -1 if no disk is inserted.
+func (d *fdsDrive) InsertedSide() int {
+	return int(atomic.LoadInt32(&d.side))
+}
+
+// InsertSide requests the side to be inserted, the request is processed by the
+// emulation goroutine.
+func (d *fdsDrive) InsertSide(side int) error {
+	if side < 0 || side >= d.Sides() {
+		return fmt.Errorf("%w: %d", fds.ErrInvalidSide, side)
+	}
+	atomic.StoreInt32(&d.requestedSide, int32(side))
+	return nil
+}
+
+// Eject requests the disk to be ejected, the request is processed by the
+// emulation goroutine.
+func (d *fdsDrive) Eject() {
+	atomic.StoreInt32(&d.requestedSide, fdsNoDisk)
+}
+
+func (d *fdsDrive) setControl(value uint8) {
+	d.motorOn = value&0b0000_0001 != 0
+	d.resetTransfer = value&0b0000_0010 != 0
+	d.readMode = value&0b0000_0100 != 0
+	d.crcControl = value&0b0001_0000 != 0
+	d.transferStarted = value&0b0100_0000 != 0
+	d.irqEnabled = value&0b1000_0000 != 0
+}
+
+// status returns the drive status bits of the $4032 register.
+func (d *fdsDrive) status() uint8 {
+	inserted := d.InsertedSide() != fdsNoDisk
+
+	var value uint8
+	if !inserted {
+		value |= 0b0000_0101 // disk not inserted and write protected
+	}
+	if !inserted || !d.scanning {
+		value |= 0b0000_0010 // disk not ready
+	}
+	return value
+}
+
+// step advances the drive by one CPU cycle and returns whether a byte transfer IRQ
+// should be triggered.
+func (d *fdsDrive) step() bool {
+	d.processSideRequest()
+
+	side := d.InsertedSide()
+	if side == fdsNoDisk || !d.motorOn {
+		d.endOfHead = true
+		d.scanning = false
+		return false
+	}
+	if d.resetTransfer && !d.scanning {
+		return false
+	}
+
+	if d.endOfHead {
+		d.delay = fdsHeadReturnCycles
+		d.endOfHead = false
+		d.position = 0
+		d.gapEnded = false
+		return false
+	}
+	if d.delay > 0 {
+		d.delay--
+		return false
+	}
+
+	d.scanning = true
+	data := d.disk.Sides[side]
+
+	var irq bool
+	if d.readMode {
+		irq = d.readByte(data)
+	} else {
+		irq = d.writeByte(data)
+	}
+	d.previousCrcControl = d.crcControl
+
+	d.position++
+	if d.position >= len(data) {
+		d.motorOn = false
+		return d.irqEnabled
+	}
+	d.delay = fdsByteCycles
+	return irq
+}
+
+// readByte reads the byte under the head. The gap before a block is skipped until
+// the block start mark is found, which does not trigger an IRQ.
+func (d *fdsDrive) readByte(data []byte) bool {
+	value := data[d.position]
+	irq := d.irqEnabled
+
+	switch {
+	case !d.transferStarted:
+		d.gapEnded = false
+	case value != 0 && !d.gapEnded:
+		d.gapEnded = true
+		irq = false
+	}
+
+	if !d.gapEnded {
+		return false
+	}
+	d.transferComplete = true
+	d.readData = value
+	return irq
+}
+
+// writeByte writes the data register to the disk. While the CRC control bit is set,
+// the checksum bytes are written, which are not verified by this emulation.
+func (d *fdsDrive) writeByte(data []byte) bool {
+	var irq bool
+	if !d.crcControl {
+		d.transferComplete = true
+		irq = d.irqEnabled
+	}
+
+	value := d.writeData
+	switch {
+	case !d.transferStarted:
+		value = 0
+	case d.crcControl && !d.previousCrcControl:
+		value = 0x4D
+	case d.crcControl:
+		value = 0x62
+	}
+
+	// the written byte lags behind the head position
+	if d.position >= 2 {
+		data[d.position-2] = value
+	}
+	d.gapEnded = false
+	return irq
+}
+
+// processSideRequest ejects or inserts the disk side requested by the user. When a disk
+// is inserted while another one is still in the drive, the drive stays empty for a while
+// to allow the BIOS to detect the disk change.
+func (d *fdsDrive) processSideRequest() {
+	if d.insertDelay > 0 {
+		d.insertDelay--
+		if d.insertDelay == 0 {
+			atomic.StoreInt32(&d.side, int32(d.pendingSide))
+		}
+	}
+
+	requested := int(atomic.SwapInt32(&d.requestedSide, fdsNoRequest))
+	if requested == fdsNoRequest {
+		return
+	}
+
+	switch {
+	case requested == fdsNoDisk:
+		d.insertDelay = 0
+		atomic.StoreInt32(&d.side, fdsNoDisk)
+
+	case d.InsertedSide() == fdsNoDisk && d.insertDelay == 0:
+		atomic.StoreInt32(&d.side, int32(requested))
+
+	default:
+		atomic.StoreInt32(&d.side, fdsNoDisk)
+		d.pendingSide = requested
+		d.insertDelay = fdsInsertCycles
+	}
+}
@@ -0,0 +1,133 @@
+package mapperdb
+
+/*
+Boards: Sunsoft FME-7, 5A, 5B
+PRG ROM capacity: 512K
+PRG ROM window: 8K + 8K + 8K + 8K fixed
+PRG RAM: 8K, can be replaced by a PRG ROM bank
+CHR capacity: 256K
+CHR window: 1K
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const prgBankSize8K = 0x2000
+
+type mapperFME7 struct {
+	Base
+
+	ram []byte
+
+	command byte
+
+	// $6000-$7FFF bank configuration
+	prgBank0   int
+	ramSelect  bool
+	ramEnabled bool
+
+	irqEnabled     bool
+	counterEnabled bool
+	counter        uint16
+}
+
+// NewFME7 returns a new mapper instance.
+func NewFME7(base Base) bus.Mapper {
+	m := &mapperFME7{
+		Base: base,
+		ram:  make([]byte, 0x2000), // 8K
+	}
+	m.SetName("Sunsoft FME-7")
+	m.SetPrgWindowSize(prgBankSize8K)
+	m.SetChrWindowSize(0x0400) // 1K
+	m.SetPrgRAM(m.ram)
+	m.Initialize()
+
+	m.AddReadHook(0x6000, 0x7FFF, m.readBank0)
+	m.AddWriteHook(0x6000, 0x7FFF, m.writeBank0)
+	m.AddWriteHook(0x8000, 0x9FFF, m.setCommand)
+	m.AddWriteHook(0xA000, 0xBFFF, m.setParameter)
+	m.AddWriteHook(0xC000, 0xFFFF, func(address uint16, value uint8) {}) // audio registers
+	m.SetCycleHook(m.step)
+
+	translation := mapperbase.MirrorModeTranslation{
+		0: cartridge.MirrorVertical,
+		1: cartridge.MirrorHorizontal,
+		2: cartridge.MirrorSingle0,
+		3: cartridge.MirrorSingle1,
+	}
+	m.SetMirrorModeTranslation(translation)
+
+	m.SetPrgWindow(3, -1) // $E000-$FFFF: 8 KB PRG ROM bank, fixed to the last bank
+	return m
+}
+
+// readBank0 reads from the $6000-$7FFF bank which can map either PRG RAM or PRG ROM.
+func (m *mapperFME7) readBank0(address uint16) uint8 {
+	offset := int(address - 0x6000)
+	if m.ramSelect {
+		if !m.ramEnabled {
+			return 0 // TODO should return open bus value
+		}
+		return m.ram[offset]
+	}
+
+	prg := m.Cartridge().PRG
+	banks := len(prg) / prgBankSize8K
+	return prg[(m.prgBank0%banks)*prgBankSize8K+offset]
+}
+
+func (m *mapperFME7) writeBank0(address uint16, value uint8) {
+	if m.ramSelect && m.ramEnabled {
+		m.ram[address-0x6000] = value
+	}
+}
+
+func (m *mapperFME7) setCommand(address uint16, value uint8) {
+	m.command = value & 0b0000_1111
+}
+
+func (m *mapperFME7) setParameter(address uint16, value uint8) {
+	switch command := m.command; {
+	case command < 8: // CHR bank 0-7
+		m.SetChrWindow(int(command), int(value))
+
+	case command == 8: // PRG bank 0 at $6000-$7FFF
+		m.prgBank0 = int(value & 0b0011_1111)
+		m.ramSelect = value&0b0100_0000 != 0
+		m.ramEnabled = value&0b1000_0000 != 0
+
+	case command < 0x0C: // PRG bank 1-3 at $8000-$DFFF
+		m.SetPrgWindow(int(command-9), int(value&0b0011_1111))
+
+	case command == 0x0C:
+		m.SetNameTableMirrorModeIndex(value & 0b0000_0011)
+
+	case command == 0x0D: // IRQ control
+		m.irqEnabled = value&0b0000_0001 != 0
+		m.counterEnabled = value&0b1000_0000 != 0
+		m.AcknowledgeIrq(mapperbase.IrqCounter)
+
+	case command == 0x0E:
+		m.counter = m.counter&0xFF00 | uint16(value)
+
+	case command == 0x0F:
+		m.counter = m.counter&0x00FF | uint16(value)<<8
+	}
+}
+
+// step decrements the IRQ counter every CPU cycle, an IRQ is triggered when the
+// counter wraps around from $0000 to $FFFF.
+func (m *mapperFME7) step() {
+	if !m.counterEnabled {
+		return
+	}
+
+	m.counter--
+	if m.counter == 0xFFFF && m.irqEnabled {
+		m.TriggerIrq(mapperbase.IrqCounter)
+	}
+}
@@ -0,0 +1,70 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperFME7(t *testing.T) {
+	chr := make([]byte, 0x0400*16) // 1K banks
+	prg := make([]byte, 0x2000*8)  // 8K banks
+
+	cpu := &testCPU{}
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		CPU:       cpu,
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewFME7(base)
+
+	for bank := 0; bank < 8; bank++ {
+		prg[bank*0x2000] = byte(bank)
+	}
+	chr[0x0400*9+0x10] = 0x01 // bank 9
+
+	m.Write(0x8000, 0x03) // CHR bank 3
+	m.Write(0xA000, 9)
+	assert.Equal(t, 0x01, m.Read(0x0C10))
+
+	m.Write(0x8000, 0x0A) // PRG bank at $A000
+	m.Write(0xA000, 5)
+	assert.Equal(t, 5, m.Read(0xA000))
+	assert.Equal(t, 7, m.Read(0xE000))
+
+	m.Write(0x8000, 0x08) // PRG ROM bank 2 at $6000
+	m.Write(0xA000, 2)
+	assert.Equal(t, 2, m.Read(0x6000))
+	m.Write(0x6000, 0x10)
+	assert.Equal(t, 2, m.Read(0x6000))
+
+	m.Write(0xA000, 0b1100_0000) // enabled PRG RAM at $6000
+	m.Write(0x6000, 0x10)
+	assert.Equal(t, 0x10, m.Read(0x6000))
+
+	m.Write(0x8000, 0x0C)
+	m.Write(0xA000, 1)
+	assert.Equal(t, cartridge.MirrorHorizontal, m.MirrorMode())
+
+	m.Write(0x8000, 0x0E) // IRQ counter low
+	m.Write(0xA000, 1)
+	m.Write(0x8000, 0x0F) // IRQ counter high
+	m.Write(0xA000, 0)
+	m.Write(0x8000, 0x0D) // IRQ control
+	m.Write(0xA000, 0b1000_0001)
+
+	m.Step()
+	assert.False(t, cpu.irq)
+	m.Step() // counter wraps from $0000 to $FFFF
+	assert.True(t, cpu.irq)
+
+	m.Write(0xA000, 0) // acknowledge
+	assert.False(t, cpu.irq)
+}
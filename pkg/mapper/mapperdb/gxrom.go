@@ -0,0 +1,36 @@
+package mapperdb
+
+/*
+Boards: GNROM, MHROM
+PRG ROM capacity: 128K
+PRG ROM window: 32K
+CHR capacity: 32K
+CHR window: 8K
+*/
+
+import "github.com/retroenv/nesgo/pkg/bus"
+
+type mapperGxROM struct {
+	Base
+}
+
+// NewGxROM returns a new mapper instance.
+func NewGxROM(base Base) bus.Mapper {
+	m := &mapperGxROM{
+		Base: base,
+	}
+	m.SetName("GxROM")
+	m.SetPrgWindowSize(0x8000) // 32K
+	m.Initialize()
+
+	m.AddWriteHook(0x8000, 0xFFFF, m.setBanks)
+	return m
+}
+
+func (m *mapperGxROM) setBanks(address uint16, value uint8) {
+	prgBank := (value >> 4) & 0b0000_0011
+	m.SetPrgWindow(0, int(prgBank)) // select 32 KB PRG ROM bank for CPU $8000-$FFFF
+
+	chrBank := value & 0b0000_0011
+	m.SetChrWindow(0, int(chrBank)) // select 8 KB CHR ROM bank for PPU $0000-$1FFF
+}
@@ -0,0 +1,32 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperGxROM(t *testing.T) {
+	chr := make([]byte, 0x2000*4) // 8K banks
+	prg := make([]byte, 0x8000*4) // 32K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewGxROM(base)
+
+	chr[0x4010] = 0x01  // bank 2
+	prg[0x18010] = 0x02 // bank 3
+
+	m.Write(0x8000, 3<<4|2) // select PRG bank 3 and CHR bank 2
+	assert.Equal(t, 0x01, m.Read(0x0010))
+	assert.Equal(t, 0x02, m.Read(0x8010))
+}
@@ -22,6 +22,7 @@ func TestMapperMMC1(t *testing.T) {
 		NameTable: nametable.New(cartridge.MirrorHorizontal),
 	})
 	m := NewMMC1(base)
+	assert.Equal(t, 0x2000, len(m.BatteryRAM())) // only the $6000-$7FFF window is mapped
 
 	chr[0x0000] = 0x01
 	chr[0x2000] = 0x02
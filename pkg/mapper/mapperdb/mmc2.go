@@ -0,0 +1,164 @@
+package mapperdb
+
+/*
+MMC2
+Boards: PNROM, PEEOROM
+PRG ROM capacity: 128K
+PRG ROM window: 8K + 24K fixed
+CHR capacity: 128K
+CHR window: 4K + 4K (triggered)
+
+MMC4
+Boards: FJROM, FKROM
+PRG ROM capacity: 256K
+PRG ROM window: 16K + 16K fixed
+PRG RAM: 8K
+CHR capacity: 128K
+CHR window: 4K + 4K (triggered)
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const (
+	mmc2LatchFD = 0
+	mmc2LatchFE = 1
+)
+
+type mapperMMC2 struct {
+	Base
+
+	exactLatch0 bool // MMC2 switches latch 0 only on reads of $0FD8 and $0FE8
+
+	chrBanks [2][2]int // bank for pattern table 0/1 and latch state $FD/$FE
+	latches  [2]int
+
+	pendingLatch int // pattern table of a latch change that gets applied on the next read
+	pendingValue int
+	latchPending bool
+}
+
+// NewMMC2 returns a new mapper instance.
+func NewMMC2(base Base) bus.Mapper {
+	m := &mapperMMC2{
+		Base:        base,
+		exactLatch0: true,
+	}
+	m.SetName("MMC2")
+	m.SetPrgWindowSize(0x2000) // 8K
+	m.setup()
+
+	// $8000-$9FFF: 8 KB switchable PRG ROM bank
+	// $A000-$FFFF: three 8 KB PRG ROM banks, fixed to the last three banks
+	m.SetPrgWindow(1, -3)
+	m.SetPrgWindow(2, -2)
+	m.SetPrgWindow(3, -1)
+	return m
+}
+
+// NewMMC4 returns a new mapper instance.
+func NewMMC4(base Base) bus.Mapper {
+	m := &mapperMMC2{
+		Base: base,
+	}
+	m.SetName("MMC4")
+	m.SetPrgRAM(make([]byte, 0x2000)) // 8K
+	m.setup()
+
+	// $8000-$BFFF: 16 KB switchable PRG ROM bank
+	// $C000-$FFFF: 16 KB PRG ROM bank, fixed to the last bank
+	m.SetPrgWindow(1, -1)
+	return m
+}
+
+func (m *mapperMMC2) setup() {
+	m.SetChrWindowSize(0x1000) // 4K
+	m.Initialize()
+
+	m.latches = [2]int{mmc2LatchFE, mmc2LatchFE}
+
+	hook := m.AddPPUReadHook(0x0000, 0x1FFF, m.readChr)
+	hook.SetProxyOnly(true)
+	m.AddWriteHook(0x8000, 0xFFFF, m.setRegister)
+
+	translation := mapperbase.MirrorModeTranslation{
+		0: cartridge.MirrorVertical,
+		1: cartridge.MirrorHorizontal,
+	}
+	m.SetMirrorModeTranslation(translation)
+}
+
+// readChr observes all PPU reads of the pattern tables. Reading one of the trigger tiles
+// switches the latch of the pattern table after the read has finished, so the change
+// is applied on the next read.
+func (m *mapperMMC2) readChr(address uint16) uint8 {
+	if m.latchPending {
+		m.latchPending = false
+		m.latches[m.pendingLatch] = m.pendingValue
+		m.updateChrWindow(m.pendingLatch)
+	}
+
+	table := int(address >> 12)
+	offset := address & 0x0FFF
+
+	var latch int
+	switch {
+	case m.isTrigger(table, offset, 0x0FD8):
+		latch = mmc2LatchFD
+	case m.isTrigger(table, offset, 0x0FE8):
+		latch = mmc2LatchFE
+	default:
+		return 0
+	}
+
+	m.latchPending = true
+	m.pendingLatch = table
+	m.pendingValue = latch
+	return 0
+}
+
+// isTrigger returns whether the offset into the pattern table is part of the trigger
+// tile row that starts at the given trigger offset.
+func (m *mapperMMC2) isTrigger(table int, offset, trigger uint16) bool {
+	if table == 0 && m.exactLatch0 {
+		return offset == trigger
+	}
+	return offset >= trigger && offset <= trigger+7
+}
+
+func (m *mapperMMC2) setRegister(address uint16, value uint8) {
+	switch {
+	case address < 0xA000: // $8000-$9FFF has no register
+
+	case address < 0xB000: // $A000-$AFFF
+		m.SetPrgWindow(0, int(value&0b0000_1111))
+
+	case address < 0xC000: // $B000-$BFFF
+		m.setChrBank(0, mmc2LatchFD, value)
+
+	case address < 0xD000: // $C000-$CFFF
+		m.setChrBank(0, mmc2LatchFE, value)
+
+	case address < 0xE000: // $D000-$DFFF
+		m.setChrBank(1, mmc2LatchFD, value)
+
+	case address < 0xF000: // $E000-$EFFF
+		m.setChrBank(1, mmc2LatchFE, value)
+
+	default: // $F000-$FFFF
+		m.SetNameTableMirrorModeIndex(value & 1)
+	}
+}
+
+func (m *mapperMMC2) setChrBank(table, latch int, value uint8) {
+	m.chrBanks[table][latch] = int(value & 0b0001_1111)
+	m.updateChrWindow(table)
+}
+
+func (m *mapperMMC2) updateChrWindow(table int) {
+	bank := m.chrBanks[table][m.latches[table]]
+	m.SetChrWindow(table, bank)
+}
@@ -0,0 +1,91 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperMMC2(t *testing.T) {
+	chr := make([]byte, 0x1000*4) // 4K banks
+	prg := make([]byte, 0x2000*4) // 8K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMMC2(base)
+
+	for bank := 0; bank < 4; bank++ {
+		chr[bank*0x1000+0x10] = byte(bank)
+		prg[bank*0x2000] = byte(bank)
+	}
+
+	m.Write(0xA000, 2) // select PRG bank 2
+	assert.Equal(t, 2, m.Read(0x8000))
+	assert.Equal(t, 1, m.Read(0xA000))
+	assert.Equal(t, 3, m.Read(0xE000))
+
+	m.Write(0xB000, 1) // $FD bank for $0000
+	m.Write(0xC000, 2) // $FE bank for $0000
+	m.Write(0xD000, 3) // $FD bank for $1000
+	m.Write(0xE000, 0) // $FE bank for $1000
+	assert.Equal(t, 2, m.ReadPPU(0x0010))
+	assert.Equal(t, 0, m.ReadPPU(0x1010))
+
+	// the latch is switched after the read of the trigger tile
+	m.ReadPPU(0x0FD8)
+	assert.Equal(t, 1, m.ReadPPU(0x0010))
+	m.ReadPPU(0x0FD9) // MMC2 triggers latch 0 only on the exact address
+	m.ReadPPU(0x0FE9)
+	assert.Equal(t, 1, m.ReadPPU(0x0010))
+	m.ReadPPU(0x0FE8)
+	assert.Equal(t, 2, m.ReadPPU(0x0010))
+
+	m.ReadPPU(0x1FDA)
+	assert.Equal(t, 3, m.ReadPPU(0x1010))
+	m.ReadPPU(0x1FEF)
+	assert.Equal(t, 0, m.ReadPPU(0x1010))
+
+	m.Write(0xF000, 0)
+	assert.Equal(t, cartridge.MirrorVertical, m.MirrorMode())
+}
+
+func TestMapperMMC4(t *testing.T) {
+	chr := make([]byte, 0x1000*4) // 4K banks
+	prg := make([]byte, 0x4000*4) // 16K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMMC4(base)
+
+	chr[0x1010] = 0x01 // bank 1
+	chr[0x2010] = 0x02 // bank 2
+	prg[0x8000] = 0x03 // bank 2
+	prg[0xC000] = 0x04 // bank 3
+
+	m.Write(0xA000, 2) // select PRG bank 2
+	assert.Equal(t, 0x03, m.Read(0x8000))
+	assert.Equal(t, 0x04, m.Read(0xC000))
+
+	m.Write(0xB000, 1)
+	m.Write(0xC000, 2)
+	assert.Equal(t, 0x02, m.ReadPPU(0x0010))
+	m.ReadPPU(0x0FDC) // MMC4 triggers latch 0 on the whole tile row
+	assert.Equal(t, 0x01, m.ReadPPU(0x0010))
+
+	m.Write(0x6000, 0x05)
+	assert.Equal(t, 0x05, m.Read(0x6000))
+}
@@ -0,0 +1,317 @@
+package mapperdb
+
+/*
+Boards: EKROM, ELROM, ETROM, EWROM
+PRG ROM capacity: 1024K
+PRG ROM window: 8K, 16K or 32K
+PRG RAM: 64K
+PRG RAM window: 8K
+CHR capacity: 1024K
+CHR window: 1K, 2K, 4K or 8K
+ExRAM: 1K
+
+Only the core of the MMC5 is supported: PRG and CHR banking, PRG RAM, the multiplier,
+ExRAM as CPU accessible memory and the scanline IRQ. Not supported are PRG RAM mapped
+into $8000-$DFFF, the separate background CHR banks for 8x16 sprite mode, nametables
+that are mapped to ExRAM or the fill mode, the extended attribute mode, the vertical
+split mode and the expansion audio.
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const (
+	mmc5ExRAMStart = 0x5C00
+	mmc5ExRAMEnd   = 0x5FFF
+
+	// the frame is considered finished when the PPU did not read memory for about one
+	// scanline, which happens in the vertical blank or when rendering is disabled
+	mmc5InFrameTimeout = 341 / 3
+)
+
+// mmc5NameTableMirrorModes maps the $5105 nametable mapping values that only use the
+// internal nametables to the matching mirror mode.
+var mmc5NameTableMirrorModes = map[uint8]cartridge.MirrorMode{
+	0b00_00_00_00: cartridge.MirrorSingle0,
+	0b01_01_01_01: cartridge.MirrorSingle1,
+	0b01_00_01_00: cartridge.MirrorVertical,
+	0b01_01_00_00: cartridge.MirrorHorizontal,
+}
+
+type mapperMMC5 struct {
+	Base
+
+	ram   []byte
+	exRAM []byte
+
+	prgMode      uint8
+	chrMode      uint8
+	ramProtect1  uint8
+	ramProtect2  uint8
+	exRAMMode    uint8
+	ramBank      int
+	prgBanks     [4]int // $5114-$5117
+	chrBanks     [12]int
+	chrUpperBits int
+
+	multiplicand uint8
+	multiplier   uint8
+
+	irqCompare uint8
+	irqEnabled bool
+	irqPending bool
+
+	// scanline detection state
+	idleCycles  int
+	lastAddress uint16
+	matchCount  int
+	inFrame     bool
+	scanLine    uint8
+}
+
+// NewMMC5 returns a new mapper instance.
+func NewMMC5(base Base) bus.Mapper {
+	m := &mapperMMC5{
+		Base:    base,
+		ram:     make([]byte, 0x10000), // 64K
+		exRAM:   make([]byte, mmc5ExRAMEnd-mmc5ExRAMStart+1),
+		prgMode: 3,
+	}
+	m.SetName("MMC5")
+	m.SetPrgWindowSize(prgBankSize8K)
+	m.SetChrWindowSize(0x0400) // 1K
+	m.SetPrgRAM(m.ram)
+	m.SetBatteryRAMSize(len(m.ram))
+	m.Initialize()
+
+	m.prgBanks[3] = 0xFF
+	m.updatePrgWindows()
+
+	hook := m.AddPPUReadHook(0x0000, 0x3EFF, m.readPPU)
+	hook.SetProxyOnly(true)
+	m.AddReadHook(0x5000, 0x5FFF, m.readRegister)
+	m.AddWriteHook(0x5000, 0x5FFF, m.writeRegister)
+	m.AddReadHook(0x6000, 0x7FFF, m.readRAM)
+	m.AddWriteHook(0x6000, 0x7FFF, m.writeRAM)
+	m.AddWriteHook(0x8000, 0xFFFF, func(address uint16, value uint8) {}) // PRG RAM at $8000+ is not supported
+	m.SetCycleHook(m.step)
+
+	return m
+}
+
+func (m *mapperMMC5) readRegister(address uint16) uint8 {
+	switch {
+	case address == 0x5204:
+		return m.readIrqStatus()
+
+	case address == 0x5205:
+		return uint8(uint16(m.multiplicand) * uint16(m.multiplier))
+
+	case address == 0x5206:
+		return uint8(uint16(m.multiplicand) * uint16(m.multiplier) >> 8)
+
+	case address >= mmc5ExRAMStart && m.exRAMMode >= 2:
+		return m.exRAM[address-mmc5ExRAMStart]
+
+	default:
+		return 0 // TODO should return open bus value
+	}
+}
+
+func (m *mapperMMC5) writeRegister(address uint16, value uint8) {
+	switch {
+	case address == 0x5100:
+		m.prgMode = value & 0b0000_0011
+		m.updatePrgWindows()
+
+	case address == 0x5101:
+		m.chrMode = value & 0b0000_0011
+		m.updateChrWindows()
+
+	case address == 0x5102:
+		m.ramProtect1 = value & 0b0000_0011
+
+	case address == 0x5103:
+		m.ramProtect2 = value & 0b0000_0011
+
+	case address == 0x5104:
+		m.exRAMMode = value & 0b0000_0011
+
+	case address == 0x5105:
+		m.setNameTableMapping(value)
+
+	case address == 0x5113:
+		m.ramBank = int(value & 0b0000_0111)
+
+	case address >= 0x5114 && address <= 0x5117:
+		m.prgBanks[address-0x5114] = int(value)
+		m.updatePrgWindows()
+
+	case address >= 0x5120 && address <= 0x512B:
+		m.chrBanks[address-0x5120] = int(value) | m.chrUpperBits<<8
+		m.updateChrWindows()
+
+	case address == 0x5130:
+		m.chrUpperBits = int(value & 0b0000_0011)
+
+	case address == 0x5203:
+		m.irqCompare = value
+
+	case address == 0x5204:
+		m.setIrqEnabled(value&0b1000_0000 != 0)
+
+	case address == 0x5205:
+		m.multiplicand = value
+
+	case address == 0x5206:
+		m.multiplier = value
+
+	case address >= mmc5ExRAMStart && m.exRAMMode != 3:
+		m.exRAM[address-mmc5ExRAMStart] = value
+	}
+}
+
+// setNameTableMapping sets the nametable mirror mode if the mapping is representable
+// by a mirror mode of the internal nametables.
+func (m *mapperMMC5) setNameTableMapping(value uint8) {
+	mode, ok := mmc5NameTableMirrorModes[value]
+	if ok {
+		m.SetNameTableMirrorMode(mode)
+	}
+}
+
+func (m *mapperMMC5) readRAM(address uint16) uint8 {
+	return m.ram[m.ramOffset(address)]
+}
+
+func (m *mapperMMC5) writeRAM(address uint16, value uint8) {
+	// writing is only allowed when both protect registers are set to the unlock values
+	if m.ramProtect1 != 0b10 || m.ramProtect2 != 0b01 {
+		return
+	}
+	m.ram[m.ramOffset(address)] = value
+}
+
+func (m *mapperMMC5) ramOffset(address uint16) int {
+	return m.ramBank*prgBankSize8K + int(address-0x6000)
+}
+
+// updatePrgWindows maps the PRG ROM banks into the four 8 KB windows based on the PRG mode.
+// The lowest bits of the bank numbers are ignored for 16 KB and 32 KB banks.
+func (m *mapperMMC5) updatePrgWindows() {
+	var banks [4]int
+
+	switch m.prgMode {
+	case 0: // one 32 KB bank
+		bank := m.prgBanks[3] &^ 0b11
+		banks = [4]int{bank, bank + 1, bank + 2, bank + 3}
+
+	case 1: // two 16 KB banks
+		bank0 := m.prgBanks[1] &^ 1
+		bank1 := m.prgBanks[3] &^ 1
+		banks = [4]int{bank0, bank0 + 1, bank1, bank1 + 1}
+
+	case 2: // one 16 KB bank and two 8 KB banks
+		bank0 := m.prgBanks[1] &^ 1
+		banks = [4]int{bank0, bank0 + 1, m.prgBanks[2], m.prgBanks[3]}
+
+	case 3: // four 8 KB banks
+		banks = [4]int{m.prgBanks[0], m.prgBanks[1], m.prgBanks[2], m.prgBanks[3]}
+	}
+
+	for window, bank := range banks {
+		m.SetPrgWindow(window, bank&0b0111_1111) // bit 7 selects ROM
+	}
+}
+
+// updateChrWindows maps the CHR banks into the eight 1 KB windows based on the CHR mode.
+// Only the first register set $5120-$5127 is used.
+func (m *mapperMMC5) updateChrWindows() {
+	size := 8 >> m.chrMode // window size in 1 KB units
+
+	for window := 0; window < 8; window++ {
+		register := window | (size - 1) // the last register of every bank is used
+		bank := m.chrBanks[register]*size + window%size
+		m.SetChrWindow(window, bank)
+	}
+}
+
+// readPPU observes all PPU reads to detect scanlines. At the end of every rendered
+// scanline the PPU fetches the same nametable byte 3 times in a row, which is used
+// by the MMC5 to detect the start of the next scanline.
+func (m *mapperMMC5) readPPU(address uint16) uint8 {
+	m.idleCycles = 0
+
+	if address < 0x2000 || address != m.lastAddress {
+		m.lastAddress = address
+		m.matchCount = 0
+		return 0
+	}
+
+	m.matchCount++
+	if m.matchCount == 2 {
+		m.detectScanLine()
+	}
+	return 0
+}
+
+// detectScanLine starts a new frame or increments the scanline counter and checks
+// whether the IRQ scanline has been reached.
+func (m *mapperMMC5) detectScanLine() {
+	if !m.inFrame {
+		m.inFrame = true
+		m.scanLine = 0
+		return
+	}
+
+	m.scanLine++
+	if m.scanLine != m.irqCompare {
+		return
+	}
+
+	m.irqPending = true
+	if m.irqEnabled {
+		m.TriggerIrq(mapperbase.IrqCounter)
+	}
+}
+
+// step is called every CPU cycle and ends the frame when the PPU stopped reading memory.
+func (m *mapperMMC5) step() {
+	if !m.inFrame {
+		return
+	}
+
+	m.idleCycles++
+	if m.idleCycles > mmc5InFrameTimeout {
+		m.inFrame = false
+		m.lastAddress = 0
+		m.matchCount = 0
+	}
+}
+
+func (m *mapperMMC5) readIrqStatus() uint8 {
+	var value uint8
+	if m.irqPending {
+		value |= 0b1000_0000
+	}
+	if m.inFrame {
+		value |= 0b0100_0000
+	}
+
+	m.irqPending = false
+	m.AcknowledgeIrq(mapperbase.IrqCounter)
+	return value
+}
+
+func (m *mapperMMC5) setIrqEnabled(enabled bool) {
+	m.irqEnabled = enabled
+	switch {
+	case !enabled:
+		m.AcknowledgeIrq(mapperbase.IrqCounter)
+	case m.irqPending:
+		m.TriggerIrq(mapperbase.IrqCounter)
+	}
+}
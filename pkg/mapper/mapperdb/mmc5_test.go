@@ -0,0 +1,122 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperMMC5(t *testing.T) {
+	chr := make([]byte, 0x0400*32) // 1K banks
+	prg := make([]byte, 0x2000*8)  // 8K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		CPU:       &testCPU{},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMMC5(base)
+
+	for bank := 0; bank < 8; bank++ {
+		prg[bank*0x2000] = byte(bank)
+	}
+	for bank := 0; bank < 32; bank++ {
+		chr[bank*0x0400] = byte(bank)
+	}
+
+	// PRG mode 3 is the default, $E000 is mapped to the last bank
+	assert.Equal(t, 7, m.Read(0xE000))
+	m.Write(0x5114, 0x82)
+	assert.Equal(t, 2, m.Read(0x8000))
+
+	m.Write(0x5100, 1) // two 16 KB banks
+	m.Write(0x5115, 0x85)
+	m.Write(0x5117, 0x82)
+	assert.Equal(t, 4, m.Read(0x8000))
+	assert.Equal(t, 5, m.Read(0xA000))
+	assert.Equal(t, 2, m.Read(0xC000))
+	assert.Equal(t, 3, m.Read(0xE000))
+
+	m.Write(0x5101, 1) // two 4 KB CHR banks
+	m.Write(0x5123, 2)
+	m.Write(0x5127, 5)
+	assert.Equal(t, 8, m.Read(0x0000))
+	assert.Equal(t, 11, m.Read(0x0C00))
+	assert.Equal(t, 20, m.Read(0x1000))
+
+	m.Write(0x5101, 3) // eight 1 KB CHR banks
+	m.Write(0x5121, 30)
+	assert.Equal(t, 30, m.Read(0x0400))
+
+	// PRG RAM is write protected until both protect registers are set
+	m.Write(0x5113, 1)
+	m.Write(0x6000, 0x12)
+	assert.Equal(t, 0, m.Read(0x6000))
+	m.Write(0x5102, 0b10)
+	m.Write(0x5103, 0b01)
+	m.Write(0x6000, 0x12)
+	assert.Equal(t, 0x12, m.Read(0x6000))
+	assert.Equal(t, 0x12, m.PrgRAM()[0x2000])
+	assert.Equal(t, 0x10000, len(m.BatteryRAM()))
+
+	m.Write(0x5205, 200)
+	m.Write(0x5206, 100)
+	assert.Equal(t, 20000&0xFF, m.Read(0x5205))
+	assert.Equal(t, 20000>>8, m.Read(0x5206))
+
+	m.Write(0x5104, 2) // ExRAM as general purpose RAM
+	m.Write(0x5C10, 0x34)
+	assert.Equal(t, 0x34, m.Read(0x5C10))
+
+	m.Write(0x5105, 0b01_00_01_00)
+	assert.Equal(t, cartridge.MirrorVertical, m.MirrorMode())
+}
+
+func TestMapperMMC5ScanlineIrq(t *testing.T) {
+	cpu := &testCPU{}
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: make([]byte, 0x2000),
+			PRG: make([]byte, 0x8000),
+		},
+		CPU:       cpu,
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewMMC5(base)
+
+	m.Write(0x5203, 2)           // IRQ at scanline 2
+	m.Write(0x5204, 0b1000_0000) // enable IRQ
+
+	// simulate the 3 nametable fetches of the same address at the end of every scanline
+	endScanLine := func() {
+		m.ReadPPU(0x0010)
+		for i := 0; i < 3; i++ {
+			m.ReadPPU(0x2001)
+			m.Step()
+		}
+	}
+
+	endScanLine() // pre-render scanline starts the frame
+	assert.Equal(t, 0b0100_0000, m.Read(0x5204))
+	endScanLine() // scanline 1
+	assert.False(t, cpu.irq)
+	endScanLine() // scanline 2
+	assert.True(t, cpu.irq)
+
+	status := m.Read(0x5204)
+	assert.Equal(t, 0b1100_0000, status)
+	assert.False(t, cpu.irq)
+
+	// the frame ends when the PPU stops reading
+	for i := 0; i <= mmc5InFrameTimeout; i++ {
+		m.Step()
+	}
+	assert.Equal(t, 0, m.Read(0x5204))
+}
@@ -0,0 +1,184 @@
+package mapperdb
+
+/*
+Boards: Konami VRC2, VRC4
+PRG ROM capacity: 256K
+PRG ROM window: 8K + 8K + 16K fixed
+PRG RAM: 8K
+CHR capacity: 512K
+CHR window: 1K
+
+The boards differ in which CPU address lines are connected to the register select pins
+of the chip. The mapper numbers 21, 23 and 25 combine two boards each by decoding both
+address line variants.
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// vrcRegisterDecoder returns the register index 0-3 that is selected by the address.
+type vrcRegisterDecoder func(address uint16) uint16
+
+type mapperVRC24 struct {
+	Base
+
+	irq      *vrcIrq
+	register vrcRegisterDecoder
+
+	vrc2        bool // VRC2 only supports 1 bit mirroring and has no PRG swap mode
+	chrShift    int  // VRC2a ignores the lowest bit of the CHR bank numbers
+	swapMode    bool
+	prgBank0    int
+	prgBank1    int
+	chrBanks    [8]int
+	chrHighMask uint8 // mask for the high nibble of the CHR bank numbers
+}
+
+// NewVRC4a returns a new mapper instance for mapper 21 that supports the VRC4a and VRC4c boards.
+func NewVRC4a(base Base) bus.Mapper {
+	m := newMapperVRC24(base, func(address uint16) uint16 {
+		return (address>>1 | address>>6) & 0b11 // A1|A6, A2|A7
+	})
+	m.SetName("VRC4a/VRC4c")
+	return m
+}
+
+// NewVRC2a returns a new mapper instance for mapper 22 that supports the VRC2a board.
+func NewVRC2a(base Base) bus.Mapper {
+	m := newMapperVRC24(base, func(address uint16) uint16 {
+		return (address>>1)&1 | (address<<1)&2 // A1, A0
+	})
+	m.SetName("VRC2a")
+	m.vrc2 = true
+	m.chrShift = 1
+	m.chrHighMask = 0b0000_1111
+	return m
+}
+
+// NewVRC2b returns a new mapper instance for mapper 23 that supports the VRC2b, VRC4e and VRC4f boards.
+func NewVRC2b(base Base) bus.Mapper {
+	m := newMapperVRC24(base, func(address uint16) uint16 {
+		return (address | address>>2) & 0b11 // A0|A2, A1|A3
+	})
+	m.SetName("VRC2b/VRC4e/VRC4f")
+	return m
+}
+
+// NewVRC2c returns a new mapper instance for mapper 25 that supports the VRC2c, VRC4b and VRC4d boards.
+func NewVRC2c(base Base) bus.Mapper {
+	m := newMapperVRC24(base, func(address uint16) uint16 {
+		return (address>>1|address>>3)&1 | (address<<1|address>>1)&2 // A1|A3, A0|A2
+	})
+	m.SetName("VRC2c/VRC4b/VRC4d")
+	return m
+}
+
+func newMapperVRC24(base Base, register vrcRegisterDecoder) *mapperVRC24 {
+	m := &mapperVRC24{
+		Base:        base,
+		irq:         newVrcIrq(base),
+		register:    register,
+		chrHighMask: 0b0001_1111,
+	}
+	m.SetPrgWindowSize(0x2000)        // 8K
+	m.SetChrWindowSize(0x0400)        // 1K
+	m.SetPrgRAM(make([]byte, 0x2000)) // 8K
+	m.Initialize()
+
+	m.AddWriteHook(0x8000, 0xFFFF, m.setRegister)
+	m.SetCycleHook(m.irq.step)
+
+	translation := mapperbase.MirrorModeTranslation{
+		0: cartridge.MirrorVertical,
+		1: cartridge.MirrorHorizontal,
+		2: cartridge.MirrorSingle0,
+		3: cartridge.MirrorSingle1,
+	}
+	m.SetMirrorModeTranslation(translation)
+
+	m.updatePrgWindows()
+	return m
+}
+
+func (m *mapperVRC24) setRegister(address uint16, value uint8) {
+	register := m.register(address)
+
+	switch address & 0xF000 {
+	case 0x8000:
+		m.prgBank0 = int(value & 0b0001_1111)
+		m.updatePrgWindows()
+
+	case 0x9000:
+		m.setControl(register, value)
+
+	case 0xA000:
+		m.prgBank1 = int(value & 0b0001_1111)
+		m.updatePrgWindows()
+
+	case 0xB000, 0xC000, 0xD000, 0xE000:
+		m.setChrBank(address, register, value)
+
+	case 0xF000:
+		m.setIrqRegister(register, value)
+	}
+}
+
+func (m *mapperVRC24) setControl(register uint16, value uint8) {
+	switch {
+	case m.vrc2:
+		m.SetNameTableMirrorModeIndex(value & 1)
+
+	case register < 2:
+		m.SetNameTableMirrorModeIndex(value & 0b0000_0011)
+
+	default:
+		m.swapMode = value&0b0000_0010 != 0
+		m.updatePrgWindows()
+	}
+}
+
+// setChrBank sets the low or high nibble of a 1 KB CHR bank number, every register
+// range $B000-$E000 controls two banks.
+func (m *mapperVRC24) setChrBank(address, register uint16, value uint8) {
+	index := int((address-0xB000)>>12)*2 + int(register>>1)
+	bank := m.chrBanks[index]
+
+	if register&1 == 0 {
+		bank = bank&^0x0F | int(value&0x0F)
+	} else {
+		bank = bank&0x0F | int(value&m.chrHighMask)<<4
+	}
+
+	m.chrBanks[index] = bank
+	m.SetChrWindow(index, bank>>m.chrShift)
+}
+
+func (m *mapperVRC24) setIrqRegister(register uint16, value uint8) {
+	switch register {
+	case 0:
+		m.irq.setLatchLow(value)
+	case 1:
+		m.irq.setLatchHigh(value)
+	case 2:
+		m.irq.setControl(value)
+	case 3:
+		m.irq.acknowledge()
+	}
+}
+
+func (m *mapperVRC24) updatePrgWindows() {
+	if m.swapMode {
+		// $8000-$9FFF: fixed to the second last bank, $C000-$DFFF: switchable
+		m.SetPrgWindow(0, -2)
+		m.SetPrgWindow(2, m.prgBank0)
+	} else {
+		// $8000-$9FFF: switchable, $C000-$DFFF: fixed to the second last bank
+		m.SetPrgWindow(0, m.prgBank0)
+		m.SetPrgWindow(2, -2)
+	}
+	m.SetPrgWindow(1, m.prgBank1)
+	m.SetPrgWindow(3, -1)
+}
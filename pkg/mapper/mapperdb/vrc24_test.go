@@ -0,0 +1,122 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testCPU records the interrupt request state that is signaled by a mapper.
+type testCPU struct {
+	irq bool
+}
+
+func (c *testCPU) ClearIrq()                 { c.irq = false }
+func (c *testCPU) Cycles() uint64            { return 0 }
+func (c *testCPU) StallCycles(cycles uint16) {}
+func (c *testCPU) State() bus.CPUState       { return bus.CPUState{} }
+func (c *testCPU) TriggerIrq()               { c.irq = true }
+func (c *testCPU) TriggerNMI()               {}
+
+func TestMapperVRC4(t *testing.T) {
+	chr := make([]byte, 0x0400*32) // 1K banks
+	prg := make([]byte, 0x2000*8)  // 8K banks
+
+	cpu := &testCPU{}
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		CPU:       cpu,
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewVRC2c(base) // VRC4b uses A1 and A0 as register select
+
+	for bank := 0; bank < 8; bank++ {
+		prg[bank*0x2000] = byte(bank)
+	}
+	chr[0x0400*18+0x10] = 0x12 // bank 18
+
+	m.Write(0x8000, 3)
+	m.Write(0xA000, 4)
+	assert.Equal(t, 3, m.Read(0x8000))
+	assert.Equal(t, 4, m.Read(0xA000))
+	assert.Equal(t, 6, m.Read(0xC000))
+	assert.Equal(t, 7, m.Read(0xE000))
+
+	m.Write(0x9001, 2) // register 2: swap mode
+	assert.Equal(t, 6, m.Read(0x8000))
+	assert.Equal(t, 3, m.Read(0xC000))
+
+	m.Write(0xD001, 2) // register 2: CHR bank 5 low nibble
+	m.Write(0xD003, 1) // register 3: CHR bank 5 high nibble
+	assert.Equal(t, 0x12, m.Read(0x1410))
+
+	m.Write(0x9000, 3)
+	assert.Equal(t, cartridge.MirrorSingle1, m.MirrorMode())
+
+	m.Write(0x6000, 0x05)
+	assert.Equal(t, 0x05, m.Read(0x6000))
+
+	m.Write(0xF000, 0x0E)  // latch low
+	m.Write(0xF002, 0x0F)  // latch high
+	m.Write(0xF001, 0b111) // cycle mode, enable
+	m.Step()               // counter $FE -> $FF
+	assert.False(t, cpu.irq)
+	m.Step() // counter overflow
+	assert.True(t, cpu.irq)
+
+	m.Write(0xF003, 0) // acknowledge
+	assert.False(t, cpu.irq)
+}
+
+func TestMapperVRC4ScanlineIrq(t *testing.T) {
+	cpu := &testCPU{}
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: make([]byte, 0x2000),
+			PRG: make([]byte, 0x8000),
+		},
+		CPU:       cpu,
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewVRC4a(base) // VRC4a uses A1 and A2 as register select
+
+	m.Write(0xF000, 0x0F)  // latch low
+	m.Write(0xF002, 0x0F)  // latch high
+	m.Write(0xF004, 0b010) // scanline mode, enable
+
+	// the counter overflows after one scanline of 341 PPU cycles
+	for i := 0; i < 113; i++ {
+		m.Step()
+	}
+	assert.False(t, cpu.irq)
+	m.Step()
+	assert.True(t, cpu.irq)
+}
+
+func TestMapperVRC2a(t *testing.T) {
+	chr := make([]byte, 0x0400*16) // 1K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: make([]byte, 0x8000),
+		},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewVRC2a(base)
+
+	chr[0x0400*3+0x10] = 0x01 // bank 3
+
+	m.Write(0xB001, 7) // register 2: CHR bank 1, the lowest bit is ignored
+	assert.Equal(t, 0x01, m.Read(0x0410))
+
+	m.Write(0x9000, 1)
+	assert.Equal(t, cartridge.MirrorHorizontal, m.MirrorMode())
+}
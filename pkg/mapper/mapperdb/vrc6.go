@@ -0,0 +1,124 @@
+package mapperdb
+
+/*
+Boards: Konami VRC6a, VRC6b
+PRG ROM capacity: 256K
+PRG ROM window: 16K + 8K + 8K fixed
+PRG RAM: 8K
+CHR capacity: 256K
+CHR window: 1K
+Expansion audio: 2 pulse channels, 1 sawtooth channel
+
+Only the default PPU banking mode with 8 1 KB CHR banks is supported.
+*/
+
+import (
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+type mapperVRC6 struct {
+	Base
+
+	audio    vrc6Audio
+	irq      *vrcIrq
+	register vrcRegisterDecoder
+}
+
+// NewVRC6a returns a new mapper instance for mapper 24.
+func NewVRC6a(base Base) bus.Mapper {
+	m := newMapperVRC6(base, func(address uint16) uint16 {
+		return address & 0b11 // A0, A1
+	})
+	m.SetName("VRC6a")
+	return m
+}
+
+// NewVRC6b returns a new mapper instance for mapper 26 which has the register select lines swapped.
+func NewVRC6b(base Base) bus.Mapper {
+	m := newMapperVRC6(base, func(address uint16) uint16 {
+		return (address>>1)&1 | (address<<1)&2 // A1, A0
+	})
+	m.SetName("VRC6b")
+	return m
+}
+
+func newMapperVRC6(base Base, register vrcRegisterDecoder) *mapperVRC6 {
+	m := &mapperVRC6{
+		Base:     base,
+		irq:      newVrcIrq(base),
+		register: register,
+	}
+	m.SetPrgWindowSize(prgBankSize8K)
+	m.SetChrWindowSize(0x0400)        // 1K
+	m.SetPrgRAM(make([]byte, 0x2000)) // 8K
+	m.Initialize()
+
+	m.AddWriteHook(0x8000, 0xFFFF, m.setRegister)
+	m.SetCycleHook(m.step)
+
+	translation := mapperbase.MirrorModeTranslation{
+		0: cartridge.MirrorVertical,
+		1: cartridge.MirrorHorizontal,
+		2: cartridge.MirrorSingle0,
+		3: cartridge.MirrorSingle1,
+	}
+	m.SetMirrorModeTranslation(translation)
+
+	m.SetPrgWindow(3, -1) // $E000-$FFFF: 8 KB PRG ROM bank, fixed to the last bank
+	return m
+}
+
+// AudioOutput returns the current output level of the expansion audio channels.
+func (m *mapperVRC6) AudioOutput() float32 {
+	return m.audio.output()
+}
+
+func (m *mapperVRC6) step() {
+	m.irq.step()
+	m.audio.step()
+}
+
+func (m *mapperVRC6) setRegister(address uint16, value uint8) {
+	register := m.register(address)
+	base := address & 0xF000
+
+	switch base {
+	case 0x8000: // 16 KB PRG ROM bank at $8000-$BFFF
+		bank := int(value&0b0000_1111) * 2
+		m.SetPrgWindow(0, bank)
+		m.SetPrgWindow(1, bank+1)
+
+	case 0x9000, 0xA000:
+		m.audio.setRegister(base, register, value)
+
+	case 0xB000:
+		if register == 3 {
+			m.SetNameTableMirrorModeIndex((value >> 2) & 0b0000_0011)
+			return
+		}
+		m.audio.setRegister(base, register, value)
+
+	case 0xC000: // 8 KB PRG ROM bank at $C000-$DFFF
+		m.SetPrgWindow(2, int(value&0b0001_1111))
+
+	case 0xD000, 0xE000: // 1 KB CHR banks
+		window := int(base-0xD000)>>10 | int(register)
+		m.SetChrWindow(window, int(value))
+
+	case 0xF000:
+		m.setIrqRegister(register, value)
+	}
+}
+
+func (m *mapperVRC6) setIrqRegister(register uint16, value uint8) {
+	switch register {
+	case 0:
+		m.irq.setLatch(value)
+	case 1:
+		m.irq.setControl(value)
+	case 2:
+		m.irq.acknowledge()
+	}
+}
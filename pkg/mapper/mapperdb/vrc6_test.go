@@ -0,0 +1,86 @@
+package mapperdb
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/apu"
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMapperVRC6(t *testing.T) {
+	chr := make([]byte, 0x0400*16) // 1K banks
+	prg := make([]byte, 0x2000*8)  // 8K banks
+
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: chr,
+			PRG: prg,
+		},
+		CPU:       &testCPU{},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewVRC6b(base)
+
+	for bank := 0; bank < 8; bank++ {
+		prg[bank*0x2000] = byte(bank)
+	}
+	chr[0x0400*9+0x10] = 0x01 // bank 9
+
+	m.Write(0x8000, 1) // 16 KB bank 1
+	assert.Equal(t, 2, m.Read(0x8000))
+	assert.Equal(t, 3, m.Read(0xA000))
+
+	m.Write(0xC000, 5)
+	assert.Equal(t, 5, m.Read(0xC000))
+	assert.Equal(t, 7, m.Read(0xE000))
+
+	m.Write(0xE001, 9) // register 2: CHR bank 6
+	assert.Equal(t, 0x01, m.Read(0x1810))
+
+	m.Write(0xB003, 0b0000_0100)
+	assert.Equal(t, cartridge.MirrorHorizontal, m.MirrorMode())
+}
+
+func TestMapperVRC6Audio(t *testing.T) {
+	base := mapperbase.New(&bus.Bus{
+		Cartridge: &cartridge.Cartridge{
+			CHR: make([]byte, 0x2000),
+			PRG: make([]byte, 0x8000),
+		},
+		CPU:       &testCPU{},
+		NameTable: nametable.New(cartridge.MirrorHorizontal),
+	})
+	m := NewVRC6a(base)
+	expansion, ok := m.(apu.ExpansionAudio)
+	assert.True(t, ok)
+	assert.Equal(t, float32(0), expansion.AudioOutput())
+
+	m.Write(0x9000, 0b1000_1010) // pulse 1: digital mode, volume 10
+	m.Write(0x9002, 0b1000_0000) // enable
+	assert.Equal(t, float32(10), expansion.AudioOutput())
+
+	m.Write(0x9000, 0b0000_1111) // 1/16 duty cycle, volume 15
+	m.Write(0x9001, 1)           // period 1
+	var high int
+	for i := 0; i < 16*2; i++ {
+		m.Step()
+		if expansion.AudioOutput() > 0 {
+			high++
+		}
+	}
+	assert.Equal(t, 2, high)
+
+	m.Write(0x9002, 0) // disable pulse 1
+	m.Write(0xB000, 8) // saw rate 8
+	m.Write(0xB002, 0b1000_0000)
+	var outputs []float32
+	for i := 0; i < 14; i++ {
+		m.Step()
+		outputs = append(outputs, expansion.AudioOutput())
+	}
+	assert.Equal(t, []float32{0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 0}, outputs)
+}
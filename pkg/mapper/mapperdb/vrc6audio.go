@@ -0,0 +1,166 @@
+package mapperdb
+
+// vrc6Pulse implements a VRC6 pulse channel with 8 duty cycles and a 4 bit volume.
+type vrc6Pulse struct {
+	enabled    bool
+	digital    bool // ignores the duty cycle and outputs the volume constantly
+	duty       uint8
+	volume     uint8
+	period     uint16
+	divider    uint16
+	dutyCycles uint8
+}
+
+// vrc6Saw implements the VRC6 sawtooth channel, an accumulator that gets increased by
+// the rate on every second clock and is reset after 7 additions.
+type vrc6Saw struct {
+	enabled     bool
+	rate        uint8
+	period      uint16
+	divider     uint16
+	clocks      uint8
+	accumulator uint8
+}
+
+// vrc6Audio implements the VRC6 expansion sound chip with two pulse and one sawtooth channel.
+type vrc6Audio struct {
+	pulse1 vrc6Pulse
+	pulse2 vrc6Pulse
+	saw    vrc6Saw
+
+	halted         bool
+	frequencyShift uint16
+}
+
+// setRegister sets a sound register, the register is one of $9000-$B002 with the
+// register select bits already decoded.
+func (a *vrc6Audio) setRegister(base, register uint16, value uint8) {
+	switch base {
+	case 0x9000:
+		if register == 3 {
+			a.setFrequencyControl(value)
+			return
+		}
+		a.pulse1.setRegister(register, value)
+	case 0xA000:
+		a.pulse2.setRegister(register, value)
+	case 0xB000:
+		a.saw.setRegister(register, value)
+	}
+}
+
+func (a *vrc6Audio) setFrequencyControl(value uint8) {
+	a.halted = value&0b0000_0001 != 0
+
+	switch {
+	case value&0b0000_0100 != 0:
+		a.frequencyShift = 8
+	case value&0b0000_0010 != 0:
+		a.frequencyShift = 4
+	default:
+		a.frequencyShift = 0
+	}
+}
+
+// step clocks all channels for one CPU cycle.
+func (a *vrc6Audio) step() {
+	if a.halted {
+		return
+	}
+	a.pulse1.step(a.frequencyShift)
+	a.pulse2.step(a.frequencyShift)
+	a.saw.step(a.frequencyShift)
+}
+
+// output returns the sum of all channel outputs in the range of 0-61.
+// A VRC6 pulse channel at full volume is about as loud as a 2A03 pulse channel
+// at full volume, so the output does not need to be scaled.
+func (a *vrc6Audio) output() float32 {
+	return float32(a.pulse1.output()) + float32(a.pulse2.output()) + float32(a.saw.output())
+}
+
+func (p *vrc6Pulse) setRegister(register uint16, value uint8) {
+	switch register {
+	case 0:
+		p.digital = value&0b1000_0000 != 0
+		p.duty = (value >> 4) & 0b0000_0111
+		p.volume = value & 0b0000_1111
+	case 1:
+		p.period = p.period&0x0F00 | uint16(value)
+	case 2:
+		p.period = p.period&0x00FF | uint16(value&0b0000_1111)<<8
+		p.enabled = value&0b1000_0000 != 0
+		if !p.enabled {
+			p.dutyCycles = 15
+		}
+	}
+}
+
+func (p *vrc6Pulse) step(frequencyShift uint16) {
+	if !p.enabled {
+		return
+	}
+
+	if p.divider > 0 {
+		p.divider--
+		return
+	}
+	p.divider = p.period >> frequencyShift
+
+	if p.dutyCycles == 0 {
+		p.dutyCycles = 15
+	} else {
+		p.dutyCycles--
+	}
+}
+
+func (p *vrc6Pulse) output() uint8 {
+	if !p.enabled {
+		return 0
+	}
+	if p.digital || p.dutyCycles <= p.duty {
+		return p.volume
+	}
+	return 0
+}
+
+func (s *vrc6Saw) setRegister(register uint16, value uint8) {
+	switch register {
+	case 0:
+		s.rate = value & 0b0011_1111
+	case 1:
+		s.period = s.period&0x0F00 | uint16(value)
+	case 2:
+		s.period = s.period&0x00FF | uint16(value&0b0000_1111)<<8
+		s.enabled = value&0b1000_0000 != 0
+		if !s.enabled {
+			s.clocks = 0
+			s.accumulator = 0
+		}
+	}
+}
+
+func (s *vrc6Saw) step(frequencyShift uint16) {
+	if !s.enabled {
+		return
+	}
+
+	if s.divider > 0 {
+		s.divider--
+		return
+	}
+	s.divider = s.period >> frequencyShift
+
+	s.clocks++
+	switch {
+	case s.clocks == 14:
+		s.clocks = 0
+		s.accumulator = 0
+	case s.clocks&1 == 0:
+		s.accumulator += s.rate
+	}
+}
+
+func (s *vrc6Saw) output() uint8 {
+	return s.accumulator >> 3
+}
@@ -0,0 +1,88 @@
+package mapperdb
+
+import "github.com/retroenv/nesgo/pkg/mapper/mapperbase"
+
+const (
+	vrcIrqPrescalerReload = 341 // PPU cycles per scanline
+	vrcIrqPrescalerStep   = 3   // PPU cycles per CPU cycle
+)
+
+// vrcIrq implements the IRQ counter that is shared by the Konami VRC4, VRC6 and VRC7 mappers.
+// The 8 bit counter is clocked either every CPU cycle or every scanline, which is
+// approximated by a prescaler that is decremented by 3 every CPU cycle.
+type vrcIrq struct {
+	base Base
+
+	latch     uint8
+	counter   uint8
+	prescaler int
+
+	enabled        bool
+	enableAfterAck bool
+	cycleMode      bool
+}
+
+func newVrcIrq(base Base) *vrcIrq {
+	return &vrcIrq{
+		base:      base,
+		prescaler: vrcIrqPrescalerReload,
+	}
+}
+
+// setLatch sets the reload value of the counter.
+func (i *vrcIrq) setLatch(value uint8) {
+	i.latch = value
+}
+
+// setLatchLow sets the low 4 bits of the reload value, used by VRC4.
+func (i *vrcIrq) setLatchLow(value uint8) {
+	i.latch = i.latch&0xF0 | value&0x0F
+}
+
+// setLatchHigh sets the high 4 bits of the reload value, used by VRC4.
+func (i *vrcIrq) setLatchHigh(value uint8) {
+	i.latch = i.latch&0x0F | value<<4
+}
+
+// setControl sets the IRQ control register and reloads the counter if the IRQ is enabled.
+func (i *vrcIrq) setControl(value uint8) {
+	i.enableAfterAck = value&0b0000_0001 != 0
+	i.enabled = value&0b0000_0010 != 0
+	i.cycleMode = value&0b0000_0100 != 0
+
+	if i.enabled {
+		i.counter = i.latch
+		i.prescaler = vrcIrqPrescalerReload
+	}
+	i.base.AcknowledgeIrq(mapperbase.IrqCounter)
+}
+
+// acknowledge acknowledges a pending IRQ and restores the enabled state that was set
+// by the last write to the control register.
+func (i *vrcIrq) acknowledge() {
+	i.base.AcknowledgeIrq(mapperbase.IrqCounter)
+	i.enabled = i.enableAfterAck
+}
+
+// step is called for every CPU cycle.
+func (i *vrcIrq) step() {
+	if !i.enabled {
+		return
+	}
+
+	if !i.cycleMode {
+		i.prescaler -= vrcIrqPrescalerStep
+		if i.prescaler > 0 {
+			return
+		}
+		i.prescaler += vrcIrqPrescalerReload
+	}
+
+	if i.counter != 0xFF {
+		i.counter++
+		return
+	}
+
+	i.counter = i.latch
+	i.base.TriggerIrq(mapperbase.IrqCounter)
+}
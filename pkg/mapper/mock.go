@@ -9,12 +9,15 @@ import (
 // MockMapper implements a mock mapper for use in tests.
 type MockMapper struct {
 	*memory.Memory
+
+	bus *bus.Bus
 }
 
 // NewMockMapper returns a new mock mapper.
 func NewMockMapper(bus *bus.Bus) bus.Mapper {
 	return &MockMapper{
 		Memory: memory.New(bus),
+		bus:    bus,
 	}
 }
 
@@ -27,3 +30,34 @@ func (m *MockMapper) State() bus.MapperState {
 func (m *MockMapper) MirrorMode() cartridge.MirrorMode {
 	return cartridge.MirrorHorizontal
 }
+
+// PrgRAM returns the PRG RAM buffer, the mock mapper has no PRG RAM.
+func (m *MockMapper) PrgRAM() []byte {
+	return nil
+}
+
+// BatteryRAM returns the battery backed PRG RAM, the mock mapper has no PRG RAM.
+func (m *MockMapper) BatteryRAM() []byte {
+	return nil
+}
+
+// Step is called for every CPU cycle, the mock mapper has no cycle based logic.
+func (m *MockMapper) Step() {
+}
+
+// ReadPPU reads a byte from a pattern table or nametable address of the PPU address space.
+func (m *MockMapper) ReadPPU(address uint16) uint8 {
+	if address < 0x2000 {
+		return m.Read(address)
+	}
+	return m.bus.NameTable.Read(address)
+}
+
+// WritePPU writes a byte to a pattern table or nametable address of the PPU address space.
+func (m *MockMapper) WritePPU(address uint16, value uint8) {
+	if address < 0x2000 {
+		m.Write(address, value)
+		return
+	}
+	m.bus.NameTable.Write(address, value)
+}
@@ -15,6 +15,8 @@ type Memory struct {
 	bus *bus.Bus
 	ram *RAM
 
+	cartridgeReadHook func(address uint16, value uint8) uint8
+
 	// point to X/Y for comparison of indirect register
 	// parameters in unit tests.
 	x, globalX *uint8
@@ -39,6 +41,19 @@ func (m *Memory) LinkRegisters(x *uint8, y *uint8, globalX *uint8, globalY *uint
 	m.globalY = globalY
 }
 
+// SetCartridgeReadHook sets a hook that gets called for every read from the cartridge
+// address space with the value read from the mapper. The value returned by the hook
+// is passed on instead, which allows cheat devices like the Game Genie to patch the
+// program. It has to be set before the emulation starts.
+func (m *Memory) SetCartridgeReadHook(hook func(address uint16, value uint8) uint8) {
+	m.cartridgeReadHook = hook
+}
+
+// Reset resets the content of the RAM.
+func (m *Memory) Reset() {
+	m.ram.Reset()
+}
+
 // Write a byte to a memory address.
 func (m *Memory) Write(address uint16, value byte) {
 	switch {
@@ -49,13 +64,13 @@ func (m *Memory) Write(address uint16, value byte) {
 		m.bus.PPU.Write(address, value)
 
 	case address == controller.JOYPAD1:
-		m.bus.Controller1.SetStrobeMode(value)
-		m.bus.Controller2.SetStrobeMode(value)
+		m.bus.Port1.SetStrobeMode(value)
+		m.bus.Port2.SetStrobeMode(value)
 
-	case address >= 0x4000 && address <= 0x4020:
+	case address >= 0x4000 && address < 0x4020:
 		return // TODO apu support
 
-	case address >= 0x5000: // mappers like GTROM allow writes starting 0x5000
+	case address >= 0x4020: // the FDS has registers starting at 0x4020, GTROM at 0x5000
 		m.bus.Mapper.Write(address, value)
 
 	default:
@@ -73,16 +88,20 @@ func (m *Memory) Read(address uint16) byte {
 		return m.bus.PPU.Read(address)
 
 	case address == controller.JOYPAD1:
-		return m.bus.Controller1.Read()
+		return m.bus.Port1.Read()
 
 	case address == controller.JOYPAD2:
-		return m.bus.Controller2.Read()
+		return m.bus.Port2.Read()
 
-	case address >= 0x4000 && address <= 0x4020:
+	case address >= 0x4000 && address < 0x4020:
 		return 0xff // TODO apu support
 
-	case address >= 0x5000: // GTROM allow writes starting 0x5000, MMC1 has RAM starting at 0x6000
-		return m.bus.Mapper.Read(address)
+	case address >= 0x4020: // the FDS has registers starting at 0x4020, MMC1 has RAM starting at 0x6000
+		value := m.bus.Mapper.Read(address)
+		if m.cartridgeReadHook != nil {
+			value = m.cartridgeReadHook(address, value)
+		}
+		return value
 
 	default:
 		panic(fmt.Sprintf("unhandled memory read at address: 0x%04X", address))
@@ -0,0 +1,199 @@
+//go:build !nesgo
+
+package movie
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+)
+
+// ramChecksumKey is a custom header key that is ignored by other emulators.
+const ramChecksumKey = "nesgoRamChecksum"
+
+// gamepadButtons defines the button order of a gamepad in a frame line.
+var gamepadButtons = [8]struct {
+	char   byte
+	button controller.Button
+}{
+	{'R', controller.Right},
+	{'L', controller.Left},
+	{'D', controller.Down},
+	{'U', controller.Up},
+	{'T', controller.Start},
+	{'S', controller.Select},
+	{'B', controller.B},
+	{'A', controller.A},
+}
+
+// Read reads a movie in fm2 format.
+func Read(reader io.Reader) (*Movie, error) {
+	m := &Movie{}
+	scanner := bufio.NewScanner(reader)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		var err error
+		if line[0] == '|' {
+			err = m.parseFrame(line)
+		} else {
+			err = m.parseHeader(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading movie: %w", err)
+	}
+	return m, nil
+}
+
+func (m *Movie) parseHeader(line string) error {
+	key, value, _ := strings.Cut(line, " ")
+
+	var err error
+	switch key {
+	case "version":
+		if value != strconv.Itoa(formatVersion) {
+			return fmt.Errorf("%w: version %s", ErrUnsupported, value)
+		}
+	case "emuVersion":
+		m.EmuVersion, err = strconv.Atoi(value)
+	case "rerecordCount":
+		m.RerecordCount, err = strconv.Atoi(value)
+	case "palFlag":
+		m.PAL = value == "1"
+	case "romFilename":
+		m.ROMFilename = value
+	case "romChecksum":
+		m.ROMChecksum = value
+	case "guid":
+		m.GUID = value
+	case "comment":
+		m.Comments = append(m.Comments, value)
+	case "port0", "port1", "port2":
+		port := int(key[4] - '0')
+		m.Ports[port], err = strconv.Atoi(value)
+	case "binary", "fourscore", "FDS":
+		if value != "0" {
+			return fmt.Errorf("%w: %s", ErrUnsupported, key)
+		}
+	case ramChecksumKey:
+		m.RAMChecksum = value
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: header %s: %s", ErrInvalidFormat, key, err.Error())
+	}
+	return nil
+}
+
+func (m *Movie) parseFrame(line string) error {
+	fields := strings.Split(line, "|")
+	if len(fields) < 5 {
+		return fmt.Errorf("%w: frame '%s'", ErrInvalidFormat, line)
+	}
+
+	commands, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("%w: frame commands '%s'", ErrInvalidFormat, fields[1])
+	}
+
+	frame := Frame{
+		Commands: byte(commands),
+	}
+	for port := 0; port < 2; port++ {
+		if m.Ports[port] != PortGamepad {
+			continue
+		}
+		frame.Buttons[port], err = parseGamepad(fields[port+2])
+		if err != nil {
+			return err
+		}
+	}
+
+	m.Frames = append(m.Frames, frame)
+	return nil
+}
+
+// parseGamepad parses the gamepad state of a frame line, every character
+// that is not a space or a dot marks a pressed button.
+func parseGamepad(s string) (controller.Button, error) {
+	if len(s) != len(gamepadButtons) {
+		return 0, fmt.Errorf("%w: gamepad state '%s'", ErrInvalidFormat, s)
+	}
+
+	var buttons controller.Button
+	for i, b := range gamepadButtons {
+		if s[i] != '.' && s[i] != ' ' {
+			buttons |= b.button
+		}
+	}
+	return buttons, nil
+}
+
+func formatGamepad(buttons controller.Button) string {
+	b := make([]byte, len(gamepadButtons))
+	for i, button := range gamepadButtons {
+		if buttons&button.button != 0 {
+			b[i] = button.char
+		} else {
+			b[i] = '.'
+		}
+	}
+	return string(b)
+}
+
+// Write writes the movie in fm2 format.
+func (m *Movie) Write(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+
+	palFlag := 0
+	if m.PAL {
+		palFlag = 1
+	}
+
+	fmt.Fprintf(w, "version %d\n", formatVersion)
+	fmt.Fprintf(w, "emuVersion %d\n", m.EmuVersion)
+	fmt.Fprintf(w, "rerecordCount %d\n", m.RerecordCount)
+	fmt.Fprintf(w, "palFlag %d\n", palFlag)
+	fmt.Fprintf(w, "romFilename %s\n", m.ROMFilename)
+	fmt.Fprintf(w, "romChecksum %s\n", m.ROMChecksum)
+	fmt.Fprintf(w, "guid %s\n", m.GUID)
+	fmt.Fprintf(w, "fourscore 0\n")
+	for i, port := range m.Ports {
+		fmt.Fprintf(w, "port%d %d\n", i, port)
+	}
+	for _, comment := range m.Comments {
+		fmt.Fprintf(w, "comment %s\n", comment)
+	}
+	if m.RAMChecksum != "" {
+		fmt.Fprintf(w, "%s %s\n", ramChecksumKey, m.RAMChecksum)
+	}
+
+	for _, frame := range m.Frames {
+		fmt.Fprintf(w, "|%d|", frame.Commands)
+		for port := 0; port < 2; port++ {
+			if m.Ports[port] == PortGamepad {
+				_, _ = w.WriteString(formatGamepad(frame.Buttons[port]))
+			}
+			_ = w.WriteByte('|')
+		}
+		_, _ = w.WriteString("|\n")
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing movie: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+//go:build !nesgo
+
+// Package movie implements recording and replaying of controller input in the
+// FCEUX .fm2 movie text format.
+package movie
+
+import (
+	"crypto/md5" // nolint:gosec // md5 is used by the fm2 format for checksums
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+)
+
+const (
+	formatVersion  = 3
+	checksumPrefix = "base64:"
+)
+
+// Port device types of the fm2 format.
+const (
+	PortNone    = 0
+	PortGamepad = 1
+	PortZapper  = 2
+)
+
+// Commands of a movie frame.
+const (
+	CommandSoftReset = 1 << 0
+	CommandHardReset = 1 << 1
+)
+
+var (
+	// ErrUnsupported is returned for movie features that are not supported.
+	ErrUnsupported = errors.New("unsupported movie feature")
+	// ErrInvalidFormat is returned for movie files that can not be parsed.
+	ErrInvalidFormat = errors.New("invalid movie format")
+	// ErrChecksumMismatch is returned when a ROM or RAM checksum does not match.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// Frame contains the input of a single frame.
+type Frame struct {
+	Commands byte
+	Buttons  [2]controller.Button // buttons of the controllers in port 1 and 2
+}
+
+// Movie contains the header and the input frames of a movie.
+type Movie struct {
+	EmuVersion    int
+	RerecordCount int
+	PAL           bool
+	ROMFilename   string
+	ROMChecksum   string
+	GUID          string
+	Comments      []string
+	Ports         [3]int
+
+	// RAMChecksum is the checksum of the internal RAM after the last frame.
+	// It is not part of the fm2 format and gets stored in a custom header field.
+	RAMChecksum string
+
+	Frames []Frame
+}
+
+// New returns a new movie for the given ROM, using gamepads in both ports.
+func New(romFilename string, prg, chr []byte) *Movie {
+	return &Movie{
+		ROMFilename: romFilename,
+		ROMChecksum: Checksum(prg, chr),
+		GUID:        newGUID(),
+		Ports:       [3]int{PortGamepad, PortGamepad, PortNone},
+	}
+}
+
+// Checksum returns the checksum of the given data in the format used by fm2 files.
+func Checksum(data ...[]byte) string {
+	hash := md5.New() // nolint:gosec
+	for _, d := range data {
+		_, _ = hash.Write(d)
+	}
+	return checksumPrefix + base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// VerifyROM verifies that the movie was recorded using the given ROM data.
+// Movies without ROM checksum are accepted for all ROMs.
+func (m *Movie) VerifyROM(prg, chr []byte) error {
+	if m.ROMChecksum == "" {
+		return nil
+	}
+	if checksum := Checksum(prg, chr); checksum != m.ROMChecksum {
+		return fmt.Errorf("%w: ROM checksum is %s, movie expects %s", ErrChecksumMismatch, checksum, m.ROMChecksum)
+	}
+	return nil
+}
+
+// VerifyRAM verifies that the RAM matches the RAM checksum of the movie.
+// Movies without RAM checksum are accepted for all RAM states.
+func (m *Movie) VerifyRAM(ram []byte) error {
+	if m.RAMChecksum == "" {
+		return nil
+	}
+	if checksum := Checksum(ram); checksum != m.RAMChecksum {
+		return fmt.Errorf("%w: RAM checksum is %s, movie expects %s", ErrChecksumMismatch, checksum, m.RAMChecksum)
+	}
+	return nil
+}
+
+// newGUID returns a random GUID in the format that FCEUX uses.
+func newGUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,80 @@
+package movie
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+const testMovie = `version 3
+emuVersion 22020
+rerecordCount 5
+palFlag 0
+romFilename test
+romChecksum base64:1B2M2Y8AsgTpgAmY7PhCfg==
+guid 01234567-89AB-CDEF-0123-456789ABCDEF
+fourscore 0
+port0 1
+port1 1
+port2 0
+comment author tester
+|0|........|........||
+|0|R......A|........||
+|2|...U....|.L....B.||
+`
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	m, err := Read(strings.NewReader(testMovie))
+	assert.NoError(t, err)
+	assert.Equal(t, 22020, m.EmuVersion)
+	assert.Equal(t, 5, m.RerecordCount)
+	assert.Equal(t, "test", m.ROMFilename)
+	assert.Equal(t, []string{"author tester"}, m.Comments)
+	assert.Equal(t, 3, len(m.Frames))
+
+	assert.Equal(t, controller.Right|controller.A, m.Frames[1].Buttons[0])
+	assert.Equal(t, CommandHardReset, m.Frames[2].Commands)
+	assert.Equal(t, controller.Up, m.Frames[2].Buttons[0])
+	assert.Equal(t, controller.Left|controller.B, m.Frames[2].Buttons[1])
+
+	assert.NoError(t, m.VerifyROM(nil, nil))
+	assert.True(t, errors.Is(m.VerifyROM([]byte{1}, nil), ErrChecksumMismatch))
+}
+
+func TestWriteRead(t *testing.T) {
+	t.Parallel()
+
+	m := New("game.nes", []byte{1, 2}, []byte{3})
+	m.RAMChecksum = Checksum(make([]byte, 0x800))
+	m.Frames = []Frame{
+		{Buttons: [2]controller.Button{controller.Start, 0}},
+		{Buttons: [2]controller.Button{controller.A | controller.Down, controller.Select}},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, m.Write(buf))
+	assert.True(t, strings.Contains(buf.String(), "|0|..D....A|.....S..||\n"))
+
+	loaded, err := Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, m, loaded)
+	assert.NoError(t, loaded.VerifyROM([]byte{1, 2}, []byte{3}))
+	assert.NoError(t, loaded.VerifyRAM(make([]byte, 0x800)))
+	assert.True(t, errors.Is(loaded.VerifyRAM(make([]byte, 0x400)), ErrChecksumMismatch))
+}
+
+func TestReadUnsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := Read(strings.NewReader("version 3\nfourscore 1\n"))
+	assert.True(t, errors.Is(err, ErrUnsupported))
+
+	_, err = Read(strings.NewReader("version 3\nport0 1\n|0|ABC|\n"))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
@@ -0,0 +1,21 @@
+//go:build !nesgo
+
+package nes
+
+import "github.com/retroenv/nesgo/pkg/apu"
+
+// connectExpansionAudio adds the expansion sound chip of the mapper to the mixer,
+// if the mapper has one.
+func (sys *System) connectExpansionAudio() {
+	sys.mixer.ClearExpansions()
+	if expansion, ok := sys.Bus.Mapper.(apu.ExpansionAudio); ok {
+		sys.mixer.AddExpansion(expansion)
+	}
+}
+
+// AudioSample returns the current mixed audio output level. The 2A03 channels are
+// not emulated yet, only the channels of an expansion sound chip of the mapper
+// contribute to the output.
+func (sys *System) AudioSample() float32 {
+	return sys.mixer.Sample(apu.ChannelOutputs{})
+}
@@ -0,0 +1,34 @@
+package nes
+
+import (
+	"testing"
+
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testProgramVRC6 enables the first VRC6 pulse channel in digital mode at full volume.
+var testProgramVRC6 = []byte{
+	0xA9, 0x8F, // lda #$8F
+	0x8D, 0x00, 0x90, // sta $9000
+	0xA9, 0x80, // lda #$80
+	0x8D, 0x02, 0x90, // sta $9002
+	0x4C, 0x0A, 0x80, // loop: jmp loop
+}
+
+func TestSystemAudioSampleExpansion(t *testing.T) {
+	cart := &cartridge.Cartridge{
+		PRG:    make([]byte, 0x8000),
+		CHR:    make([]byte, 0x2000),
+		Mapper: 24,
+	}
+	copy(cart.PRG, testProgramVRC6)
+	copy(cart.PRG[len(cart.PRG)-6:], []byte{0x0A, 0x80, 0x00, 0x80, 0x0A, 0x80})
+
+	r := NewRunner(cart)
+	assert.Equal(t, float32(0), r.System().AudioSample())
+
+	assert.NoError(t, r.RunUntil(0x800A))
+	sample := r.System().AudioSample()
+	assert.True(t, sample > 0.14 && sample < 0.16)
+}
@@ -0,0 +1,150 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// batterySaveInterval is the amount of frames after which a changed battery
+// backed PRG RAM gets written to the save file.
+var batterySaveInterval uint64 = 300
+
+// ErrNoBatteryRAM is returned when accessing the save data of a cartridge
+// without battery backed PRG RAM.
+var ErrNoBatteryRAM = errors.New("cartridge has no battery backed PRG RAM")
+
+// batterySave persists the battery backed PRG RAM in a save file.
+type batterySave struct {
+	fileName string
+	saved    []byte // RAM content of the last write
+}
+
+// setupBatterySave loads the save file into the PRG RAM of a cartridge with
+// a battery and adds a frame hook that writes changes of the RAM periodically.
+func (sys *System) setupBatterySave(fileName string) {
+	if fileName == "" || !sys.hasBatteryRAM() {
+		return
+	}
+
+	ram := sys.Bus.Mapper.BatteryRAM()
+	data, err := os.ReadFile(fileName)
+	switch {
+	case err == nil:
+		copy(ram, data)
+	case !errors.Is(err, os.ErrNotExist):
+		panic(fmt.Errorf("reading save file '%s': %w", fileName, err))
+	}
+
+	sys.battery = &batterySave{
+		fileName: fileName,
+		saved:    append([]byte(nil), ram...),
+	}
+
+	sys.AddFrameHook(func(frame uint64) {
+		if frame == 0 || frame%batterySaveInterval != 0 {
+			return
+		}
+		if err := sys.WriteBatterySave(); err != nil {
+			fmt.Printf("Writing save file failed: %s\n", err.Error())
+		}
+	})
+}
+
+// disableBatterySave stops writing the save file and clears the battery backed
+// PRG RAM. This is used for movies, which are recorded and played from power
+// on without any save data to be deterministic.
+func (sys *System) disableBatterySave() {
+	sys.battery = nil
+	ram := sys.Bus.Mapper.BatteryRAM()
+	for i := range ram {
+		ram[i] = 0
+	}
+}
+
+func (sys *System) hasBatteryRAM() bool {
+	return sys.Bus.Cartridge.Battery != 0 && len(sys.Bus.Mapper.BatteryRAM()) > 0
+}
+
+// WriteBatterySave writes the battery backed PRG RAM to the save file if it
+// changed since the last write. The file is replaced atomically to not lose
+// the save data if the emulator gets terminated while writing.
+func (sys *System) WriteBatterySave() error {
+	if sys.battery == nil {
+		return nil
+	}
+
+	ram := sys.Bus.Mapper.BatteryRAM()
+	if bytes.Equal(ram, sys.battery.saved) {
+		return nil
+	}
+
+	data := append([]byte(nil), ram...)
+	if err := writeFileAtomic(sys.battery.fileName, data); err != nil {
+		return err
+	}
+	sys.battery.saved = data
+	return nil
+}
+
+// ExportSave returns a copy of the battery backed PRG RAM. It can be called
+// while the emulation is running, the RAM is read between two frames.
+func (sys *System) ExportSave() ([]byte, error) {
+	sys.emulationMu.Lock()
+	defer sys.emulationMu.Unlock()
+
+	if !sys.hasBatteryRAM() {
+		return nil, ErrNoBatteryRAM
+	}
+	return append([]byte(nil), sys.Bus.Mapper.BatteryRAM()...), nil
+}
+
+// ImportSave overwrites the battery backed PRG RAM with the given data, data
+// that exceeds the RAM size is ignored. The game should be reset afterwards
+// to pick up the new save data. It can be called while the emulation is
+// running, the RAM is written between two frames.
+func (sys *System) ImportSave(data []byte) error {
+	sys.emulationMu.Lock()
+	defer sys.emulationMu.Unlock()
+
+	if !sys.hasBatteryRAM() {
+		return ErrNoBatteryRAM
+	}
+
+	ram := sys.Bus.Mapper.BatteryRAM()
+	n := copy(ram, data)
+	for i := n; i < len(ram); i++ {
+		ram[i] = 0
+	}
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory
+// and renames it to the target file name.
+func writeFileAtomic(fileName string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	tempName := file.Name()
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempName)
+		return fmt.Errorf("writing file '%s': %w", tempName, err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tempName)
+		return fmt.Errorf("closing file '%s': %w", tempName, err)
+	}
+
+	if err := os.Rename(tempName, fileName); err != nil {
+		_ = os.Remove(tempName)
+		return fmt.Errorf("renaming file '%s': %w", tempName, err)
+	}
+	return nil
+}
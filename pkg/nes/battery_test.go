@@ -0,0 +1,100 @@
+package nes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testProgramBattery writes $42 to $6000 and increments $6001 of the PRG RAM.
+var testProgramBattery = []byte{
+	0xA9, 0x42, // lda #$42
+	0x8D, 0x00, 0x60, // sta $6000
+	0xEE, 0x01, 0x60, // inc $6001
+	0x4C, 0x08, 0x80, // loop: jmp loop
+}
+
+func testBatteryCartridge() *cartridge.Cartridge {
+	cart := testCartridgeWithProgram(testProgramBattery)
+	cart.Mapper = 1
+	cart.Battery = 1
+	return cart
+}
+
+func TestBatterySave(t *testing.T) {
+	interval := batterySaveInterval
+	batterySaveInterval = 5
+	defer func() {
+		batterySaveInterval = interval
+	}()
+
+	fileName := filepath.Join(t.TempDir(), "game.sav")
+	assert.NoError(t, os.WriteFile(fileName, []byte{0x00, 0x05}, 0o600))
+
+	r := NewRunner(testBatteryCartridge(), WithSaveFile(fileName))
+	sys := r.System()
+	assert.Equal(t, 5, r.ReadMemory(0x6001))
+
+	assert.NoError(t, r.RunFrames(1))
+	assert.Equal(t, 6, r.ReadMemory(0x6001))
+	assert.NoError(t, sys.WriteBatterySave())
+
+	data, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x2000, len(data)) // only the mapped 8K window of the MMC1 RAM
+	assert.Equal(t, []byte{0x42, 0x06}, data[:2])
+
+	// battery backed RAM survives a power cycle
+	sys.PowerCycle()
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 6, r.ReadMemory(0x6001))
+
+	// changes are written periodically
+	assert.NoError(t, r.RunFrames(int(batterySaveInterval)+1))
+	data, err = os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, data[1])
+}
+
+func TestBatteryImportExport(t *testing.T) {
+	r := NewRunner(testBatteryCartridge())
+	sys := r.System()
+
+	assert.NoError(t, sys.ImportSave([]byte{1, 2, 3}))
+	assert.Equal(t, 2, r.ReadMemory(0x6001))
+
+	data, err := sys.ExportSave()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 0}, data[:4])
+
+	r = NewRunner(testCartridge())
+	_, err = r.System().ExportSave()
+	assert.True(t, errors.Is(err, ErrNoBatteryRAM))
+	assert.True(t, errors.Is(r.System().ImportSave(data), ErrNoBatteryRAM))
+}
+
+func TestBatteryExportWhileRunning(t *testing.T) {
+	r := NewRunner(testBatteryCartridge(), WithTurbo())
+	sys := r.System()
+	sys.startPacing()
+	go sys.runEmulatorSteps(NewOptions(WithStopAtFrame(3)))
+
+	for {
+		select {
+		case <-sys.emulationDone:
+			data, err := sys.ExportSave()
+			assert.NoError(t, err)
+			assert.Equal(t, 0x42, data[0])
+			return
+		default:
+		}
+
+		_, err := sys.ExportSave()
+		assert.NoError(t, err)
+		assert.NoError(t, sys.ImportSave([]byte{0x42}))
+	}
+}
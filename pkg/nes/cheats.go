@@ -0,0 +1,73 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"fmt"
+
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+)
+
+const (
+	prgRAMAddress = 0x6000
+	prgRAMSize    = 0x2000 // size of the PRG RAM window at $6000-$7FFF
+)
+
+// setupCheats adds the Game Genie codes and raw cheats of the options to the cheat
+// lists, installs the lists as hook for all cartridge reads and applies the freeze
+// cheats at the end of every frame.
+func (sys *System) setupCheats(opts *Options) {
+	sys.cheats = gamegenie.NewList()
+	for _, code := range opts.cheats {
+		if err := sys.cheats.Add(code); err != nil {
+			panic(fmt.Errorf("adding Game Genie code: %w", err))
+		}
+	}
+	sys.rawCheats = cheat.NewList()
+	for _, c := range opts.rawCheats {
+		if err := sys.rawCheats.Add(c); err != nil {
+			panic(fmt.Errorf("adding cheat: %w", err))
+		}
+	}
+
+	sys.Bus.Memory.SetCartridgeReadHook(func(address uint16, value uint8) uint8 {
+		value = sys.cheats.Apply(address, value)
+		return sys.rawCheats.Apply(address, value)
+	})
+	sys.AddFrameHook(func(frame uint64) {
+		sys.rawCheats.Freeze(sys.Bus.Memory)
+	})
+}
+
+// Cheats returns the list of Game Genie codes, codes can be added and toggled
+// while the emulation is running.
+func (sys *System) Cheats() *gamegenie.List {
+	return sys.cheats
+}
+
+// RawCheats returns the list of RAM freeze and substitute cheats, cheats can be
+// added and toggled while the emulation is running.
+func (sys *System) RawCheats() *cheat.List {
+	return sys.rawCheats
+}
+
+// MemorySnapshot returns a copy of the memory that can be searched for game
+// variables, the internal RAM and the PRG RAM that is currently mapped at
+// $6000-$7FFF if the cartridge has PRG RAM. It can be called while the
+// emulation is running, the memory is read between two frames.
+func (sys *System) MemorySnapshot() cheat.Snapshot {
+	sys.emulationMu.Lock()
+	defer sys.emulationMu.Unlock()
+
+	snapshot := cheat.Snapshot{
+		RAM: sys.ram(),
+	}
+	if len(sys.Bus.Mapper.PrgRAM()) > 0 {
+		snapshot.PrgRAM = make([]byte, prgRAMSize)
+		for i := range snapshot.PrgRAM {
+			snapshot.PrgRAM[i] = sys.Bus.Memory.Read(uint16(prgRAMAddress + i))
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,66 @@
+package nes
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testProgramCheat copies the value at $8010 to $00 and the value at $8011 to $01.
+var testProgramCheat = []byte{
+	0xAD, 0x10, 0x80, // lda $8010
+	0x85, 0x00, // sta $00
+	0xAD, 0x11, 0x80, // lda $8011
+	0x85, 0x01, // sta $01
+	0x4C, 0x0A, 0x80, // loop: jmp loop
+	0x00, 0x00, 0x00,
+	0x11, 0x22, // data
+}
+
+func TestSystemCheats(t *testing.T) {
+	code1, err := gamegenie.Encode(gamegenie.Patch{Address: 0x8010, Data: 0x42})
+	assert.NoError(t, err)
+	code2, err := gamegenie.Encode(gamegenie.Patch{Address: 0x8011, Data: 0x43, Compare: 0x23, HasCompare: true})
+	assert.NoError(t, err)
+
+	r := NewRunner(testCartridgeWithProgram(testProgramCheat), WithCheats(code1, code2))
+	assert.NoError(t, r.RunUntil(0x800A))
+	assert.Equal(t, 0x42, r.ReadMemory(0x00))
+	assert.Equal(t, 0x22, r.ReadMemory(0x01)) // compare value does not match
+
+	_, err = r.System().Cheats().Toggle(code1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x11, r.ReadMemory(0x8010))
+}
+
+func TestSystemRawCheats(t *testing.T) {
+	cheats := []cheat.Cheat{
+		{Address: 0x0010, Value: 0x55, Enabled: true},
+		{Address: 0x8011, Value: 0x66, Substitute: true, Enabled: true},
+	}
+	r := NewRunner(testCartridgeWithProgram(testProgramCheat), WithRawCheats(cheats...))
+
+	assert.NoError(t, r.RunUntil(0x800A))
+	assert.Equal(t, 0x11, r.ReadMemory(0x00))
+	assert.Equal(t, 0x66, r.ReadMemory(0x01))
+
+	assert.NoError(t, r.RunFrames(1))
+	assert.Equal(t, 0x55, r.ReadMemory(0x10))
+}
+
+func TestSystemMemorySnapshot(t *testing.T) {
+	r := NewRunner(testBatteryCartridge())
+	assert.NoError(t, r.RunUntil(0x8008))
+
+	snapshot := r.System().MemorySnapshot()
+	assert.Equal(t, ramSize, len(snapshot.RAM))
+	assert.Equal(t, prgRAMSize, len(snapshot.PrgRAM)) // only the mapped 8K window of the MMC1 RAM
+	assert.Equal(t, []byte{0x42, 0x01}, snapshot.PrgRAM[:2])
+
+	r = NewRunner(testCartridgeWithProgram(testProgramCheat))
+	snapshot = r.System().MemorySnapshot()
+	assert.Equal(t, ramSize, len(snapshot.RAM))
+	assert.Equal(t, 0, len(snapshot.PrgRAM))
+}
@@ -0,0 +1,29 @@
+package nes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/cdl"
+	"github.com/retroenv/retrogolib/arch/nes/codedatalog"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestSystemCodeDataLog(t *testing.T) {
+	cart := testCartridgeWithProgram(testProgramCheat)
+	logger := cdl.New(len(cart.PRG), len(cart.CHR))
+
+	r := NewRunner(cart, WithCodeDataLog(logger))
+	assert.NoError(t, r.RunUntil(0x800A))
+
+	var buf bytes.Buffer
+	assert.NoError(t, logger.Write(&buf))
+	data := buf.Bytes()
+	assert.Equal(t, len(cart.PRG)+len(cart.CHR), len(data))
+
+	code := byte(codedatalog.Code)
+	assert.Equal(t, []byte{code, code, code, code, code}, data[0x00:0x05])
+	assert.Equal(t, 0, data[0x0A]) // jmp is not executed yet
+	assert.Equal(t, []byte{byte(codedatalog.Data), byte(codedatalog.Data)}, data[0x10:0x12])
+	assert.Equal(t, 0, data[0x0D])
+}
@@ -13,3 +13,9 @@ type Inline any
 // customized by placing a call to this function anywhere into the program
 // code.
 func VariableInit() {}
+
+// VBlankBudget declares that the calling function has to fit into the vertical
+// blank. This is intended to be called at the start of the NMI handler, nesgo
+// fails to compile the program if the estimated worst case cycles of the
+// function, including all called functions, exceed the NTSC vertical blank.
+func VBlankBudget(_ ...Inline) {}
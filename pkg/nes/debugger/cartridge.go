@@ -0,0 +1,40 @@
+//go:build !nesgo
+
+package debugger
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxSaveSize limits the size of imported save files.
+const maxSaveSize = 0x10000
+
+// cartridgeSave exports the battery backed PRG RAM on GET requests and
+// imports the request body into it on POST or PUT requests.
+func (d *Debugger) cartridgeSave(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		data, err := d.emulator.ExportSave()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="game.sav"`)
+		_, _ = w.Write(data)
+
+	case http.MethodPost, http.MethodPut:
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxSaveSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := d.emulator.ImportSave(data); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
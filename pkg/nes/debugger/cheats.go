@@ -0,0 +1,156 @@
+//go:build !nesgo
+
+package debugger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+)
+
+type cheatCode struct {
+	Code    string   `json:"code"`
+	Address hexWord  `json:"address"`
+	Data    hexByte  `json:"data"`
+	Compare *hexByte `json:"compare,omitempty"`
+	Enabled bool     `json:"enabled"`
+}
+
+type rawCheat struct {
+	Index      int      `json:"index"`
+	Name       string   `json:"name"`
+	Address    hexWord  `json:"address"`
+	Value      hexByte  `json:"value"`
+	Compare    *hexByte `json:"compare,omitempty"`
+	Substitute bool     `json:"substitute"`
+	Enabled    bool     `json:"enabled"`
+}
+
+// cheats lists all Game Genie codes on GET requests and adds the code passed
+// as code parameter on POST requests.
+func (d *Debugger) cheats(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		d.writeCheats(w)
+
+	case http.MethodPost:
+		if err := d.emulator.Cheats().Add(r.FormValue("code")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.writeCheats(w)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// cheatsToggle enables or disables the Game Genie code passed as code parameter.
+func (d *Debugger) cheatsToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, err := d.emulator.Cheats().Toggle(r.FormValue("code")); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	d.writeCheats(w)
+}
+
+func (d *Debugger) writeCheats(w http.ResponseWriter) {
+	codes := d.emulator.Cheats().Codes()
+	res := make([]cheatCode, 0, len(codes))
+
+	for _, code := range codes {
+		res = append(res, newCheatCode(code))
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func newCheatCode(code gamegenie.Code) cheatCode {
+	c := cheatCode{
+		Code:    code.Code,
+		Address: hexWord(code.Patch.Address),
+		Data:    hexByte(code.Patch.Data),
+		Enabled: code.Enabled,
+	}
+	if code.Patch.HasCompare {
+		compare := hexByte(code.Patch.Compare)
+		c.Compare = &compare
+	}
+	return c
+}
+
+// rawCheats lists all RAM freeze and substitute cheats on GET requests and adds the
+// cheat passed in .cht line format as cheat parameter on POST requests.
+func (d *Debugger) rawCheats(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		d.writeRawCheats(w)
+
+	case http.MethodPost:
+		c, err := cheat.Parse(r.FormValue("cheat"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := d.emulator.RawCheats().Add(c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.writeRawCheats(w)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// rawCheatsToggle enables or disables the raw cheat with the index passed as
+// index parameter.
+func (d *Debugger) rawCheatsToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	index, err := strconv.Atoi(r.FormValue("index"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := d.emulator.RawCheats().Toggle(index); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	d.writeRawCheats(w)
+}
+
+func (d *Debugger) writeRawCheats(w http.ResponseWriter) {
+	cheats := d.emulator.RawCheats().Cheats()
+	res := make([]rawCheat, 0, len(cheats))
+
+	for i, c := range cheats {
+		rc := rawCheat{
+			Index:      i,
+			Name:       c.Name,
+			Address:    hexWord(c.Address),
+			Value:      hexByte(c.Value),
+			Substitute: c.Substitute,
+			Enabled:    c.Enabled,
+		}
+		if c.HasCompare {
+			compare := hexByte(c.Compare)
+			rc.Compare = &compare
+		}
+		res = append(res, rc)
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -73,5 +73,24 @@ func (d *Debugger) cpuState(w http.ResponseWriter, r *http.Request) {
 }
 
 func (d *Debugger) cpuPause(w http.ResponseWriter, r *http.Request) {
-	// TODO implement
+	d.emulator.Pause()
+}
+
+func (d *Debugger) cpuResume(w http.ResponseWriter, r *http.Request) {
+	d.emulator.Resume()
+}
+
+// cpuFrame emulates the next frame while the emulation is paused.
+func (d *Debugger) cpuFrame(w http.ResponseWriter, r *http.Request) {
+	d.emulator.AdvanceFrame()
+}
+
+// cpuReset executes a soft reset of the system.
+func (d *Debugger) cpuReset(w http.ResponseWriter, r *http.Request) {
+	d.emulator.Reset()
+}
+
+// cpuPowerCycle executes a power cycle of the system.
+func (d *Debugger) cpuPowerCycle(w http.ResponseWriter, r *http.Request) {
+	d.emulator.PowerCycle()
 }
@@ -11,26 +11,62 @@ import (
 	"time"
 
 	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
 )
 
 const defaultWebserverTimeout = 5 * time.Second
 
+// Emulator defines the emulation controls that the debugger uses.
+type Emulator interface {
+	Pause()
+	Resume()
+	AdvanceFrame()
+	Reset()
+	PowerCycle()
+
+	ExportSave() ([]byte, error)
+	ImportSave(data []byte) error
+
+	Cheats() *gamegenie.List
+	RawCheats() *cheat.List
+	MemorySnapshot() cheat.Snapshot
+}
+
 // Debugger implements a Debugger webserver.
 type Debugger struct {
-	bus    *bus.Bus
-	server *http.Server
+	bus       *bus.Bus
+	emulator  Emulator
+	ramSearch *cheat.Search
+	server    *http.Server
 }
 
 // New creates a new debugger webserver.
-func New(listenAddress string, bus *bus.Bus) *Debugger {
+func New(listenAddress string, bus *bus.Bus, emulator Emulator) *Debugger {
 	d := &Debugger{
-		bus: bus,
+		bus:       bus,
+		emulator:  emulator,
+		ramSearch: cheat.NewSearch(cheat.Snapshot{}),
 	}
 
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/cpu", d.cpuState)
 	mux.HandleFunc("/cpu/pause", d.cpuPause)
+	mux.HandleFunc("/cpu/resume", d.cpuResume)
+	mux.HandleFunc("/cpu/frame", d.cpuFrame)
+	mux.HandleFunc("/cpu/reset", d.cpuReset)
+	mux.HandleFunc("/cpu/powercycle", d.cpuPowerCycle)
+
+	mux.HandleFunc("/cartridge/save", d.cartridgeSave)
+
+	mux.HandleFunc("/cheats", d.cheats)
+	mux.HandleFunc("/cheats/toggle", d.cheatsToggle)
+	mux.HandleFunc("/cheats/raw", d.rawCheats)
+	mux.HandleFunc("/cheats/raw/toggle", d.rawCheatsToggle)
+	mux.HandleFunc("/cheats/search", d.search)
+	mux.HandleFunc("/cheats/search/filter", d.searchFilter)
+	mux.HandleFunc("/cheats/search/promote", d.searchPromote)
 
 	mux.HandleFunc("/mapper", d.mapperState)
 
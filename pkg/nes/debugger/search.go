@@ -0,0 +1,167 @@
+//go:build !nesgo
+
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+)
+
+const (
+	romAddress      = 0x8000
+	maxSearchResult = 256 // maximum amount of search candidates returned
+)
+
+type searchState struct {
+	Count      int            `json:"count"`
+	Candidates []searchResult `json:"candidates"`
+}
+
+type searchResult struct {
+	Address  hexWord `json:"address"`
+	Previous hexByte `json:"previous"`
+	Value    hexByte `json:"value"`
+}
+
+// search returns the state of the RAM search on GET requests and starts a new
+// search with the current memory as start values on POST requests.
+func (d *Debugger) search(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		// return the current search state
+
+	case http.MethodPost:
+		d.ramSearch.Reset(d.emulator.MemorySnapshot())
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	d.writeSearch(w)
+}
+
+// searchFilter removes all search candidates that do not match the filter passed
+// as filter parameter. The value filter compares with the hex value passed as
+// value parameter.
+func (d *Debugger) searchFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var value uint64
+	filter := cheat.Filter(r.FormValue("filter"))
+	if filter == cheat.Value {
+		var err error
+		value, err = parseHex(r.FormValue("value"), 8)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := d.ramSearch.Filter(d.emulator.MemorySnapshot(), filter, byte(value)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d.writeSearch(w)
+}
+
+// searchPromote adds a cheat for the hex address passed as address parameter.
+// Addresses in the internal RAM and PRG RAM are added as freeze cheat, addresses
+// in ROM as Game Genie code. The cheat uses the hex value passed as value parameter.
+// For RAM addresses the value defaults to the value of the last search step, ROM
+// addresses require a value.
+func (d *Debugger) searchPromote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	address, err := parseHex(r.FormValue("address"), 16)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	searchValue, searched := d.ramSearch.Value(uint16(address))
+	if !searched && address < romAddress {
+		http.Error(w, fmt.Sprintf("address %04x is not in RAM or ROM", address), http.StatusBadRequest)
+		return
+	}
+
+	value := uint64(searchValue)
+	if s := r.FormValue("value"); s != "" {
+		if value, err = parseHex(s, 8); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if !searched {
+		http.Error(w, "missing value for ROM address", http.StatusBadRequest)
+		return
+	}
+
+	if searched {
+		err = d.emulator.RawCheats().Add(cheat.Cheat{
+			Name:    fmt.Sprintf("search %04x", address),
+			Address: uint16(address),
+			Value:   byte(value),
+			Enabled: true,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.writeRawCheats(w)
+		return
+	}
+
+	patch := gamegenie.Patch{
+		Address: uint16(address),
+		Data:    byte(value),
+	}
+	code, err := gamegenie.Encode(patch)
+	if err == nil {
+		err = d.emulator.Cheats().Add(code)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d.writeCheats(w)
+}
+
+func (d *Debugger) writeSearch(w http.ResponseWriter) {
+	results := d.ramSearch.Results(maxSearchResult)
+	state := searchState{
+		Count:      d.ramSearch.Count(),
+		Candidates: make([]searchResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		state.Candidates = append(state.Candidates, searchResult{
+			Address:  hexWord(result.Address),
+			Previous: hexByte(result.Previous),
+			Value:    hexByte(result.Value),
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(state)
+}
+
+// parseHex parses a hex number that can be prefixed by $ or 0x.
+func parseHex(s string, bitSize int) (uint64, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(s), "$"), "0x")
+	value, err := strconv.ParseUint(s, 16, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("parsing hex number '%s': %w", s, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,63 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"fmt"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/controller"
+)
+
+// connectInputDevices connects the configured input devices to the controller ports.
+func (sys *System) connectInputDevices(opts *Options, light controller.LightSource) {
+	if opts.fourScore {
+		fourScore := controller.NewFourScore(sys.gamepads)
+		sys.Bus.Port1 = fourScore.Port(1)
+		sys.Bus.Port2 = fourScore.Port(2)
+		return
+	}
+
+	sys.Bus.Port1 = sys.newInputDevice(opts.inputDevices[0], sys.gamepads[0], light)
+	sys.Bus.Port2 = sys.newInputDevice(opts.inputDevices[1], sys.gamepads[1], light)
+}
+
+func (sys *System) newInputDevice(device controller.DeviceType, gamepad *controller.Controller,
+	light controller.LightSource) bus.InputDevice {
+
+	switch device {
+	case controller.DeviceGamepad:
+		return gamepad
+	case controller.DeviceZapper:
+		return controller.NewZapper(light)
+	case controller.DeviceArkanoidPaddle:
+		return controller.NewArkanoidPaddle()
+	case controller.DevicePowerPad:
+		return controller.NewPowerPad()
+	default:
+		panic(fmt.Sprintf("unsupported input device type %d", device))
+	}
+}
+
+// InputDevice returns the input device that is connected to the given controller
+// port (1 or 2). The returned device can be converted to the device specific type
+// like *controller.Zapper to control it.
+func (sys *System) InputDevice(port int) bus.InputDevice {
+	switch port {
+	case 1:
+		return sys.Bus.Port1
+	case 2:
+		return sys.Bus.Port2
+	default:
+		panic(fmt.Sprintf("invalid controller port %d", port))
+	}
+}
+
+// controller returns the gamepad of the given player (1 to 4). The gamepads of
+// player 3 and 4 are only connected when a Four Score adapter is used.
+func (sys *System) controller(port int) bus.Controller {
+	if port < 1 || port > len(sys.gamepads) {
+		panic(fmt.Sprintf("invalid controller port %d", port))
+	}
+	return sys.gamepads[port-1]
+}
@@ -0,0 +1,58 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"errors"
+
+	"github.com/retroenv/nesgo/pkg/fds"
+)
+
+// ErrNoDiskDrive is returned for disk operations on a system without a disk drive.
+var ErrNoDiskDrive = errors.New("system has no disk drive")
+
+// diskDrive returns the disk drive of the Famicom Disk System.
+func (sys *System) diskDrive() (fds.Drive, error) {
+	drive, ok := sys.Bus.Mapper.(fds.Drive)
+	if !ok {
+		return nil, ErrNoDiskDrive
+	}
+	return drive, nil
+}
+
+// InsertDiskSide inserts the disk side with the given index into the drive of the
+// Famicom Disk System. A disk that is in the drive gets ejected first, the new side
+// is inserted after a short delay. It can be called from any goroutine.
+func (sys *System) InsertDiskSide(side int) error {
+	drive, err := sys.diskDrive()
+	if err != nil {
+		return err
+	}
+	return drive.InsertSide(side)
+}
+
+// EjectDisk ejects the disk from the drive of the Famicom Disk System.
+// It can be called from any goroutine.
+func (sys *System) EjectDisk() error {
+	drive, err := sys.diskDrive()
+	if err != nil {
+		return err
+	}
+	drive.Eject()
+	return nil
+}
+
+// SwitchDiskSide inserts the next side of the disk, after the last side the first
+// side is inserted again. It can be called from any goroutine.
+func (sys *System) SwitchDiskSide() error {
+	drive, err := sys.diskDrive()
+	if err != nil {
+		return err
+	}
+	if drive.Sides() == 0 {
+		return fds.ErrInvalidSide
+	}
+
+	side := (drive.InsertedSide() + 1) % drive.Sides()
+	return drive.InsertSide(side)
+}
@@ -0,0 +1,71 @@
+package nes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/fds"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testBIOSProgram is a stub BIOS that turns on the disk drive motor and copies the
+// first 15 bytes that are read from the disk to $0200 by polling the transfer flag.
+var testBIOSProgram = []byte{
+	0xA9, 0x01, // lda #$01
+	0x8D, 0x23, 0x40, // sta $4023
+	0xA9, 0x65, // lda #$65
+	0x8D, 0x25, 0x40, // sta $4025
+	0xA2, 0x00, // ldx #$00
+	0xAD, 0x30, 0x40, // wait: lda $4030
+	0x29, 0x02, // and #$02
+	0xF0, 0xF9, // beq wait
+	0xAD, 0x31, 0x40, // lda $4031
+	0x9D, 0x00, 0x02, // sta $0200,x
+	0xE8,       // inx
+	0xE0, 0x0F, // cpx #$0F
+	0xD0, 0xEE, // bne wait
+	0x4C, 0x1E, 0xE0, // done: jmp done
+}
+
+func testDisk(t *testing.T) *fds.Disk {
+	t.Helper()
+
+	data := make([]byte, 2*fds.SideSize)
+	for offset := 0; offset < len(data); offset += fds.SideSize {
+		data[offset] = 0x01
+		copy(data[offset+1:], "*NINTENDO-HVC*")
+	}
+	disk, err := fds.Load(data)
+	assert.NoError(t, err)
+	return disk
+}
+
+func TestSystemDiskRead(t *testing.T) {
+	bios := make([]byte, fds.BIOSSize)
+	copy(bios, testBIOSProgram)
+	copy(bios[len(bios)-6:], []byte{0x1E, 0xE0, 0x00, 0xE0, 0x1E, 0xE0})
+	cart, err := fds.NewCartridge(bios)
+	assert.NoError(t, err)
+
+	r := NewRunner(cart, WithDisk(testDisk(t)))
+	assert.NoError(t, r.RunUntil(0xE01E))
+
+	data := make([]byte, 15)
+	for i := range data {
+		data[i] = r.ReadMemory(0x0200 + uint16(i))
+	}
+	assert.Equal(t, []byte("\x80\x01*NINTENDO-HVC"), data)
+}
+
+func TestSystemDiskSide(t *testing.T) {
+	cart, err := fds.NewCartridge(make([]byte, fds.BIOSSize))
+	assert.NoError(t, err)
+
+	sys := NewSystem(NewOptions(WithCartridge(cart), WithDisk(testDisk(t))))
+	assert.NoError(t, sys.SwitchDiskSide())
+	assert.NoError(t, sys.EjectDisk())
+	assert.True(t, errors.Is(sys.InsertDiskSide(2), fds.ErrInvalidSide))
+
+	sys = NewSystem(NewOptions(WithCartridge(testCartridge())))
+	assert.True(t, errors.Is(sys.SwitchDiskSide(), ErrNoDiskDrive))
+}
@@ -0,0 +1,104 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/retroenv/nesgo/pkg/inputmap"
+	"github.com/retroenv/nesgo/pkg/nes/pacer"
+	"github.com/retroenv/nesgo/pkg/screenshot"
+)
+
+// setupHotkeys registers the built-in hotkey handlers and the handlers passed
+// as options, which replace the built-in ones.
+func (sys *System) setupHotkeys(opts *Options) {
+	sys.hotkeyHandlers = map[inputmap.Action]func(pressed bool){
+		inputmap.Pause:          sys.togglePause,
+		inputmap.Reset:          onPress(sys.Reset),
+		inputmap.PowerCycle:     onPress(sys.PowerCycle),
+		inputmap.FastForward:    sys.fastForward,
+		inputmap.Screenshot:     sys.requestScreenshot,
+		inputmap.SwitchDiskSide: onPress(sys.switchDiskSideHotkey),
+	}
+	for action, handler := range opts.hotkeyHandlers {
+		sys.hotkeyHandlers[action] = onPress(handler)
+	}
+
+	sys.screenshotDir = opts.screenshotDir
+	sys.AddFrameHook(sys.saveRequestedScreenshot)
+}
+
+// onPress returns a hotkey handler that calls the function when the hotkey is pressed.
+func onPress(f func()) func(pressed bool) {
+	return func(pressed bool) {
+		if pressed {
+			f()
+		}
+	}
+}
+
+// hotkey executes the handler of the hotkey action, hotkeys without
+// a handler are ignored.
+func (sys *System) hotkey(action inputmap.Action, pressed bool) {
+	if handler, ok := sys.hotkeyHandlers[action]; ok {
+		handler(pressed)
+	}
+}
+
+// togglePause pauses a running or resumes a paused emulation.
+func (sys *System) togglePause(pressed bool) {
+	if !pressed {
+		return
+	}
+	if sys.pacer.Mode() == pacer.FrameAdvance {
+		sys.Resume()
+	} else {
+		sys.Pause()
+	}
+}
+
+// fastForward runs the emulation in turbo mode while the hotkey is held.
+func (sys *System) fastForward(pressed bool) {
+	if pressed {
+		if mode := sys.pacer.Mode(); mode != pacer.Turbo {
+			sys.fastForwardMode = mode
+			sys.pacer.SetMode(pacer.Turbo)
+		}
+		return
+	}
+	if sys.pacer.Mode() == pacer.Turbo {
+		sys.pacer.SetMode(sys.fastForwardMode)
+	}
+}
+
+// requestScreenshot requests a screenshot to be saved at the end of the current
+// frame, the hotkey is called from the GUI goroutine.
+func (sys *System) requestScreenshot(pressed bool) {
+	if pressed {
+		atomic.StoreUint32(&sys.screenshotRequested, 1)
+	}
+}
+
+// switchDiskSideHotkey inserts the next disk side, systems without a disk drive
+// ignore the hotkey.
+func (sys *System) switchDiskSideHotkey() {
+	err := sys.SwitchDiskSide()
+	if err != nil && !errors.Is(err, ErrNoDiskDrive) {
+		fmt.Printf("Switching disk side failed: %s\n", err.Error())
+	}
+}
+
+func (sys *System) saveRequestedScreenshot(frame uint64) {
+	if !atomic.CompareAndSwapUint32(&sys.screenshotRequested, 1, 0) {
+		return
+	}
+
+	fileName := filepath.Join(sys.screenshotDir, fmt.Sprintf("nesgo_%d.png", frame))
+	if err := screenshot.Save(sys.Bus.PPU.Image(), fileName); err != nil {
+		fmt.Printf("Saving screenshot failed: %s\n", err.Error())
+	}
+}
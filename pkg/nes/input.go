@@ -3,35 +3,75 @@
 package nes
 
 import (
+	"sync/atomic"
+
 	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/inputmap"
 	"github.com/retroenv/retrogolib/input"
 )
 
-var controllerMapping = map[input.Key]controller.Button{
-	input.Up:        controller.Up,
-	input.Down:      controller.Down,
-	input.Left:      controller.Left,
-	input.Right:     controller.Right,
-	input.Z:         controller.A,
-	input.X:         controller.B,
-	input.Enter:     controller.Start,
-	input.Backspace: controller.Select,
-}
-
 // KeyDown gets called when a key down event is registered.
 func (sys *System) KeyDown(key input.Key) {
-	controllerKey, ok := controllerMapping[key]
-	if !ok {
-		return
+	if binding, ok := sys.inputMapping.Key(key); ok {
+		sys.applyBinding(binding, true)
 	}
-	sys.Bus.Controller1.SetButtonState(controllerKey, true)
 }
 
 // KeyUp gets called when a key up event is registered.
 func (sys *System) KeyUp(key input.Key) {
-	controllerKey, ok := controllerMapping[key]
-	if !ok {
+	if binding, ok := sys.inputMapping.Key(key); ok {
+		sys.applyBinding(binding, false)
+	}
+}
+
+// GamepadButtonDown gets called when a button of a host gamepad is pressed.
+func (sys *System) GamepadButtonDown(gamepad, button int) {
+	if binding, ok := sys.inputMapping.GamepadButton(gamepad, button); ok {
+		sys.applyBinding(binding, true)
+	}
+}
+
+// GamepadButtonUp gets called when a button of a host gamepad is released.
+func (sys *System) GamepadButtonUp(gamepad, button int) {
+	if binding, ok := sys.inputMapping.GamepadButton(gamepad, button); ok {
+		sys.applyBinding(binding, false)
+	}
+}
+
+func (sys *System) applyBinding(binding inputmap.Binding, pressed bool) {
+	if binding.Action != inputmap.NoAction {
+		sys.hotkey(binding.Action, pressed)
+		return
+	}
+	sys.setButtonState(binding.Player, binding.Button, pressed)
+}
+
+// setButtonState sets the button state of the controller of the given player.
+// If the input is latched, the state gets applied at the start of the next frame.
+func (sys *System) setButtonState(player int, button controller.Button, pressed bool) {
+	if !sys.inputLatched {
+		sys.controller(player).SetButtonState(button, pressed)
 		return
 	}
-	sys.Bus.Controller1.SetButtonState(controllerKey, false)
+
+	pending := &sys.pendingButtons[player-1]
+	state := atomic.LoadUint64(pending)
+	if pressed {
+		state |= uint64(button)
+	} else {
+		state &= ^uint64(button)
+	}
+	atomic.StoreUint64(pending, state)
+}
+
+// applyPendingInput sets the latched button states to the controllers and
+// returns the applied states.
+func (sys *System) applyPendingInput() [2]controller.Button {
+	buttons := [2]controller.Button{
+		controller.Button(atomic.LoadUint64(&sys.pendingButtons[0])),
+		controller.Button(atomic.LoadUint64(&sys.pendingButtons[1])),
+	}
+	sys.Bus.Controller1.SetButtons(buttons[0])
+	sys.Bus.Controller2.SetButtons(buttons[1])
+	return buttons
 }
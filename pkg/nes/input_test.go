@@ -0,0 +1,61 @@
+package nes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/inputmap"
+	"github.com/retroenv/nesgo/pkg/nes/pacer"
+	"github.com/retroenv/retrogolib/assert"
+	"github.com/retroenv/retrogolib/input"
+)
+
+func TestInputMapping(t *testing.T) {
+	mapping := inputmap.New()
+	mapping.Keys[input.K] = inputmap.Binding{Player: 2, Button: controller.Start}
+	mapping.Gamepad[inputmap.GamepadButton{Gamepad: 0, Button: 3}] = inputmap.Binding{Player: 1, Button: controller.B}
+	r := NewRunner(testCartridge(), WithInputMapping(mapping))
+	sys := r.System()
+
+	sys.KeyDown(input.K)
+	sys.KeyDown(input.Z)
+	sys.GamepadButtonDown(0, 3)
+	assert.Equal(t, controller.Start, sys.controller(2).Buttons())
+	assert.Equal(t, controller.B, sys.controller(1).Buttons())
+
+	sys.KeyUp(input.K)
+	sys.GamepadButtonUp(0, 3)
+	assert.Equal(t, 0, sys.controller(2).Buttons())
+	assert.Equal(t, 0, sys.controller(1).Buttons())
+}
+
+func TestHotkeys(t *testing.T) {
+	dir := t.TempDir()
+	saved := false
+	r := NewRunner(testCartridge(),
+		WithScreenshotDir(dir),
+		WithHotkeyHandler(inputmap.SaveState, func() { saved = true }),
+	)
+	sys := r.System()
+
+	sys.KeyDown(input.P)
+	assert.Equal(t, pacer.FrameAdvance, sys.Pacer().Mode())
+	sys.KeyUp(input.P)
+	sys.KeyDown(input.P)
+	assert.Equal(t, pacer.Normal, sys.Pacer().Mode())
+
+	sys.KeyDown(input.Tab)
+	assert.Equal(t, pacer.Turbo, sys.Pacer().Mode())
+	sys.KeyUp(input.Tab)
+	assert.Equal(t, pacer.Normal, sys.Pacer().Mode())
+
+	sys.KeyDown(input.F5)
+	assert.True(t, saved)
+
+	sys.KeyDown(input.F12)
+	assert.NoError(t, r.RunFrames(1))
+	files, err := filepath.Glob(filepath.Join(dir, "nesgo_*.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+}
@@ -0,0 +1,25 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"github.com/retroenv/nesgo/pkg/inputscript"
+)
+
+// addInputScriptHook adds a frame hook that applies the input events of the script.
+func (sys *System) addInputScriptHook(script *inputscript.Script) {
+	if script == nil || len(script.Events) == 0 {
+		return
+	}
+
+	sys.AddFrameHook(func(frame uint64) {
+		for _, event := range script.EventsAt(frame) {
+			c := sys.controller(event.Port)
+			if event.Pressed {
+				c.SetButtons(c.Buttons() | event.Buttons)
+			} else {
+				c.SetButtons(c.Buttons() &^ event.Buttons)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"github.com/retroenv/nesgo/pkg/movie"
+)
+
+// movieRecorder records the controller input of every frame into a movie.
+type movieRecorder struct {
+	movie *movie.Movie
+	ram   []byte // RAM state at the start of the last recorded frame
+}
+
+// recordMovie records the controller input of every frame into the movie.
+// The input gets latched to make sure that the game reads the same input
+// during the frame as it gets recorded. The save file of a battery backed
+// cartridge is not used while recording.
+func (sys *System) recordMovie(m *movie.Movie) *movieRecorder {
+	sys.inputLatched = true
+	sys.disableBatterySave()
+	recorder := &movieRecorder{
+		movie: m,
+	}
+
+	sys.AddFrameHook(func(frame uint64) {
+		recorder.ram = sys.ram()
+		sys.scheduledReset = sys.takePendingReset()
+		m.Frames = append(m.Frames, movie.Frame{
+			Commands: sys.scheduledReset.movieCommands(),
+			Buttons:  sys.applyPendingInput(),
+		})
+	})
+	return recorder
+}
+
+// finish stores the checksum of the RAM in the recorded movie. As the emulation
+// stops at an arbitrary point within the last frame, that frame gets discarded
+// and the RAM state of its start is used, which is the point in time that a
+// replay verifies the RAM at. The emulation has to be stopped before calling
+// this function.
+func (r *movieRecorder) finish() {
+	frames := len(r.movie.Frames)
+	if frames == 0 {
+		return
+	}
+
+	r.movie.Frames = r.movie.Frames[:frames-1]
+	r.movie.RAMChecksum = movie.Checksum(r.ram)
+}
+
+// playMovie replays the controller input of the movie, after the last frame the
+// RAM gets verified and the done function called with the verification result.
+// The save file of a battery backed cartridge is not used during the replay.
+func (sys *System) playMovie(m *movie.Movie, done func(err error)) {
+	sys.inputLatched = true
+	sys.disableBatterySave()
+	index := 0
+
+	sys.AddFrameHook(func(frame uint64) {
+		switch {
+		case index < len(m.Frames):
+			// resets requested by the user are ignored during the replay
+			sys.takePendingReset()
+			sys.scheduledReset = resetKindFromMovie(m.Frames[index].Commands)
+			buttons := m.Frames[index].Buttons
+			sys.Bus.Controller1.SetButtons(buttons[0])
+			sys.Bus.Controller2.SetButtons(buttons[1])
+
+		case index == len(m.Frames):
+			done(m.VerifyRAM(sys.ram()))
+
+		default:
+			return
+		}
+		index++
+	})
+}
+
+// ram returns a copy of the 2K internal RAM.
+func (sys *System) ram() []byte {
+	data := make([]byte, ramSize)
+	for i := range data {
+		data[i] = sys.Bus.Memory.Read(uint16(i))
+	}
+	return data
+}
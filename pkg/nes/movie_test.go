@@ -0,0 +1,85 @@
+package nes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestMovieRecordPlayback(t *testing.T) {
+	cart := testCartridge()
+	m := movie.New("test.nes", cart.PRG, cart.CHR)
+	Start(nil, WithEmulator(), WithCartridge(cart), WithDisabledGUI(), WithTurbo(),
+		WithStopAtFrame(5), WithMovieRecording(m))
+
+	assert.True(t, len(m.Frames) >= 5)
+	assert.True(t, m.RAMChecksum != "")
+
+	r := NewRunner(testCartridge())
+	assert.NoError(t, r.PlayMovie(m))
+}
+
+func TestMoviePlaybackVerify(t *testing.T) {
+	cart := testCartridge()
+	m := movie.New("test.nes", cart.PRG, cart.CHR)
+	for i := 0; i < 4; i++ {
+		m.Frames = append(m.Frames, movie.Frame{
+			Buttons: [2]controller.Button{controller.Button(i % 2), 0},
+		})
+	}
+
+	// the RAM gets verified at the start of the frame that follows the last movie frame
+	r := NewRunner(cart)
+	var ram []byte
+	r.System().AddFrameHook(func(frame uint64) {
+		if frame == uint64(len(m.Frames)) {
+			ram = r.RAM()
+		}
+	})
+	assert.NoError(t, r.PlayMovie(m))
+	assert.Equal(t, 1, ram[0x02])
+	m.RAMChecksum = movie.Checksum(ram)
+
+	r = NewRunner(testCartridge())
+	assert.NoError(t, r.PlayMovie(m))
+
+	m.Frames[3].Buttons[0] = 0
+	r = NewRunner(testCartridge())
+	assert.True(t, errors.Is(r.PlayMovie(m), movie.ErrChecksumMismatch))
+
+	cart = testCartridge()
+	cart.PRG[0] = 0xEA
+	r = NewRunner(cart)
+	assert.True(t, errors.Is(r.PlayMovie(m), movie.ErrChecksumMismatch))
+}
+
+func TestMovieBatterySave(t *testing.T) {
+	interval := batterySaveInterval
+	batterySaveInterval = 2
+	defer func() {
+		batterySaveInterval = interval
+	}()
+
+	fileName := filepath.Join(t.TempDir(), "game.sav")
+	save := []byte{0x00, 0x05}
+	assert.NoError(t, os.WriteFile(fileName, save, 0o600))
+
+	cart := testBatteryCartridge()
+	m := movie.New("test.nes", cart.PRG, cart.CHR)
+	Start(nil, WithEmulator(), WithCartridge(cart), WithDisabledGUI(), WithTurbo(),
+		WithStopAtFrame(5), WithSaveFile(fileName), WithMovieRecording(m))
+
+	r := NewRunner(testBatteryCartridge(), WithSaveFile(fileName))
+	assert.Equal(t, 5, r.ReadMemory(0x6001)) // loaded, but cleared by the playback
+	assert.NoError(t, r.PlayMovie(m))
+	assert.Equal(t, 1, r.ReadMemory(0x6001)) // incremented once from the cleared RAM
+
+	data, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, save, data)
+}
@@ -5,14 +5,26 @@ package nes
 import (
 	"io"
 
+	"github.com/retroenv/nesgo/pkg/cdl"
+	"github.com/retroenv/nesgo/pkg/cheat"
+	"github.com/retroenv/nesgo/pkg/controller"
 	"github.com/retroenv/nesgo/pkg/cpu"
+	"github.com/retroenv/nesgo/pkg/fds"
+	"github.com/retroenv/nesgo/pkg/inputmap"
+	"github.com/retroenv/nesgo/pkg/inputscript"
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/nesgo/pkg/nes/pacer"
+	"github.com/retroenv/nesgo/pkg/profiler"
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/nesgo/pkg/romdb"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 )
 
 // Options contains options for the nesgo system.
 type Options struct {
-	entrypoint int
-	stopAt     int
+	entrypoint  int
+	stopAt      int
+	stopAtFrame uint64
 
 	debug        bool
 	debugAddress string
@@ -20,12 +32,45 @@ type Options struct {
 	emulator  bool
 	noGui     bool
 	cartridge *cartridge.Cartridge
+	disk      *fds.Disk
+
+	romDatabase        *romdb.Database
+	romDatabaseHandler func(entry romdb.Entry, changes []string)
+	noROMDatabase      bool
 
 	tracing       cpu.TracingMode
 	tracingTarget io.Writer
+	traceOptions  cpu.TraceOptions
 
 	nmiHandler func()
 	irqHandler func()
+
+	screenshots map[uint64][]string
+
+	speed      float64
+	pacingMode pacer.Mode
+	region     region.Region
+
+	movieRecording    *movie.Movie
+	moviePlayback     *movie.Movie
+	moviePlaybackDone func(err error)
+
+	inputScript *inputscript.Script
+
+	inputDevices [2]controller.DeviceType
+	fourScore    bool
+
+	inputMapping   *inputmap.Mapping
+	hotkeyHandlers map[inputmap.Action]func()
+	screenshotDir  string
+
+	saveFile string
+
+	cheats    []string
+	rawCheats []cheat.Cheat
+
+	codeDataLog *cdl.Logger
+	profiler    *profiler.Profiler
 }
 
 // Option defines a Start parameter.
@@ -36,6 +81,14 @@ func NewOptions(optionList ...Option) *Options {
 	opts := &Options{
 		entrypoint: -1,
 		stopAt:     -1,
+		speed:      1.0,
+
+		traceOptions: cpu.TraceOptions{
+			StartAt: -1,
+		},
+
+		hotkeyHandlers: map[inputmap.Action]func(){},
+		screenshotDir:  ".",
 	}
 	for _, option := range optionList {
 		option(opts)
@@ -55,6 +108,40 @@ func WithCartridge(cart *cartridge.Cartridge) func(*Options) {
 	}
 }
 
+// WithDisk inserts the disk into the drive of the Famicom Disk System. The cartridge
+// that contains the BIOS needs to be created by fds.NewCartridge and passed using
+// WithCartridge.
+func WithDisk(disk *fds.Disk) func(*Options) {
+	return func(options *Options) {
+		options.disk = disk
+	}
+}
+
+// WithROMDatabase sets the ROM database that is used to fix wrong header fields
+// of the cartridge, by default the database embedded in the romdb package is used.
+func WithROMDatabase(db *romdb.Database) func(*Options) {
+	return func(options *Options) {
+		options.romDatabase = db
+	}
+}
+
+// WithROMDatabaseHandler sets a function that gets called with the database
+// entry and a description of every changed field when the ROM database fixed
+// the header of the cartridge.
+func WithROMDatabaseHandler(handler func(entry romdb.Entry, changes []string)) func(*Options) {
+	return func(options *Options) {
+		options.romDatabaseHandler = handler
+	}
+}
+
+// WithoutROMDatabase disables the ROM database lookup, the header fields of the
+// cartridge are used as they are.
+func WithoutROMDatabase() func(*Options) {
+	return func(options *Options) {
+		options.noROMDatabase = true
+	}
+}
+
 // WithEmulator sets the emulator mode.
 func WithEmulator() func(*Options) {
 	return func(options *Options) {
@@ -99,6 +186,43 @@ func WithTracingTarget(target io.Writer) func(*Options) {
 	}
 }
 
+// WithTraceFilter enables tracing for the program and limits the traced instructions
+// to the ones matching the filter.
+func WithTraceFilter(filter cpu.TraceFilter) func(*Options) {
+	return func(options *Options) {
+		options.tracing = cpu.GoTracing
+		options.traceOptions.Filter = filter
+	}
+}
+
+// WithTraceFormat enables tracing for the program using the given output format.
+func WithTraceFormat(format cpu.TraceFormat) func(*Options) {
+	return func(options *Options) {
+		options.tracing = cpu.GoTracing
+		options.traceOptions.Format = format
+	}
+}
+
+// WithTraceStartAt enables tracing for the program starting at the first
+// execution of the given address.
+func WithTraceStartAt(address uint16) func(*Options) {
+	return func(options *Options) {
+		options.tracing = cpu.GoTracing
+		options.traceOptions.StartAt = int(address)
+	}
+}
+
+// WithTraceBuffer enables tracing for the program and keeps the last trace lines
+// in a ring buffer of the given size instead of outputting them. The buffer is
+// written to the trace output when the program panics or reaches the address
+// set by WithStopAt.
+func WithTraceBuffer(lines int) func(*Options) {
+	return func(options *Options) {
+		options.tracing = cpu.GoTracing
+		options.traceOptions.BufferSize = lines
+	}
+}
+
 // WithEntrypoint enables tracing for the program.
 func WithEntrypoint(address int) func(*Options) {
 	return func(options *Options) {
@@ -113,6 +237,173 @@ func WithStopAt(address int) func(*Options) {
 	}
 }
 
+// WithStopAtFrame stops execution of the program after the given frame has been
+// rendered. It is only supported in emulator mode.
+func WithStopAtFrame(frame uint64) func(*Options) {
+	return func(options *Options) {
+		options.stopAtFrame = frame
+	}
+}
+
+// WithScreenshot saves a screenshot of the given frame as PNG to the given file.
+// It can be passed multiple times to save multiple screenshots.
+func WithScreenshot(frame uint64, fileName string) func(*Options) {
+	return func(options *Options) {
+		if options.screenshots == nil {
+			options.screenshots = map[uint64][]string{}
+		}
+		options.screenshots[frame] = append(options.screenshots[frame], fileName)
+	}
+}
+
+// WithSpeed sets the emulation speed factor, 1.0 is the original speed of the
+// system, values below 1 result in a slow motion and values above 1 in a fast-forward.
+func WithSpeed(speed float64) func(*Options) {
+	return func(options *Options) {
+		options.speed = speed
+	}
+}
+
+// WithTurbo disables the frame pacing and runs the emulation as fast as possible.
+func WithTurbo() func(*Options) {
+	return func(options *Options) {
+		options.pacingMode = pacer.Turbo
+	}
+}
+
+// WithPaused starts the emulation in frame advance mode, paused after the first frame.
+func WithPaused() func(*Options) {
+	return func(options *Options) {
+		options.pacingMode = pacer.FrameAdvance
+	}
+}
+
+// WithRegion sets the region of the emulated system, the default is NTSC.
+func WithRegion(r region.Region) func(*Options) {
+	return func(options *Options) {
+		options.region = r
+	}
+}
+
+// WithMovieRecording records the controller input of every frame into the
+// passed movie. When Start returns, the movie contains all recorded frames and
+// the RAM checksum after the last frame. It is only supported in emulator mode.
+func WithMovieRecording(m *movie.Movie) func(*Options) {
+	return func(options *Options) {
+		options.movieRecording = m
+	}
+}
+
+// WithMoviePlayback replays the controller input of the passed movie. After the
+// last frame, the RAM checksum gets verified, the done function called with the
+// verification result and the emulation stopped. It is only supported in emulator mode.
+func WithMoviePlayback(m *movie.Movie, done func(err error)) func(*Options) {
+	return func(options *Options) {
+		options.moviePlayback = m
+		options.moviePlaybackDone = done
+	}
+}
+
+// WithInputScript applies the controller input events of the script at the
+// start of the vertical blank of their frames.
+func WithInputScript(script *inputscript.Script) func(*Options) {
+	return func(options *Options) {
+		options.inputScript = script
+	}
+}
+
+// WithInputDevice sets the type of the input device that is connected to the
+// given controller port (1 or 2). By default, gamepads are connected to both ports.
+func WithInputDevice(port int, device controller.DeviceType) func(*Options) {
+	return func(options *Options) {
+		options.inputDevices[port-1] = device
+	}
+}
+
+// WithFourScore connects a Four Score multitap adapter with 4 gamepads to both
+// controller ports, replacing the devices set by WithInputDevice.
+func WithFourScore() func(*Options) {
+	return func(options *Options) {
+		options.fourScore = true
+	}
+}
+
+// WithInputMapping sets the mapping of host keys and gamepad buttons to
+// controller buttons and hotkeys, by default inputmap.Default is used.
+func WithInputMapping(mapping *inputmap.Mapping) func(*Options) {
+	return func(options *Options) {
+		options.inputMapping = mapping
+	}
+}
+
+// WithHotkeyHandler sets a handler that gets called from the GUI goroutine when
+// the hotkey of the given action is pressed. It replaces the built-in handler
+// of the action, the system has built-in handlers for pause, reset, power cycle,
+// fast-forward, screenshot and switching the disk side.
+func WithHotkeyHandler(action inputmap.Action, handler func()) func(*Options) {
+	return func(options *Options) {
+		options.hotkeyHandlers[action] = handler
+	}
+}
+
+// WithScreenshotDir sets the directory that screenshots that are triggered
+// by the screenshot hotkey are saved in.
+func WithScreenshotDir(dir string) func(*Options) {
+	return func(options *Options) {
+		options.screenshotDir = dir
+	}
+}
+
+// WithSaveFile sets the file that the battery backed PRG RAM of the cartridge
+// gets persisted in. The file is loaded at start if it exists and written when
+// the RAM changed, periodically and at exit. Cartridges without battery flag
+// ignore this option, while a movie is recorded or played the file is not used
+// and the RAM starts cleared.
+func WithSaveFile(fileName string) func(*Options) {
+	return func(options *Options) {
+		options.saveFile = fileName
+	}
+}
+
+// WithCheats enables the given Game Genie codes. The patches of the codes are applied
+// to all reads from the cartridge, codes with a compare value only patch matching
+// bytes of the currently mapped PRG bank. The codes have to be valid, they can be
+// checked using gamegenie.Decode.
+func WithCheats(codes ...string) func(*Options) {
+	return func(options *Options) {
+		options.cheats = append(options.cheats, codes...)
+	}
+}
+
+// WithRawCheats enables the given RAM freeze and substitute cheats, which can be
+// loaded from a .cht file using cheat.Load. Freeze cheats are written to memory at
+// the end of every frame, substitute cheats are applied to reads from the cartridge.
+// The cheats have to be valid, they can be checked using cheat.Cheat.Validate.
+func WithRawCheats(cheats ...cheat.Cheat) func(*Options) {
+	return func(options *Options) {
+		options.rawCheats = append(options.rawCheats, cheats...)
+	}
+}
+
+// WithCodeDataLog records which PRG ROM bytes get executed or read as data and
+// which CHR ROM bytes get rendered into the passed logger. The logger needs to be
+// created for the PRG and CHR ROM sizes of the cartridge. It is only supported
+// in emulator mode.
+func WithCodeDataLog(logger *cdl.Logger) func(*Options) {
+	return func(options *Options) {
+		options.codeDataLog = logger
+	}
+}
+
+// WithProfiler attributes the executed CPU cycles to the functions of the program
+// and the rendered frames using the passed profiler. It is only supported in
+// emulator mode.
+func WithProfiler(p *profiler.Profiler) func(*Options) {
+	return func(options *Options) {
+		options.profiler = p
+	}
+}
+
 // WithDisabledGUI disabled the GUI.
 func WithDisabledGUI() func(*Options) {
 	return func(options *Options) {
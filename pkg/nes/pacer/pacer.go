@@ -0,0 +1,145 @@
+//go:build !nesgo
+
+// Package pacer implements frame pacing of the emulation against the wall clock.
+package pacer
+
+import (
+	"sync"
+	"time"
+)
+
+// maxLag defines the amount of frames that the emulation can lag behind the
+// wall clock before the pacer stops trying to catch up.
+const maxLag = 5
+
+// Mode defines the pacing mode.
+type Mode int
+
+const (
+	Normal       Mode = iota // emulate at the frame rate multiplied by the speed factor
+	Turbo                    // emulate as fast as possible
+	FrameAdvance             // pause after every frame until the next frame is requested
+)
+
+// Pacer limits the emulation to a specific frame rate.
+type Pacer struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	frameDuration time.Duration
+	speed         float64
+	mode          Mode
+	advance       int       // frames that can be emulated in frame advance mode
+	next          time.Time // deadline for the end of the next frame
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// New returns a new pacer for the given frame rate.
+func New(frameRate float64) *Pacer {
+	p := &Pacer{
+		speed: 1.0,
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+	p.cond = sync.NewCond(&p.mu)
+	p.setFrameRate(frameRate)
+	return p
+}
+
+// SetFrameRate sets the frame rate to pace the emulation to.
+func (p *Pacer) SetFrameRate(frameRate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setFrameRate(frameRate)
+}
+
+func (p *Pacer) setFrameRate(frameRate float64) {
+	p.frameDuration = time.Duration(float64(time.Second) / frameRate)
+	p.next = time.Time{}
+}
+
+// FrameDuration returns the duration of a single frame at normal speed.
+func (p *Pacer) FrameDuration() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.frameDuration
+}
+
+// SetSpeed sets the speed factor of the normal mode, 1.0 is the original speed,
+// values below 1 result in a slow motion and values above 1 in a fast-forward.
+func (p *Pacer) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed = speed
+	p.next = time.Time{}
+}
+
+// Mode returns the current pacing mode.
+func (p *Pacer) Mode() Mode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mode
+}
+
+// SetMode sets the pacing mode.
+func (p *Pacer) SetMode(mode Mode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.mode = mode
+	p.advance = 0
+	p.next = time.Time{}
+	p.cond.Broadcast()
+}
+
+// AdvanceFrame allows the emulation of one more frame in frame advance mode.
+func (p *Pacer) AdvanceFrame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance++
+	p.cond.Broadcast()
+}
+
+// Wait blocks until the next frame should be emulated. It has to be called
+// after every emulated frame.
+func (p *Pacer) Wait() {
+	p.mu.Lock()
+
+	switch p.mode {
+	case Turbo:
+		p.mu.Unlock()
+		return
+
+	case FrameAdvance:
+		for p.mode == FrameAdvance && p.advance == 0 {
+			p.cond.Wait()
+		}
+		if p.mode == FrameAdvance {
+			p.advance--
+		}
+		p.mu.Unlock()
+		return
+	}
+
+	duration := time.Duration(float64(p.frameDuration) / p.speed)
+	now := p.now()
+	if p.next.IsZero() || now.Sub(p.next) > maxLag*duration {
+		// start pacing from now on at start or after the emulation was
+		// too slow to keep up, instead of trying to catch up
+		p.next = now
+	}
+	p.next = p.next.Add(duration)
+	wait := p.next.Sub(now)
+	p.mu.Unlock()
+
+	if wait > 0 {
+		p.sleep(wait)
+	}
+}
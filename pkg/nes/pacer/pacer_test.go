@@ -0,0 +1,95 @@
+package pacer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+type testClock struct {
+	current time.Time
+	slept   []time.Duration
+}
+
+func newTestPacer(frameRate float64) (*Pacer, *testClock) {
+	clock := &testClock{
+		current: time.Unix(0, 0),
+	}
+	p := New(frameRate)
+	p.now = func() time.Time {
+		return clock.current
+	}
+	p.sleep = func(d time.Duration) {
+		clock.slept = append(clock.slept, d)
+		clock.current = clock.current.Add(d)
+	}
+	return p, clock
+}
+
+func TestPacerNormal(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestPacer(50)
+
+	p.Wait()
+	clock.current = clock.current.Add(5 * time.Millisecond)
+	p.Wait()
+	assert.Equal(t, []time.Duration{20 * time.Millisecond, 15 * time.Millisecond}, clock.slept)
+
+	p.SetSpeed(0.5)
+	clock.slept = nil
+	p.Wait()
+	assert.Equal(t, []time.Duration{40 * time.Millisecond}, clock.slept)
+}
+
+func TestPacerLag(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestPacer(50)
+
+	p.Wait()
+	// a slow frame is caught up by the following frames
+	clock.current = clock.current.Add(30 * time.Millisecond)
+	p.Wait()
+	p.Wait()
+	assert.Equal(t, []time.Duration{20 * time.Millisecond, 10 * time.Millisecond}, clock.slept)
+
+	// lagging behind too much resets the pacing
+	clock.slept = nil
+	clock.current = clock.current.Add(time.Second)
+	p.Wait()
+	assert.Equal(t, []time.Duration{20 * time.Millisecond}, clock.slept)
+}
+
+func TestPacerTurbo(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestPacer(60)
+
+	p.SetMode(Turbo)
+	p.Wait()
+	p.Wait()
+	assert.Equal(t, 0, len(clock.slept))
+}
+
+func TestPacerFrameAdvance(t *testing.T) {
+	t.Parallel()
+	p, _ := newTestPacer(60)
+	p.SetMode(FrameAdvance)
+
+	p.AdvanceFrame()
+	p.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("frame advanced without request")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	p.AdvanceFrame()
+	<-done
+}
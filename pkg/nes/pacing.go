@@ -0,0 +1,54 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"github.com/retroenv/nesgo/pkg/nes/pacer"
+)
+
+// startPacing adds a frame hook that paces the emulation against the wall clock
+// and signals the renderer that a new frame is available.
+func (sys *System) startPacing() {
+	sys.AddFrameHook(func(frame uint64) {
+		select {
+		case sys.frameSignal <- struct{}{}:
+		default:
+		}
+
+		sys.waitForPacer()
+	})
+}
+
+// waitForPacer waits for the pacer and allows other goroutines to access the
+// emulation state while waiting.
+func (sys *System) waitForPacer() {
+	if !sys.emulationLoop {
+		sys.pacer.Wait()
+		return
+	}
+
+	sys.emulationMu.Unlock()
+	sys.pacer.Wait()
+	sys.emulationMu.Lock()
+}
+
+// Pacer returns the frame pacer of the system.
+func (sys *System) Pacer() *pacer.Pacer {
+	return sys.pacer
+}
+
+// Pause pauses the emulation after the current frame, AdvanceFrame can be used
+// to emulate single frames while paused.
+func (sys *System) Pause() {
+	sys.pacer.SetMode(pacer.FrameAdvance)
+}
+
+// Resume resumes a paused emulation at normal speed.
+func (sys *System) Resume() {
+	sys.pacer.SetMode(pacer.Normal)
+}
+
+// AdvanceFrame emulates the next frame while the emulation is paused.
+func (sys *System) AdvanceFrame() {
+	sys.pacer.AdvanceFrame()
+}
@@ -0,0 +1,47 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"github.com/retroenv/nesgo/pkg/profiler"
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+)
+
+// profiledStep executes the next step and attributes its cycles to the profiler.
+func (sys *System) profiledStep() error {
+	pc := sys.PC
+	sp := sys.SP
+	cycles := sys.Cycles()
+	nmiTriggered := sys.CPU.State().Interrupts.NMITriggered
+
+	kind, err := sys.step()
+	if err != nil {
+		return err
+	}
+	cycles = sys.Cycles() - cycles
+
+	switch kind {
+	case resetStep:
+		sys.profiler.Reset()
+
+	case stallStep:
+		sys.profiler.Stall(cycles)
+
+	case interruptStep:
+		nmi := nmiTriggered && !sys.CPU.State().Interrupts.NMITriggered
+		sys.profiler.Interrupt(nmi, pc, sys.PC, sp, cycles)
+
+	case instructionStep:
+		sys.profiler.Instruction(profiler.Step{
+			PC:          pc,
+			Next:        sys.PC,
+			SP:          sp,
+			A:           sys.A,
+			X:           sys.X,
+			Y:           sys.Y,
+			Instruction: m6502.Opcodes[sys.TraceStep.Opcode[0]].Instruction.Name,
+			Cycles:      cycles,
+		})
+	}
+	return nil
+}
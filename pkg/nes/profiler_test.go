@@ -0,0 +1,75 @@
+package nes
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/profiler"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testCartridgeProfile returns a cartridge with a main loop that waits for the
+// NMI and calls a subroutine that executes a delay loop of the given length.
+func testCartridgeProfile(delay byte) *cartridge.Cartridge {
+	cart := testCartridgeWithProgram([]byte{
+		0x2C, 0x02, 0x20, // $8000: bit PPU_STATUS
+		0xA9, 0x80, // lda #$80
+		0x8D, 0x00, 0x20, // sta PPU_CTRL
+		0xA5, 0x00, // $8008: wait: lda $00
+		0xF0, 0xFC, // beq wait
+		0xA9, 0x00, // lda #$00
+		0x85, 0x00, // sta $00
+		0x20, 0x20, 0x80, // jsr update
+		0x4C, 0x08, 0x80, // jmp wait
+	})
+	copy(cart.PRG[0x20:], []byte{
+		0xA0, delay, // $8020: update: ldy #delay
+		0xA2, 0x00, // $8022: ldx #$00
+		0xCA,       // $8024: dex
+		0xD0, 0xFD, // bne $8024
+		0x88,       // dey
+		0xD0, 0xF8, // bne $8022
+		0x60, // rts
+	})
+	copy(cart.PRG[0x30:], []byte{
+		0xE6, 0x00, // $8030: nmi: inc $00
+		0x40, // rti
+	})
+	copy(cart.PRG[len(cart.PRG)-6:], []byte{0x30, 0x80})
+	return cart
+}
+
+func TestSystemProfiler(t *testing.T) {
+	p := profiler.New(nil)
+	r := NewRunner(testCartridgeProfile(1), WithProfiler(p))
+	assert.NoError(t, r.RunFrames(3))
+
+	functions := map[string]profiler.Function{}
+	for _, fun := range p.Functions() {
+		functions[fun.Name] = fun
+	}
+	assert.Equal(t, 3, len(functions))
+	assert.Equal(t, 0x8000, functions["reset"].Address)
+	assert.Equal(t, 0x8020, functions["_label_8020"].Address)
+	assert.Equal(t, 0x8030, functions["nmi"].Address)
+	assert.Equal(t, functions["nmi"].Calls, functions["_label_8020"].Calls)
+	assert.True(t, functions["_label_8020"].SelfCycles > 1000)
+	assert.Equal(t, functions["_label_8020"].SelfCycles, functions["_label_8020"].TotalCycles)
+
+	assert.Equal(t, 0, len(p.Overruns()))
+	frames := p.Frames()
+	assert.Equal(t, 3, len(frames))
+	assert.True(t, frames[1].IdleCycles > frames[1].BusyCycles())
+	assert.True(t, frames[1].NMICycles > 0)
+}
+
+func TestSystemProfilerOverrun(t *testing.T) {
+	p := profiler.New(nil)
+	r := NewRunner(testCartridgeProfile(0x20), WithProfiler(p))
+	assert.NoError(t, r.RunFrames(3))
+
+	overruns := p.Overruns()
+	assert.True(t, len(overruns) > 0)
+	assert.Equal(t, "reset;_label_8020", overruns[0].Stack)
+	assert.False(t, overruns[0].InNMI)
+}
@@ -0,0 +1,121 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/retroenv/nesgo/pkg/mapper"
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/nesgo/pkg/ppu/nametable"
+)
+
+// resetKind defines the kind of a requested reset, a power cycle takes
+// precedence over a soft reset.
+type resetKind uint32
+
+const (
+	noReset resetKind = iota
+	softReset
+	powerCycle
+)
+
+// Reset requests a soft reset of the system, as triggered by the reset button
+// of the console. The reset gets executed before the next emulation step, or at
+// the next frame start while a movie is recorded or replayed. The RAM and the
+// mapper state are kept. Resets are only supported in emulator mode.
+func (sys *System) Reset() {
+	sys.requestReset(softReset)
+}
+
+// PowerCycle requests a power cycle of the system, which turns the console off
+// and on again. The RAM is cleared and the PPU and mapper are reinitialized.
+// It is executed at the same point in time as a reset.
+func (sys *System) PowerCycle() {
+	sys.requestReset(powerCycle)
+}
+
+func (sys *System) requestReset(kind resetKind) {
+	for {
+		pending := atomic.LoadUint32(&sys.pendingReset)
+		if resetKind(pending) >= kind {
+			return
+		}
+		if atomic.CompareAndSwapUint32(&sys.pendingReset, pending, uint32(kind)) {
+			return
+		}
+	}
+}
+
+// takePendingReset returns and clears the requested reset.
+func (sys *System) takePendingReset() resetKind {
+	return resetKind(atomic.SwapUint32(&sys.pendingReset, uint32(noReset)))
+}
+
+// executeScheduledReset executes a scheduled or requested reset and returns
+// whether a reset was executed. Requested resets are not executed while the
+// input is latched, as they are scheduled by the movie frame hooks instead.
+func (sys *System) executeScheduledReset() bool {
+	kind := sys.scheduledReset
+	if kind == noReset && !sys.inputLatched {
+		kind = sys.takePendingReset()
+	}
+	sys.scheduledReset = noReset
+
+	switch kind {
+	case softReset:
+		sys.Bus.PPU.Reset()
+		sys.CPU.Reset()
+	case powerCycle:
+		sys.powerCycle()
+	default:
+		return false
+	}
+	return true
+}
+
+// powerCycle resets the RAM, name tables, mapper, PPU and CPU to their power up
+// state. Battery backed PRG RAM keeps its content.
+func (sys *System) powerCycle() {
+	sys.Bus.Memory.Reset()
+	sys.Bus.NameTable = nametable.New(sys.Bus.Cartridge.Mirror)
+
+	m, err := mapper.New(sys.Bus)
+	if err != nil {
+		panic(fmt.Errorf("reinitializing mapper: %w", err))
+	}
+	if sys.hasBatteryRAM() {
+		copy(m.BatteryRAM(), sys.Bus.Mapper.BatteryRAM())
+	}
+	sys.Bus.Mapper = m
+	sys.connectExpansionAudio()
+
+	sys.Bus.PPU.PowerCycle()
+	sys.CPU.PowerCycle()
+	sys.CPU.SyncPPU()
+}
+
+// movieCommands returns the movie frame commands for the reset kind.
+func (k resetKind) movieCommands() byte {
+	switch k {
+	case softReset:
+		return movie.CommandSoftReset
+	case powerCycle:
+		return movie.CommandHardReset
+	default:
+		return 0
+	}
+}
+
+// resetKindFromMovie returns the reset kind for the movie frame commands.
+func resetKindFromMovie(commands byte) resetKind {
+	switch {
+	case commands&movie.CommandHardReset != 0:
+		return powerCycle
+	case commands&movie.CommandSoftReset != 0:
+		return softReset
+	default:
+		return noReset
+	}
+}
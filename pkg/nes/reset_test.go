@@ -0,0 +1,69 @@
+package nes
+
+import (
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestSystemReset(t *testing.T) {
+	r := NewRunner(testCartridge())
+	sys := r.System()
+	assert.NoError(t, r.RunFrames(3))
+	assert.NoError(t, r.RunUntil(testProgramLoop))
+
+	nmiCount := r.ReadMemory(0x01)
+	sp := sys.SP
+	sys.CPU.Flags.I = 0
+
+	sys.Reset()
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8000, sys.PC)
+	assert.Equal(t, sp-3, sys.SP)
+	assert.Equal(t, 1, sys.CPU.Flags.I)
+	assert.Equal(t, nmiCount, r.ReadMemory(0x01))
+	// the PPU control register got cleared
+	assert.Equal(t, 0, r.ReadMemory(0x2000))
+
+	assert.NoError(t, r.RunFrames(2))
+	assert.True(t, r.ReadMemory(0x01) > nmiCount)
+}
+
+func TestSystemPowerCycle(t *testing.T) {
+	r := NewRunner(testCartridge())
+	sys := r.System()
+	assert.NoError(t, r.RunFrames(3))
+	assert.NoError(t, r.RunUntil(testProgramLoop))
+	frame := r.Frame()
+
+	sys.Reset()
+	sys.PowerCycle()
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8000, sys.PC)
+	assert.Equal(t, 0xFD, sys.SP)
+	assert.Equal(t, 0, r.ReadMemory(0x00))
+	assert.Equal(t, 0, r.ReadMemory(0x01))
+	assert.Equal(t, frame+1, r.Frame())
+
+	// no further reset is pending
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8001, sys.PC)
+}
+
+func TestMovieResetCommands(t *testing.T) {
+	cart := testCartridge()
+	m := movie.New("test.nes", cart.PRG, cart.CHR)
+	m.Frames = make([]movie.Frame, 10)
+	m.Frames[8].Commands = movie.CommandHardReset
+
+	r := NewRunner(cart)
+	var nmiCount byte
+	r.System().AddFrameHook(func(frame uint64) {
+		if frame == 10 {
+			nmiCount = r.ReadMemory(0x01)
+		}
+	})
+	assert.NoError(t, r.PlayMovie(m))
+	assert.True(t, nmiCount < 3)
+}
@@ -0,0 +1,32 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"github.com/retroenv/nesgo/pkg/romdb"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// applyROMDatabase overrides the header fields of the cartridge with the
+// fields of its ROM database entry and passes all changed fields to the
+// ROM database handler.
+func applyROMDatabase(opts *Options, cart *cartridge.Cartridge) {
+	if opts.noROMDatabase {
+		return
+	}
+
+	db := opts.romDatabase
+	if db == nil {
+		var err error
+		db, err = romdb.Default()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	entry, changes := db.Apply(cart)
+	if len(changes) == 0 || opts.romDatabaseHandler == nil {
+		return
+	}
+	opts.romDatabaseHandler(entry, changes)
+}
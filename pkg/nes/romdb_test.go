@@ -0,0 +1,63 @@
+package nes
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/romdb"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func testROMDatabase(t *testing.T, cart *cartridge.Cartridge) *romdb.Database {
+	t.Helper()
+
+	crc := crc32.Update(crc32.ChecksumIEEE(cart.PRG), crc32.IEEETable, cart.CHR)
+	line := fmt.Sprintf("%08X 1 0 H 1 ntsc 0 8192 0 0 Battery Test", crc)
+	db, err := romdb.Load(strings.NewReader(line))
+	assert.NoError(t, err)
+	return db
+}
+
+func TestROMDatabase(t *testing.T) {
+	cart := testCartridgeWithProgram(testProgramBattery)
+	db := testROMDatabase(t, cart)
+
+	r := NewRunner(cart, WithROMDatabase(db))
+	assert.Equal(t, 1, cart.Mapper)
+	assert.Equal(t, 1, cart.Battery)
+	assert.Equal(t, cartridge.MirrorHorizontal, cart.Mirror)
+
+	assert.NoError(t, r.RunFrames(1))
+	assert.Equal(t, 0x42, r.ReadMemory(0x6000))
+}
+
+func TestROMDatabaseHandler(t *testing.T) {
+	cart := testCartridgeWithProgram(testProgramBattery)
+	db := testROMDatabase(t, cart)
+
+	var name string
+	var changes []string
+	_ = NewRunner(cart, WithROMDatabase(db), WithROMDatabaseHandler(func(entry romdb.Entry, fixed []string) {
+		name = entry.Name
+		changes = fixed
+	}))
+	assert.Equal(t, "Battery Test", name)
+	assert.Equal(t, []string{
+		"mapper 0 -> 1",
+		"mirroring vertical -> horizontal",
+		"battery false -> true",
+	}, changes)
+}
+
+func TestROMDatabaseDisabled(t *testing.T) {
+	cart := testCartridgeWithProgram(testProgramBattery)
+	db := testROMDatabase(t, cart)
+
+	_ = NewRunner(cart, WithROMDatabase(db), WithoutROMDatabase())
+	assert.Equal(t, 0, cart.Mapper)
+	assert.Equal(t, 0, cart.Battery)
+	assert.Equal(t, cartridge.MirrorVertical, cart.Mirror)
+}
@@ -0,0 +1,232 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/movie"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+const ramSize = 0x0800
+
+// ErrCycleLimit is returned by the Runner functions when the configured cycle limit was reached.
+var ErrCycleLimit = errors.New("cycle limit reached")
+
+var controllerButtons = []controller.Button{
+	controller.A,
+	controller.B,
+	controller.Select,
+	controller.Start,
+	controller.Up,
+	controller.Down,
+	controller.Left,
+	controller.Right,
+}
+
+// scheduledInput defines a controller state that gets set at the start of a frame.
+type scheduledInput struct {
+	port    int
+	buttons controller.Button
+}
+
+// Runner runs a NES program headless and synchronously in the calling goroutine.
+// It is intended to be used in tests, as it does not start a renderer and allows
+// to advance the emulation in well-defined steps.
+// As the instruction functions are linked globally, only one Runner or started
+// System can be used at a time.
+type Runner struct {
+	sys *System
+
+	cycleLimit uint64
+	inputs     map[uint64][]scheduledInput
+}
+
+// NewRunner creates a new headless runner for the given cartridge.
+// Options like WithEntrypoint or WithTracingTarget can be passed, the emulator
+// mode is always enabled and the GUI disabled.
+func NewRunner(cart *cartridge.Cartridge, options ...Option) *Runner {
+	options = append(options, WithEmulator(), WithCartridge(cart), WithDisabledGUI())
+	opts := NewOptions(options...)
+
+	sys := NewSystem(opts)
+	if opts.entrypoint >= 0 {
+		sys.PC = uint16(opts.entrypoint)
+	}
+	sys.LinkAliases()
+	sys.CPU.SetTracing(opts.tracing, opts.tracingTarget)
+	sys.CPU.SetTraceOptions(opts.traceOptions)
+
+	return &Runner{
+		sys:    sys,
+		inputs: map[uint64][]scheduledInput{},
+	}
+}
+
+// System returns the emulated system.
+func (r *Runner) System() *System {
+	return r.sys
+}
+
+// DumpTrace writes all trace lines of the ring buffer that is enabled by
+// WithTraceBuffer to the trace output and clears the buffer.
+func (r *Runner) DumpTrace() {
+	r.sys.CPU.DumpTrace()
+}
+
+// SetCycleLimit sets the maximum amount of CPU cycles that the system can execute
+// before all run functions return ErrCycleLimit. A limit of 0 disables the limit.
+func (r *Runner) SetCycleLimit(cycles uint64) {
+	r.cycleLimit = cycles
+}
+
+// RunFrames runs the emulation until the given amount of frames have been rendered.
+func (r *Runner) RunFrames(frames int) error {
+	target := r.Frame() + uint64(frames)
+	return r.runWhile(func() bool {
+		return r.Frame() < target
+	})
+}
+
+// RunUntil runs the emulation until the program counter reaches the given address.
+// If the program counter is already at the address, no instruction is executed.
+func (r *Runner) RunUntil(address uint16) error {
+	return r.runWhile(func() bool {
+		return r.sys.PC != address
+	})
+}
+
+// RunCycles runs the emulation for at least the given amount of CPU cycles.
+// As instructions are executed as a whole, more cycles can be executed than requested.
+func (r *Runner) RunCycles(cycles uint64) error {
+	target := r.sys.Cycles() + cycles
+	return r.runWhile(func() bool {
+		return r.sys.Cycles() < target
+	})
+}
+
+// runWhile executes instructions while the condition function returns true.
+func (r *Runner) runWhile(condition func() bool) error {
+	frame := r.Frame()
+	r.applyInputs(frame)
+
+	for condition() {
+		if r.cycleLimit > 0 && r.sys.Cycles() >= r.cycleLimit {
+			return ErrCycleLimit
+		}
+
+		if err := r.sys.Step(); err != nil {
+			return fmt.Errorf("executing instruction at $%04X: %w", r.sys.PC, err)
+		}
+
+		if newFrame := r.Frame(); newFrame != frame {
+			frame = newFrame
+			r.applyInputs(frame)
+		}
+	}
+	return nil
+}
+
+// applyInputs sets all controller states that are scheduled for the given frame.
+func (r *Runner) applyInputs(frame uint64) {
+	inputs, ok := r.inputs[frame]
+	if !ok {
+		return
+	}
+
+	for _, input := range inputs {
+		r.SetButtons(input.port, input.buttons)
+	}
+	delete(r.inputs, frame)
+}
+
+// ScheduleButtons schedules the controller state of the given port (1 to 4) to be set
+// to the passed buttons at the start of the given frame.
+func (r *Runner) ScheduleButtons(frame uint64, port int, buttons controller.Button) {
+	r.inputs[frame] = append(r.inputs[frame], scheduledInput{
+		port:    port,
+		buttons: buttons,
+	})
+}
+
+// SetButtons sets the state of all buttons of the controller of the given port (1 to 4),
+// all passed buttons are pressed, all others are released.
+func (r *Runner) SetButtons(port int, buttons controller.Button) {
+	r.controller(port).SetButtons(buttons)
+}
+
+// Press presses the given buttons on the controller of the given port (1 to 4).
+func (r *Runner) Press(port int, buttons controller.Button) {
+	r.setButtonsState(port, buttons, true)
+}
+
+// Release releases the given buttons on the controller of the given port (1 to 4).
+func (r *Runner) Release(port int, buttons controller.Button) {
+	r.setButtonsState(port, buttons, false)
+}
+
+func (r *Runner) setButtonsState(port int, buttons controller.Button, pressed bool) {
+	c := r.controller(port)
+	for _, button := range controllerButtons {
+		if buttons&button != 0 {
+			c.SetButtonState(button, pressed)
+		}
+	}
+}
+
+func (r *Runner) controller(port int) bus.Controller {
+	return r.sys.controller(port)
+}
+
+// Frame returns the number of the frame that is currently being rendered.
+func (r *Runner) Frame() uint64 {
+	return r.sys.Bus.PPU.Frame()
+}
+
+// Registers returns the current state of the CPU registers.
+func (r *Runner) Registers() bus.CPUState {
+	return r.sys.CPU.State()
+}
+
+// ReadMemory reads a byte from the CPU address space.
+// Reading from memory mapped registers can have side effects.
+func (r *Runner) ReadMemory(address uint16) byte {
+	return r.sys.Bus.Memory.Read(address)
+}
+
+// RAM returns a copy of the 2K internal RAM.
+func (r *Runner) RAM() []byte {
+	return r.sys.ram()
+}
+
+// PlayMovie replays the input of the movie and verifies the RAM checksum of the
+// movie after the last frame. To be deterministic, the movie has to be played
+// on a newly created runner.
+func (r *Runner) PlayMovie(m *movie.Movie) error {
+	cart := r.sys.Bus.Cartridge
+	if err := m.VerifyROM(cart.PRG, cart.CHR); err != nil {
+		return err
+	}
+
+	finished := false
+	var result error
+	r.sys.playMovie(m, func(err error) {
+		finished = true
+		result = err
+	})
+
+	if err := r.runWhile(func() bool { return !finished }); err != nil {
+		return err
+	}
+	return result
+}
+
+// Image returns the last fully rendered frame.
+func (r *Runner) Image() *image.RGBA {
+	return r.sys.Bus.PPU.Image()
+}
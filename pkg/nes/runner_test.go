@@ -0,0 +1,209 @@
+package nes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/controller"
+	"github.com/retroenv/nesgo/pkg/inputscript"
+	"github.com/retroenv/nesgo/pkg/screenshot"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+const (
+	testProgramLoop = 0x8006
+	testProgramNmi  = 0x800B
+)
+
+// testProgram increments $00 in an endless loop, increments $01 in the NMI handler and
+// stores the state of the A button of controller 1 in $02.
+var testProgram = []byte{
+	0x78,       // sei
+	0xA9, 0x80, // lda #$80
+	0x8D, 0x00, 0x20, // sta PPU_CTRL
+	0xE6, 0x00, // loop: inc $00
+	0x4C, 0x06, 0x80, // jmp loop
+	0xE6, 0x01, // nmi: inc $01
+	0xA9, 0x01, // lda #$01
+	0x8D, 0x16, 0x40, // sta JOYPAD1
+	0xA9, 0x00, // lda #$00
+	0x8D, 0x16, 0x40, // sta JOYPAD1
+	0xAD, 0x16, 0x40, // lda JOYPAD1
+	0x85, 0x02, // sta $02
+	0x40, // rti
+}
+
+func testCartridge() *cartridge.Cartridge {
+	return testCartridgeWithProgram(testProgram)
+}
+
+// testCartridgeWithProgram returns a cartridge that starts the given program at $8000.
+func testCartridgeWithProgram(program []byte) *cartridge.Cartridge {
+	cart := cartridge.New()
+	copy(cart.PRG, program)
+
+	vectors := []byte{
+		0x0B, 0x80, // NMI
+		0x00, 0x80, // reset
+		0x00, 0x80, // IRQ
+	}
+	copy(cart.PRG[len(cart.PRG)-6:], vectors)
+	return cart
+}
+
+func TestRunnerRunFrames(t *testing.T) {
+	r := NewRunner(testCartridge())
+
+	assert.NoError(t, r.RunFrames(1))
+	nmiCount := r.ReadMemory(0x01)
+	frame := r.Frame()
+
+	assert.NoError(t, r.RunFrames(3))
+	assert.Equal(t, frame+3, r.Frame())
+	assert.Equal(t, nmiCount+3, r.ReadMemory(0x01))
+}
+
+func TestRunnerRunUntil(t *testing.T) {
+	r := NewRunner(testCartridge())
+
+	assert.NoError(t, r.RunUntil(testProgramLoop))
+	assert.Equal(t, 0x80, r.Registers().A)
+
+	assert.NoError(t, r.RunUntil(testProgramNmi))
+	assert.Equal(t, testProgramNmi, r.Registers().PC)
+	assert.True(t, r.Registers().Interrupts.NMIRunning)
+}
+
+func TestRunnerRunCycles(t *testing.T) {
+	r := NewRunner(testCartridge())
+
+	start := r.Registers().Cycles
+	assert.NoError(t, r.RunCycles(100))
+	assert.True(t, r.Registers().Cycles >= start+100)
+
+	ram := r.RAM()
+	assert.Equal(t, ramSize, len(ram))
+	assert.True(t, ram[0] > 0)
+}
+
+func TestRunnerCycleLimit(t *testing.T) {
+	r := NewRunner(testCartridge())
+	r.SetCycleLimit(r.Registers().Cycles + 50)
+
+	err := r.RunUntil(0x9000)
+	assert.True(t, errors.Is(err, ErrCycleLimit))
+}
+
+func TestRunnerInput(t *testing.T) {
+	r := NewRunner(testCartridge())
+
+	r.Press(1, controller.A)
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 1, r.ReadMemory(0x02))
+
+	r.Release(1, controller.A)
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 0, r.ReadMemory(0x02))
+
+	frame := r.Frame()
+	r.ScheduleButtons(frame+2, 1, controller.A|controller.Start)
+	assert.NoError(t, r.RunFrames(1))
+	assert.Equal(t, 0, r.ReadMemory(0x02))
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 1, r.ReadMemory(0x02))
+}
+
+func TestRunnerImage(t *testing.T) {
+	r := NewRunner(testCartridge())
+	assert.NoError(t, r.RunFrames(1))
+
+	img := r.Image()
+	assert.Equal(t, 256, img.Bounds().Dx())
+	assert.Equal(t, 240, img.Bounds().Dy())
+}
+
+func TestRunnerScreenshot(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "frame.png")
+	r := NewRunner(testCartridge(), WithScreenshot(2, fileName))
+
+	var frames []uint64
+	r.System().AddFrameHook(func(frame uint64) {
+		frames = append(frames, frame)
+	})
+	assert.NoError(t, r.RunFrames(4))
+	assert.Equal(t, []uint64{0, 1, 2, 3}, frames)
+
+	result, err := screenshot.CompareFile(r.Image(), fileName, 0)
+	assert.NoError(t, err)
+	assert.True(t, result.Equal())
+}
+
+func TestRunnerScreenshotError(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "missing", "frame.png")
+	r := NewRunner(testCartridge(), WithScreenshot(1, fileName))
+
+	assert.NoError(t, r.RunFrames(2))
+	_, err := os.Stat(fileName)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunnerInputScript(t *testing.T) {
+	script, err := inputscript.Parse(strings.NewReader("frame 3: press A; frame 6: release A"))
+	assert.NoError(t, err)
+	r := NewRunner(testCartridge(), WithInputScript(script))
+
+	assert.NoError(t, r.RunFrames(3))
+	assert.Equal(t, 0, r.ReadMemory(0x02))
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 1, r.ReadMemory(0x02))
+	assert.NoError(t, r.RunFrames(3))
+	assert.Equal(t, 0, r.ReadMemory(0x02))
+}
+
+// testProgramFourScore stores the 9th bit read from JOYPAD1 in $02 in the NMI handler,
+// which is the A button of player 3 when a Four Score adapter is connected.
+var testProgramFourScore = []byte{
+	0x78,       // sei
+	0xA9, 0x80, // lda #$80
+	0x8D, 0x00, 0x20, // sta PPU_CTRL
+	0xE6, 0x00, // loop: inc $00
+	0x4C, 0x06, 0x80, // jmp loop
+	0xA9, 0x01, // nmi: lda #$01
+	0x8D, 0x16, 0x40, // sta JOYPAD1
+	0xA9, 0x00, // lda #$00
+	0x8D, 0x16, 0x40, // sta JOYPAD1
+	0xA2, 0x09, // ldx #9
+	0xAD, 0x16, 0x40, // read: lda JOYPAD1
+	0xCA,       // dex
+	0xD0, 0xFA, // bne read
+	0x85, 0x02, // sta $02
+	0x40, // rti
+}
+
+func TestRunnerFourScore(t *testing.T) {
+	r := NewRunner(testCartridgeWithProgram(testProgramFourScore), WithFourScore())
+
+	r.Press(3, controller.A)
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 1, r.ReadMemory(0x02))
+
+	r.Release(3, controller.A)
+	assert.NoError(t, r.RunFrames(2))
+	assert.Equal(t, 0, r.ReadMemory(0x02))
+}
+
+func TestRunnerInputDevice(t *testing.T) {
+	r := NewRunner(testCartridge(), WithInputDevice(2, controller.DeviceZapper))
+
+	_, ok := r.System().InputDevice(1).(*controller.Controller)
+	assert.True(t, ok)
+	zapper, ok := r.System().InputDevice(2).(*controller.Zapper)
+	assert.True(t, ok)
+
+	zapper.SetTrigger(true)
+	assert.Equal(t, 0x18, r.ReadMemory(controller.JOYPAD2))
+}
@@ -0,0 +1,32 @@
+//go:build !nesgo
+
+package nes
+
+import (
+	"fmt"
+
+	"github.com/retroenv/nesgo/pkg/screenshot"
+)
+
+// addScreenshotHooks adds a frame hook that saves the configured screenshots.
+// Errors are printed like for screenshots of the hotkey, as a failed write
+// should not stop the emulation.
+func (sys *System) addScreenshotHooks(screenshots map[uint64][]string) {
+	if len(screenshots) == 0 {
+		return
+	}
+
+	sys.AddFrameHook(func(frame uint64) {
+		fileNames, ok := screenshots[frame]
+		if !ok {
+			return
+		}
+
+		img := sys.Bus.PPU.Image()
+		for _, fileName := range fileNames {
+			if err := screenshot.Save(img, fileName); err != nil {
+				fmt.Printf("Saving screenshot of frame %d failed: %s\n", frame, err.Error())
+			}
+		}
+	})
+}
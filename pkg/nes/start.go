@@ -3,9 +3,9 @@
 package nes
 
 import (
+	"github.com/retroenv/nesgo/pkg/gui"
 	"github.com/retroenv/nesgo/pkg/nes/debugger"
 	"github.com/retroenv/retrogolib/app"
-	"github.com/retroenv/retrogolib/gui"
 )
 
 // Start is the main entrypoint for a NES program that starts the execution.
@@ -29,10 +29,23 @@ func Start(resetHandlerParam func(), options ...Option) {
 	sys.LinkAliases()
 
 	sys.CPU.SetTracing(opts.tracing, opts.tracingTarget)
+	sys.CPU.SetTraceOptions(opts.traceOptions)
+	sys.startPacing()
 
+	var recorder *movieRecorder
 	if opts.emulator {
 		sys.ResetHandler = func() {
-			sys.runEmulatorSteps(opts.stopAt)
+			sys.runEmulatorSteps(opts)
+		}
+
+		if opts.movieRecording != nil {
+			recorder = sys.recordMovie(opts.movieRecording)
+		}
+		if opts.moviePlayback != nil {
+			sys.playMovie(opts.moviePlayback, func(err error) {
+				opts.moviePlaybackDone(err)
+				sys.requestStop()
+			})
 		}
 	} else {
 		sys.ResetHandler = resetHandlerParam
@@ -42,7 +55,7 @@ func Start(resetHandlerParam func(), options ...Option) {
 	ctx := app.Context()
 	var debugServer *debugger.Debugger
 	if opts.debug {
-		debugServer = debugger.New(opts.debugAddress, sys.Bus)
+		debugServer = debugger.New(opts.debugAddress, sys.Bus, sys)
 		go debugServer.Start(ctx)
 	}
 
@@ -53,4 +66,14 @@ func Start(resetHandlerParam func(), options ...Option) {
 	if err := sys.runRenderer(ctx, opts, guiStarter); err != nil {
 		panic(err)
 	}
+
+	if opts.emulator {
+		sys.stopEmulation()
+	}
+	if recorder != nil {
+		recorder.finish()
+	}
+	if err := sys.WriteBatterySave(); err != nil {
+		panic(err)
+	}
 }
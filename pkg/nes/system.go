@@ -6,17 +6,25 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/retroenv/nesgo/pkg/apu"
 	"github.com/retroenv/nesgo/pkg/bus"
+	"github.com/retroenv/nesgo/pkg/cdl"
+	"github.com/retroenv/nesgo/pkg/cheat"
 	"github.com/retroenv/nesgo/pkg/controller"
 	"github.com/retroenv/nesgo/pkg/cpu"
+	"github.com/retroenv/nesgo/pkg/gamegenie"
+	"github.com/retroenv/nesgo/pkg/inputmap"
 	"github.com/retroenv/nesgo/pkg/mapper"
 	"github.com/retroenv/nesgo/pkg/memory"
+	"github.com/retroenv/nesgo/pkg/nes/pacer"
 	"github.com/retroenv/nesgo/pkg/ppu"
 	"github.com/retroenv/nesgo/pkg/ppu/nametable"
 	"github.com/retroenv/nesgo/pkg/ppu/screen"
+	"github.com/retroenv/nesgo/pkg/profiler"
 	"github.com/retroenv/retrogolib/arch/cpu/m6502"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 	cpulib "github.com/retroenv/retrogolib/cpu"
@@ -33,7 +41,42 @@ type System struct {
 	IrqHandler   func()
 	ResetHandler func()
 
-	dimensions gui.Dimensions
+	dimensions  gui.Dimensions
+	frameHooks  []func(frame uint64)
+	frameSignal chan struct{}
+	pacer       *pacer.Pacer
+
+	stopped       uint32        // set atomically to request the emulation to stop
+	emulationDone chan struct{} // closed when the emulation loop exited
+
+	// emulationMu is held by the emulation loop while it is not waiting for
+	// the pacer, functions that are called from other goroutines lock it to
+	// access the emulation state between two frames.
+	emulationMu   sync.Mutex
+	emulationLoop bool // set when the emulation loop holds emulationMu
+
+	inputLatched   bool
+	pendingButtons [2]uint64 // latched button states, accessed atomically
+
+	gamepads [4]*controller.Controller
+
+	battery *batterySave
+	mixer   *apu.Mixer
+
+	cheats    *gamegenie.List
+	rawCheats *cheat.List
+
+	codeDataLog *cdl.Logger
+	profiler    *profiler.Profiler
+
+	pendingReset   uint32    // requested resetKind, accessed atomically
+	scheduledReset resetKind // reset to execute before the next step
+
+	inputMapping        *inputmap.Mapping
+	hotkeyHandlers      map[inputmap.Action]func(pressed bool)
+	fastForwardMode     pacer.Mode // pacing mode to restore after fast-forwarding
+	screenshotDir       string
+	screenshotRequested uint32 // set atomically by the screenshot hotkey
 }
 
 // NewSystem creates a new NES system.
@@ -44,15 +87,24 @@ func NewSystem(opts *Options) *System {
 	cart := opts.cartridge
 	if cart == nil {
 		cart = cartridge.New()
+	} else {
+		applyROMDatabase(opts, cart)
 	}
 
+	gamepads := [4]*controller.Controller{
+		controller.New(), controller.New(), controller.New(), controller.New(),
+	}
 	systemBus := &bus.Bus{
 		Cartridge:   cart,
-		Controller1: controller.New(),
-		Controller2: controller.New(),
+		Controller1: gamepads[0],
+		Controller2: gamepads[1],
+		Disk:        opts.disk,
 		NameTable:   nametable.New(cart.Mirror),
 	}
 	systemBus.Memory = memory.New(systemBus)
+	if opts.codeDataLog != nil {
+		systemBus.CodeDataLog = opts.codeDataLog
+	}
 
 	var err error
 	systemBus.Mapper, err = mapper.New(systemBus)
@@ -69,14 +121,58 @@ func NewSystem(opts *Options) *System {
 			Height:      screen.Height,
 			Width:       screen.Width,
 		},
+		frameSignal:   make(chan struct{}, 1),
+		emulationDone: make(chan struct{}),
+		pacer:         pacer.New(opts.region.Timing().FrameRate),
+		gamepads:      gamepads,
+		inputMapping:  opts.inputMapping,
+		mixer:         apu.NewMixer(),
+		codeDataLog:   opts.codeDataLog,
+		profiler:      opts.profiler,
 	}
+	if sys.inputMapping == nil {
+		sys.inputMapping = inputmap.Default()
+	}
+	sys.pacer.SetSpeed(opts.speed)
+	sys.pacer.SetMode(opts.pacingMode)
 
 	sys.CPU = cpu.New(systemBus, &sys.NmiHandler, &sys.IrqHandler, opts.emulator)
+	sys.CPU.SetRegion(opts.region)
 	systemBus.CPU = sys.CPU
-	systemBus.PPU = ppu.New(systemBus)
+	p := ppu.New(systemBus)
+	p.SetRegion(opts.region)
+	p.SetFrameHandler(sys.frameFinished)
+	systemBus.PPU = p
+	sys.connectInputDevices(opts, p)
+	sys.connectExpansionAudio()
+	sys.CPU.SyncPPU() // advance the PPU for the reset sequence of the CPU
+
+	sys.setupHotkeys(opts)
+	if opts.movieRecording == nil && opts.moviePlayback == nil {
+		sys.setupBatterySave(opts.saveFile)
+	}
+	sys.setupCheats(opts)
+	sys.addInputScriptHook(opts.inputScript)
+	sys.addScreenshotHooks(opts.screenshots)
+	if sys.profiler != nil {
+		sys.AddFrameHook(sys.profiler.FrameFinished)
+	}
 	return sys
 }
 
+// AddFrameHook adds a hook that gets called every time the PPU has finished
+// rendering a frame. The hook is called from the emulation goroutine and gets
+// passed the number of the rendered frame.
+func (sys *System) AddFrameHook(hook func(frame uint64)) {
+	sys.frameHooks = append(sys.frameHooks, hook)
+}
+
+func (sys *System) frameFinished(frame uint64) {
+	for _, hook := range sys.frameHooks {
+		hook(frame)
+	}
+}
+
 // LinkAliases links the register and CPU instruction globals to the actual instance.
 // Can not be used in tests in combination with t.Parallel().
 func (sys *System) LinkAliases() {
@@ -90,7 +186,13 @@ func (sys *System) LinkAliases() {
 }
 
 // DecodeInstructionAtPC decodes the current instruction at the program counter.
+// If code/data logging is enabled, the reads of the instruction bytes are logged
+// as code until the instruction gets executed.
 func (sys *System) DecodeInstructionAtPC() (cpulib.Opcode, error) {
+	if sys.codeDataLog != nil {
+		sys.codeDataLog.SetCodeFetch(*PC)
+	}
+
 	b := sys.Bus.Memory.Read(*PC)
 	opcode := m6502.Opcodes[b]
 	if opcode.Instruction == nil {
@@ -108,38 +210,133 @@ func (sys *System) DecodeInstructionAtPC() (cpulib.Opcode, error) {
 	return opcode, nil
 }
 
-// runEmulatorSteps runs the emulator until it is quit or reaches the given stop address.
-func (sys *System) runEmulatorSteps(stopAt int) {
-	for {
-		if stopAt >= 0 && sys.PC == uint16(stopAt) {
+// runEmulatorSteps runs the emulator until it is quit or reaches the configured
+// stop address or frame.
+func (sys *System) runEmulatorSteps(opts *Options) {
+	defer close(sys.emulationDone)
+
+	sys.emulationMu.Lock()
+	sys.emulationLoop = true
+	defer func() {
+		sys.emulationLoop = false
+		sys.emulationMu.Unlock()
+	}()
+	defer func() {
+		if err := recover(); err != nil {
+			sys.CPU.DumpTrace()
+			panic(err)
+		}
+	}()
+
+	if opts.stopAtFrame > 0 {
+		sys.AddFrameHook(func(frame uint64) {
+			if frame >= opts.stopAtFrame {
+				sys.requestStop()
+			}
+		})
+	}
+
+	for atomic.LoadUint32(&sys.stopped) == 0 {
+		if opts.stopAt >= 0 && sys.PC == uint16(opts.stopAt) {
+			sys.CPU.DumpTrace()
 			return
 		}
 
-		sys.CPU.CheckInterrupts()
-		sys.runEmulatorStep()
+		if err := sys.Step(); err != nil {
+			panic(err)
+		}
 	}
 }
 
-func (sys *System) runEmulatorStep() {
+// requestStop requests the emulation loop to stop.
+func (sys *System) requestStop() {
+	atomic.StoreUint32(&sys.stopped, 1)
+}
+
+// stopEmulation stops the emulation loop and waits for it to exit.
+func (sys *System) stopEmulation() {
+	sys.requestStop()
+	// unblock a paused emulation
+	sys.pacer.SetMode(pacer.Turbo)
+	<-sys.emulationDone
+}
+
+// stepKind defines the kind of scheduling unit that was executed by a step.
+type stepKind int
+
+const (
+	resetStep stepKind = iota
+	stallStep
+	interruptStep
+	instructionStep
+)
+
+// Step executes the next scheduling unit of the emulator, which is either a
+// requested reset, the pending DMA stall cycles, a triggered interrupt or the
+// next instruction.
+// The PPU is advanced in lockstep for all executed CPU cycles, including the
+// extra cycles of taken branches, page crossings and interrupts.
+func (sys *System) Step() error {
+	defer sys.CPU.SyncPPU()
+
+	if sys.profiler != nil {
+		return sys.profiledStep()
+	}
+	_, err := sys.step()
+	return err
+}
+
+func (sys *System) step() (stepKind, error) {
+	if sys.executeScheduledReset() {
+		return resetStep, nil
+	}
+	if sys.CPU.ExecuteStallCycles() {
+		return stallStep, nil
+	}
+	if sys.CPU.CheckInterrupts() {
+		return interruptStep, nil
+	}
+
 	oldPC := *PC
 	opcode, err := sys.DecodeInstructionAtPC()
 	if err != nil {
-		panic(err)
+		return instructionStep, err
 	}
 
 	ins := opcode.Instruction
 	if ins.NoParamFunc != nil {
+		sys.clearCodeFetch()
 		ins.NoParamFunc()
 		sys.updatePC(ins, oldPC, 1)
-		return
+		return instructionStep, nil
 	}
 
 	params, opcodes, pageCrossed := ReadOpParams(sys.Bus.Memory, opcode.Addressing, true)
 	sys.TraceStep.Opcode = append(sys.TraceStep.Opcode, opcodes...)
 	sys.TraceStep.PageCrossed = pageCrossed
+	sys.clearCodeFetch()
 
 	ins.ParamFunc(params...)
 	sys.updatePC(ins, oldPC, len(sys.TraceStep.Opcode))
+	return instructionStep, nil
+}
+
+// StepFrame executes steps until the PPU has started rendering the next frame.
+func (sys *System) StepFrame() error {
+	frame := sys.Bus.PPU.Frame()
+	for sys.Bus.PPU.Frame() == frame {
+		if err := sys.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearCodeFetch logs all following PRG reads as data reads of the executed instruction.
+func (sys *System) clearCodeFetch() {
+	if sys.codeDataLog != nil {
+		sys.codeDataLog.ClearCodeFetch()
+	}
 }
 
 func (sys *System) updatePC(ins *cpulib.Instruction, oldPC uint16, amount int) {
@@ -172,7 +369,7 @@ func (sys *System) runRenderer(ctx context.Context, opts *Options, guiStarter gu
 	running := uint64(1)
 	go func() {
 		sys.ResetHandler()
-		if opts.stopAt >= 0 {
+		if opts.emulator || opts.stopAt >= 0 {
 			atomic.StoreUint64(&running, 0)
 			return
 		}
@@ -198,12 +395,24 @@ func (sys *System) runRenderer(ctx context.Context, opts *Options, guiStarter gu
 			atomic.StoreUint64(&running, 0)
 		}
 
-		// TODO replace with better solution
-		time.Sleep(time.Second / ppu.FPS)
+		sys.waitForFrame(ctx)
 	}
 	return nil
 }
 
+// waitForFrame waits until a new frame has been rendered. A timeout of one
+// frame keeps the GUI responsive while the emulation is paused or slowed down.
+func (sys *System) waitForFrame(ctx context.Context) {
+	timer := time.NewTimer(sys.pacer.FrameDuration())
+	defer timer.Stop()
+
+	select {
+	case <-sys.frameSignal:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
 // Image returns the emulator screen to show.
 func (sys *System) Image() *image.RGBA {
 	return sys.Bus.PPU.Image()
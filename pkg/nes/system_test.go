@@ -0,0 +1,91 @@
+package nes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestSystemStepDMAStall(t *testing.T) {
+	program := []byte{
+		0xA9, 0x02, // lda #$02
+		0x8D, 0x14, 0x40, // sta OAM_DMA
+		0xEA, // nop
+	}
+	sys := NewRunner(testCartridgeWithProgram(program)).System()
+
+	assert.NoError(t, sys.Step())
+	assert.NoError(t, sys.Step())
+	cycles := sys.Cycles()
+	assert.Equal(t, 0x8005, sys.PC)
+
+	assert.NoError(t, sys.Step())
+	stalled := sys.Cycles() - cycles
+	assert.True(t, stalled == 513 || stalled == 514)
+	assert.Equal(t, 0x8005, sys.PC)
+
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8006, sys.PC)
+	assert.Equal(t, cycles+stalled+2, sys.Cycles())
+}
+
+func TestSystemStepIrqPolling(t *testing.T) {
+	program := []byte{
+		0x78, // sei
+		0xEA, // nop
+		0x58, // cli
+		0xEA, // nop
+		0xEA, // nop
+	}
+	sys := NewRunner(testCartridgeWithProgram(program)).System()
+
+	assert.NoError(t, sys.Step())
+	sys.TriggerIrq()
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8002, sys.PC)
+
+	// the cleared interrupt flag of cli takes effect after the next instruction
+	assert.NoError(t, sys.Step())
+	assert.NoError(t, sys.Step())
+	assert.Equal(t, 0x8004, sys.PC)
+	assert.False(t, sys.State().Interrupts.IrqRunning)
+
+	assert.NoError(t, sys.Step())
+	assert.True(t, sys.State().Interrupts.IrqRunning)
+	assert.Equal(t, 0x8000, sys.PC)
+}
+
+func TestSystemStepFrame(t *testing.T) {
+	sys := NewRunner(testCartridge()).System()
+
+	frame := sys.Bus.PPU.Frame()
+	assert.NoError(t, sys.StepFrame())
+	assert.Equal(t, frame+1, sys.Bus.PPU.Frame())
+	assert.NoError(t, sys.StepFrame())
+	assert.Equal(t, frame+2, sys.Bus.PPU.Frame())
+}
+
+func TestSystemRegionTiming(t *testing.T) {
+	tests := []struct {
+		region region.Region
+		cycles uint64 // CPU cycles of 2 frames
+	}{
+		{region.NTSC, 59561},
+		{region.PAL, 66495},
+		{region.Dendy, 70928},
+	}
+
+	for _, test := range tests {
+		r := NewRunner(testCartridge(), WithRegion(test.region))
+		assert.NoError(t, r.RunFrames(1))
+
+		start := r.Registers().Cycles
+		assert.NoError(t, r.RunFrames(2))
+		cycles := r.Registers().Cycles - start
+		// instructions are executed as a whole and can exceed a frame boundary
+		assert.True(t, cycles+7 >= test.cycles && cycles <= test.cycles+7,
+			fmt.Sprintf("%s: unexpected cycles %d", test.region, cycles))
+	}
+}
@@ -2,8 +2,12 @@
 
 package ppu
 
-import "image/color"
+import (
+	"image/color"
+	"math"
+)
 
+// colors contains the NTSC palette.
 var colors = [64]color.RGBA{
 	{0x58, 0x58, 0x58, 0xFF},
 	{0x00, 0x23, 0x7C, 0xFF},
@@ -70,3 +74,31 @@ var colors = [64]color.RGBA{
 	{0x00, 0x00, 0x00, 0xFF},
 	{0x00, 0x00, 0x00, 0xFF},
 }
+
+// shiftHue returns the palette with the hue of all colors rotated by the given
+// angle in degrees. The rotation is done in the YIQ color space, which keeps the
+// brightness of the colors and does not change the grays.
+func shiftHue(palette [64]color.RGBA, degrees float64) [64]color.RGBA {
+	if degrees == 0 {
+		return palette
+	}
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	for i, c := range palette {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		y := 0.299*r + 0.587*g + 0.114*b
+		in := 0.5959*r - 0.2746*g - 0.3213*b
+		q := 0.2115*r - 0.5227*g + 0.3112*b
+
+		in, q = in*cos-q*sin, in*sin+q*cos
+
+		palette[i].R = clampColor(y + 0.956*in + 0.619*q)
+		palette[i].G = clampColor(y - 0.272*in - 0.647*q)
+		palette[i].B = clampColor(y - 1.106*in + 1.703*q)
+	}
+	return palette
+}
+
+func clampColor(value float64) byte {
+	return byte(math.Max(0, math.Min(255, math.Round(value))))
+}
@@ -0,0 +1,37 @@
+//go:build !nesgo
+
+package ppu
+
+import "image/color"
+
+// emphasisAttenuation is the factor in percent that the color channels that are
+// not emphasized get darkened by.
+const emphasisAttenuation = 82
+
+// emphasize applies the color emphasis of the mask register to the color.
+// The PAL and Dendy PPUs have the red and green emphasis bits swapped.
+func (p *PPU) emphasize(c color.RGBA) color.RGBA {
+	red, green, blue := p.mask.EnhanceRed, p.mask.EnhanceGreen, p.mask.EnhanceBlue
+	if !red && !green && !blue {
+		return c
+	}
+	if p.timing.SwapEmphasis {
+		red, green = green, red
+	}
+
+	// emphasizing a channel attenuates the other channels
+	if !red || (green && blue) {
+		c.R = attenuate(c.R)
+	}
+	if !green || (red && blue) {
+		c.G = attenuate(c.G)
+	}
+	if !blue || (red && green) {
+		c.B = attenuate(c.B)
+	}
+	return c
+}
+
+func attenuate(value byte) byte {
+	return byte(int(value) * emphasisAttenuation / 100)
+}
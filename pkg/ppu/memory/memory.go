@@ -8,18 +8,18 @@ import (
 )
 
 // Memory implements PPU memory support.
+// Pattern table and nametable accesses are passed to the mapper, which allows
+// mappers to observe all PPU fetches.
 type Memory struct {
-	mapper    bus.Mapper
-	nametable bus.NameTable
-	palette   bus.BasicMemory
+	mapper  bus.Mapper
+	palette bus.BasicMemory
 }
 
 // New returns a new memory manager.
-func New(mapper bus.Mapper, nametable bus.NameTable, palette bus.BasicMemory) *Memory {
+func New(mapper bus.Mapper, palette bus.BasicMemory) *Memory {
 	return &Memory{
-		mapper:    mapper,
-		nametable: nametable,
-		palette:   palette,
+		mapper:  mapper,
+		palette: palette,
 	}
 }
 
@@ -27,30 +27,19 @@ func New(mapper bus.Mapper, nametable bus.NameTable, palette bus.BasicMemory) *M
 func (m *Memory) Read(address uint16) uint8 {
 	address &= 0x3FFF // valid addresses are $0000-$3FFF; higher addresses will be mirrored down
 
-	switch {
-	case address < 0x2000:
-		return m.mapper.Read(address)
-
-	case address < 0x3F00:
-		return m.nametable.Read(address)
-
-	default: // >= 0x3F00
-		return m.palette.Read(address)
+	if address < 0x3F00 {
+		return m.mapper.ReadPPU(address)
 	}
+	return m.palette.Read(address)
 }
 
 // Write to a PPU memory address.
 func (m *Memory) Write(address uint16, value uint8) {
 	address &= 0x3FFF // valid addresses are $0000-$3FFF; higher addresses will be mirrored down
 
-	switch {
-	case address < 0x2000:
-		m.mapper.Write(address, value)
-
-	case address < 0x3F00:
-		m.nametable.Write(address, value)
-
-	default: // >= 0x3F00
-		m.palette.Write(address, value)
+	if address < 0x3F00 {
+		m.mapper.WritePPU(address, value)
+		return
 	}
+	m.palette.Write(address, value)
 }
@@ -4,6 +4,8 @@
 package ppu
 
 import (
+	"image/color"
+
 	"github.com/retroenv/nesgo/pkg/bus"
 	"github.com/retroenv/nesgo/pkg/ppu/addressing"
 	"github.com/retroenv/nesgo/pkg/ppu/control"
@@ -16,10 +18,10 @@ import (
 	"github.com/retroenv/nesgo/pkg/ppu/sprites"
 	"github.com/retroenv/nesgo/pkg/ppu/status"
 	"github.com/retroenv/nesgo/pkg/ppu/tiles"
+	"github.com/retroenv/nesgo/pkg/region"
 )
 
 const (
-	FPS    = 60
 	Height = screen.Height
 	Width  = screen.Width
 )
@@ -42,17 +44,30 @@ type PPU struct {
 	sprites     *sprites.Sprites
 	status      *status.Status
 	tiles       *tiles.Tiles
+
+	frameHandler func(frame uint64)
+	timing       region.Timing
+	colors       [64]color.RGBA // palette of the region
 }
 
 // New returns a new PPU.
 func New(bus *bus.Bus) *PPU {
 	p := &PPU{
-		bus: bus,
+		bus:    bus,
+		timing: region.NTSC.Timing(),
+		colors: colors,
 	}
 	p.reset()
 	return p
 }
 
+// SetRegion sets the region that defines the frame timing, palette and color emphasis.
+func (p *PPU) SetRegion(r region.Region) {
+	p.timing = r.Timing()
+	p.colors = shiftHue(colors, p.timing.HueShift)
+	p.renderState.SetTiming(p.timing)
+}
+
 func (p *PPU) reset() {
 	p.fineX = 0
 	p.dataReadBuffer = 0
@@ -61,19 +76,24 @@ func (p *PPU) reset() {
 	p.mask = mask.New()
 	p.nmi = nmi.New()
 	p.palette = palette.New()
-	p.renderState = renderstate.New()
+	p.renderState = renderstate.New(p.timing)
 	p.screen = screen.New()
 	p.status = status.New()
 
-	p.memory = memory.New(p.bus.Mapper, p.bus.NameTable, p.palette)
-	p.sprites = sprites.New(p.bus.CPU, p.bus.Mapper, p.bus.Memory, p.renderState, p.status)
+	p.memory = memory.New(p.bus.Mapper, p.palette)
+	p.sprites = sprites.New(p.bus.CPU, p.memory, p.bus.Memory, p.renderState, p.status)
 
-	p.tiles = tiles.New(p.addressing, p.memory, p.bus.NameTable)
+	p.tiles = tiles.New(p.addressing, p.memory)
 
 	p.control = control.New(p.addressing, p.nmi, p.sprites, p.tiles)
 }
 
 func (p *PPU) readData() byte {
+	if p.bus.CodeDataLog != nil {
+		p.bus.CodeDataLog.SetChrDataRead(true)
+		defer p.bus.CodeDataLog.SetChrDataRead(false)
+	}
+
 	address := p.addressing.Address()
 	address &= 0x3FFF // valid addresses are $0000-$3FFF; higher addresses will be mirrored down
 
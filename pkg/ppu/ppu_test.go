@@ -5,6 +5,7 @@ import (
 
 	"github.com/retroenv/nesgo/pkg/bus"
 	"github.com/retroenv/nesgo/pkg/mapper"
+	"github.com/retroenv/nesgo/pkg/region"
 	"github.com/retroenv/retrogolib/arch/nes/cartridge"
 	"github.com/retroenv/retrogolib/assert"
 )
@@ -51,3 +52,23 @@ func TestSetMask(t *testing.T) {
 	assert.True(t, p.mask.EnhanceGreen)
 	assert.True(t, p.mask.EnhanceBlue)
 }
+
+// TestSetRegionPalette verifies that the PAL palette has its hue shifted.
+func TestSetRegionPalette(t *testing.T) {
+	t.Parallel()
+
+	sys := &bus.Bus{
+		Cartridge: cartridge.New(),
+	}
+	sys.Mapper = mapper.NewMockMapper(sys)
+	p := New(sys)
+	assert.Equal(t, colors, p.colors)
+
+	p.SetRegion(region.PAL)
+	assert.Equal(t, colors[0x00], p.colors[0x00])
+	assert.Equal(t, colors[0x20], p.colors[0x20])
+	assert.True(t, colors[0x16] != p.colors[0x16])
+
+	p.SetRegion(region.NTSC)
+	assert.Equal(t, colors, p.colors)
+}
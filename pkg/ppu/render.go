@@ -4,6 +4,7 @@ package ppu
 
 import (
 	"image"
+	"image/color"
 )
 
 // Image returns the rendered image to display.
@@ -11,6 +12,55 @@ func (p *PPU) Image() *image.RGBA {
 	return p.screen.Image()
 }
 
+// Frame returns the number of the frame that is currently being rendered.
+func (p *PPU) Frame() uint64 {
+	return p.renderState.Frame()
+}
+
+// Cycle returns the cycle of the scanline that is currently being rendered.
+func (p *PPU) Cycle() int {
+	return p.renderState.Cycle()
+}
+
+// ScanLine returns the scanline that is currently being rendered.
+func (p *PPU) ScanLine() int {
+	return p.renderState.ScanLine()
+}
+
+// Pixel returns a pixel of the frame that is currently being rendered. During the
+// vertical blank the pixels of the last fully rendered frame are returned.
+func (p *PPU) Pixel(x, y int) color.RGBA {
+	if p.renderState.ScanLine() >= Height {
+		return p.screen.Image().RGBAAt(x, y)
+	}
+	return p.screen.Pixel(x, y)
+}
+
+// Reset executes a soft reset of the PPU, as triggered by the reset button.
+// The control and mask registers, the write toggle and the read buffer are
+// cleared, the memory contents are kept.
+func (p *PPU) Reset() {
+	p.control.Set(0)
+	p.mask.Set(0)
+	p.addressing.ClearLatch()
+	p.fineX = 0
+	p.dataReadBuffer = 0
+}
+
+// PowerCycle resets the PPU to its power up state. The frame counter keeps
+// counting with the next frame to not confuse frame based hooks.
+func (p *PPU) PowerCycle() {
+	frame := p.renderState.Frame()
+	p.reset()
+	p.renderState.SetFrame(frame + 1)
+}
+
+// SetFrameHandler sets a handler that gets called every time a frame has been
+// rendered completely, at the start of the vertical blank.
+func (p *PPU) SetFrameHandler(handler func(frame uint64)) {
+	p.frameHandler = handler
+}
+
 // Step executes PPU cycles.
 func (p *PPU) Step(cycles int) {
 	for i := 0; i < cycles; i++ {
@@ -33,13 +83,16 @@ func (p *PPU) step() {
 	}
 
 	switch p.renderState.ScanLine() {
-	case 241:
-		// the vertical blank flag of the PPU is set at tick 1 (the second tick) of scanline 241,
-		// where the vertical blank NMI also occurs
+	case p.timing.VBlankScanLine:
+		// the vertical blank flag of the PPU is set at tick 1 (the second tick) of scanline 241
+		// for NTSC and PAL and 291 for Dendy, where the vertical blank NMI also occurs
 		p.screen.FinishRendering()
 		p.nmi.SetOccurred(true)
+		if p.frameHandler != nil {
+			p.frameHandler(p.renderState.Frame())
+		}
 
-	case 261:
+	case p.timing.PreRenderScanLine():
 		p.nmi.SetOccurred(false)
 		p.status.SetSpriteOverflow(false)
 		p.status.SetSpriteZeroHit(false)
@@ -50,7 +103,7 @@ func (p *PPU) renderBackground() {
 	cycle := p.renderState.Cycle()
 	scanLine := p.renderState.ScanLine()
 
-	preLine := scanLine == 261
+	preLine := scanLine == p.timing.PreRenderScanLine()
 	visibleLine := scanLine < 240
 	renderLine := preLine || visibleLine
 
@@ -67,6 +120,11 @@ func (p *PPU) renderBackground() {
 		p.tiles.FetchCycle(cycle)
 	}
 
+	// two unused nametable fetches at the end of the scanline
+	if renderLine && (cycle == 337 || cycle == 339) {
+		p.tiles.FetchNameTableByte()
+	}
+
 	if preLine && cycle >= 280 && cycle <= 304 {
 		p.addressing.CopyY()
 	}
@@ -134,7 +192,10 @@ func (p *PPU) renderPixel() {
 
 	colorIndex := p.palette.Read(uint16(paletteIndex))
 	colorIndex %= 64
-	color := colors[colorIndex]
+	if p.mask.Grayscale {
+		colorIndex &= 0x30
+	}
+	color := p.emphasize(p.colors[colorIndex])
 	y := p.renderState.ScanLine()
 	p.screen.SetPixel(x, y, color)
 }
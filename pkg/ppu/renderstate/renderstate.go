@@ -3,6 +3,8 @@
 // Package renderstate handles PPU render handling of cycles, scan lines and frames.
 package renderstate
 
+import "github.com/retroenv/nesgo/pkg/region"
+
 type mask interface {
 	RenderBackground() bool
 	RenderSprites() bool
@@ -11,23 +13,33 @@ type mask interface {
 // RenderState implements a PPU render state manager.
 type RenderState struct {
 	cycle    int // 0-340, 0=idle,1-336=tile data fetching,337-340=nameTable fetching
-	scanLine int // 0-261, 0-239=visible, 240=post-render, 241-260=vertical blank, 261=pre-render
+	scanLine int // 0-261 for NTSC, 0-239=visible, 240=post-render, 241-260=vertical blank, 261=pre-render
 	frame    uint64
+
+	preRenderScanLine int
+	skipOddCycle      bool
 }
 
-// New returns a new render state manager.
-func New() *RenderState {
+// New returns a new render state manager that starts at cycle 0 of
+// scanline 0, which is the PPU position at power up.
+func New(timing region.Timing) *RenderState {
 	return &RenderState{
-		cycle:    340,
-		scanLine: 240,
+		preRenderScanLine: timing.PreRenderScanLine(),
+		skipOddCycle:      timing.SkipOddCycle,
 	}
 }
 
+// SetTiming sets the region specific timing of the frames.
+func (r *RenderState) SetTiming(timing region.Timing) {
+	r.preRenderScanLine = timing.PreRenderScanLine()
+	r.skipOddCycle = timing.SkipOddCycle
+}
+
 // Tick updates cycle, scanLine and frame counters.
 func (r *RenderState) Tick(mask mask) {
-	if mask.RenderBackground() || mask.RenderSprites() {
+	if r.skipOddCycle && (mask.RenderBackground() || mask.RenderSprites()) {
 		// for odd frames, the cycle at the end of the scanline is skipped
-		if r.scanLine == 261 && r.cycle == 339 && r.frame%2 == 1 {
+		if r.scanLine == r.preRenderScanLine && r.cycle == 339 && r.frame%2 == 1 {
 			r.nextFrame()
 			return
 		}
@@ -40,7 +52,7 @@ func (r *RenderState) Tick(mask mask) {
 	r.cycle = 0
 
 	r.scanLine++
-	if r.scanLine <= 261 {
+	if r.scanLine <= r.preRenderScanLine {
 		return
 	}
 	r.nextFrame()
@@ -52,12 +64,22 @@ func (r *RenderState) nextFrame() {
 	r.frame++
 }
 
+// SetFrame sets the frame counter.
+func (r *RenderState) SetFrame(frame uint64) {
+	r.frame = frame
+}
+
 // Cycle returns the current cycle, possible values are 0-340.
 func (r *RenderState) Cycle() int {
 	return r.cycle
 }
 
-// ScanLine returns the current scanline, possible values are 0-261.
+// ScanLine returns the current scanline, possible values are 0-261 for NTSC and 0-311 for PAL.
 func (r *RenderState) ScanLine() int {
 	return r.scanLine
 }
+
+// Frame returns the amount of frames that have been started since power on.
+func (r *RenderState) Frame() uint64 {
+	return r.frame
+}
@@ -41,3 +41,8 @@ func (s *Screen) Image() *image.RGBA {
 func (s *Screen) FinishRendering() {
 	s.front, s.back = s.back, s.front
 }
+
+// Pixel returns a pixel of the rendering image.
+func (s *Screen) Pixel(x, y int) color.RGBA {
+	return s.back.RGBAAt(x, y)
+}
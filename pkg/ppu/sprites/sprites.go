@@ -27,7 +27,7 @@ type status interface {
 // Sprites implements PPU sprites support.
 type Sprites struct {
 	cpu         bus.CPU
-	mapper      bus.Mapper
+	ppuMemory   bus.BasicMemory
 	memory      bus.Memory
 	renderState renderState
 	status      status
@@ -43,10 +43,10 @@ type Sprites struct {
 }
 
 // New returns a new sprites manager.
-func New(cpu bus.CPU, mapper bus.Mapper, memory bus.Memory, renderState renderState, status status) *Sprites {
+func New(cpu bus.CPU, ppuMemory bus.BasicMemory, memory bus.Memory, renderState renderState, status status) *Sprites {
 	return &Sprites{
 		cpu:         cpu,
-		mapper:      mapper,
+		ppuMemory:   ppuMemory,
 		memory:      memory,
 		renderState: renderState,
 		status:      status,
@@ -202,8 +202,8 @@ func (s *Sprites) fetchSpritePattern(sprite *Sprite, row int) uint32 {
 	}
 
 	a := (sprite.attributes & 3) << 2
-	lowTileByte := s.mapper.Read(address)
-	highTileByte := s.mapper.Read(address + 8)
+	lowTileByte := s.ppuMemory.Read(address)
+	highTileByte := s.ppuMemory.Read(address + 8)
 
 	var data uint32
 	for i := 0; i < maxSpritesOnScreen; i++ {
@@ -16,8 +16,8 @@ type addressing interface {
 type Tiles struct {
 	addressing addressing
 	memory     bus.BasicMemory
-	nameTable  bus.NameTable
 
+	tile                   byte
 	attribute              byte
 	backgroundPatternTable uint16
 	lowByte                byte
@@ -26,11 +26,10 @@ type Tiles struct {
 }
 
 // New returns a new tiles manager.
-func New(addressing addressing, memory bus.BasicMemory, nameTable bus.NameTable) *Tiles {
+func New(addressing addressing, memory bus.BasicMemory) *Tiles {
 	return &Tiles{
 		addressing: addressing,
 		memory:     memory,
-		nameTable:  nameTable,
 	}
 }
 
@@ -43,7 +42,7 @@ func (t *Tiles) FetchCycle(cycle int) {
 		t.storeTileData()
 
 	case 1:
-		t.nameTable.Fetch(t.addressing.Address())
+		t.FetchNameTableByte()
 
 	case 3:
 		t.fetchAttributeTableByte()
@@ -58,6 +57,14 @@ func (t *Tiles) FetchCycle(cycle int) {
 	}
 }
 
+// FetchNameTableByte fetches the tile index of the current nametable address.
+// It is also called for the unused fetches at the end of every scanline, which
+// some mappers use to detect the start of a new scanline.
+func (t *Tiles) FetchNameTableByte() {
+	address := 0x2000 | (t.addressing.Address() & 0x0FFF)
+	t.tile = t.memory.Read(address)
+}
+
 // SetBackgroundPatternTable sets the temp register nametable from the passed PPU control byte.
 func (t *Tiles) SetBackgroundPatternTable(table uint16) {
 	t.backgroundPatternTable = table * 0x1000
@@ -96,7 +103,6 @@ func (t *Tiles) storeTileData() {
 }
 
 func (t *Tiles) tileAddress() uint16 {
-	tile := t.nameTable.Value()
-	address := t.backgroundPatternTable + uint16(tile)*16 + t.addressing.FineY()
+	address := t.backgroundPatternTable + uint16(t.tile)*16 + t.addressing.FineY()
 	return address
 }
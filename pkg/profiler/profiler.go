@@ -0,0 +1,461 @@
+// Package profiler implements an execution profiler that attributes the CPU
+// cycles of the emulated program to functions and frames. Functions are
+// defined by the symbols of the program if available, otherwise every jsr
+// target is handled as function.
+package profiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/retroenv/retrogolib/arch/cpu/m6502"
+)
+
+const (
+	maxSpinLoopSize = 16   // maximum size in bytes of a loop that waits for the NMI
+	maxStackDepth   = 64   // maximum depth of the tracked call stack
+	maxFrames       = 600  // amount of last frames that are kept for the per frame breakdown
+	maxOverruns     = 1000 // amount of first frame overruns that are kept
+)
+
+// Function contains the profiling results of a function.
+type Function struct {
+	Name        string
+	Address     uint16
+	Calls       uint64 // amount of calls by jsr or interrupts
+	SelfCycles  uint64 // cycles of the instructions of the function
+	TotalCycles uint64 // cycles of the function including all called functions
+}
+
+// Frame contains the profiling results of a frame.
+type Frame struct {
+	Number     uint64
+	Cycles     uint64            // all cycles executed in the frame
+	IdleCycles uint64            // cycles that the main code waited for the NMI in a spin loop
+	NMICycles  uint64            // cycles of the NMI handler including all called functions
+	Functions  map[string]uint64 // self cycles by function name
+}
+
+// BusyCycles returns the cycles of the frame that were not spent waiting for the NMI.
+func (f Frame) BusyCycles() uint64 {
+	return f.Cycles - f.IdleCycles
+}
+
+// FrameSummary contains the aggregated profiling results of all finished frames.
+type FrameSummary struct {
+	Frames     uint64 // amount of finished frames
+	Cycles     uint64 // cycles of all frames
+	BusyCycles uint64 // busy cycles of all frames
+	Busiest    Frame  // frame with the most busy cycles
+}
+
+// Overrun describes an NMI that arrived before the main code finished its
+// work and started waiting for the next NMI.
+type Overrun struct {
+	Frame uint64 // frame that the NMI arrived in
+	PC    uint16 // address of the interrupted instruction
+	Stack string // interrupted call stack, separated by ;
+	InNMI bool   // the NMI handler of the previous frame was still running
+}
+
+// Step contains the information of an executed instruction.
+type Step struct {
+	PC          uint16 // address of the instruction
+	Next        uint16 // program counter after the execution
+	SP          uint8  // stack pointer before the execution
+	A, X, Y     uint8  // registers after the execution
+	Instruction string // name of the instruction
+	Cycles      uint64 // cycles of the instruction
+}
+
+type stackFrame struct {
+	name      string
+	sp        uint8 // stack pointer after pushing the return address
+	root      bool  // frame of the reset handler, which is never returned from
+	interrupt bool  // frame of an interrupt handler
+	nmi       bool  // frame of the NMI handler
+}
+
+// Profiler attributes the executed CPU cycles to functions and frames.
+// All functions are safe for concurrent use.
+type Profiler struct {
+	mu sync.Mutex
+
+	symbols *Symbols
+	lastPC  uint16
+
+	stack          []stackFrame
+	stackKey       string      // collapsed call stack, function names separated by ;
+	stackFunctions []*Function // distinct functions of the call stack
+	interrupts     int         // interrupt handler frames in the call stack
+	nmis           int         // NMI handler frames in the call stack
+
+	functions map[string]*Function
+	stacks    map[string]uint64 // cycles by collapsed call stack
+
+	frame        Frame
+	frames       []Frame // last finished frames, limited to maxFrames
+	frameSummary FrameSummary
+	overruns     []Overrun // first overruns, limited to maxOverruns
+	overrunCount uint64
+
+	spinning  bool     // the main code executes a loop that waits for the NMI
+	spinStart uint16   // start address of the last small loop
+	spinEnd   uint16   // address of the branch of the last small loop
+	spinState [3]uint8 // registers after the previous iteration of the last small loop
+}
+
+// New returns a new profiler. The symbols are optional, if passed the cycles
+// get attributed to the functions that contain the executed instructions.
+func New(symbols *Symbols) *Profiler {
+	if symbols == nil {
+		symbols = NewSymbols(nil)
+	}
+	p := &Profiler{
+		symbols:   symbols,
+		functions: map[string]*Function{},
+		stacks:    map[string]uint64{},
+		frame:     Frame{Functions: map[string]uint64{}},
+	}
+	p.clearSpinLoop()
+	return p
+}
+
+// Instruction attributes the cycles of an executed instruction.
+func (p *Profiler) Instruction(step Step) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ensureRoot(step.PC)
+	p.lastPC = step.PC
+	p.account(step.PC, step.Cycles)
+	if p.interrupts == 0 {
+		p.updateSpinning(step)
+	}
+
+	switch step.Instruction {
+	case m6502.Jsr.Name:
+		p.push(stackFrame{
+			name: p.functionName(step.Next, fmt.Sprintf("_label_%04x", step.Next)),
+			sp:   step.SP - 2,
+		}, step.Next)
+		p.function(p.stack[len(p.stack)-1].name, step.Next).Calls++
+
+	case m6502.Rts.Name, m6502.Rti.Name:
+		p.pop(step.SP)
+	}
+}
+
+// Interrupt attributes the cycles of an interrupt that interrupted the
+// instruction at the given address and jumped to the handler address. The
+// stack pointer is the value before the interrupt.
+func (p *Profiler) Interrupt(nmi bool, interrupted, handler uint16, sp uint8, cycles uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ensureRoot(interrupted)
+
+	name := "irq"
+	if nmi {
+		name = "nmi"
+		p.checkOverrun(interrupted)
+	}
+
+	p.push(stackFrame{
+		name:      p.functionName(handler, name),
+		sp:        sp - 3,
+		interrupt: true,
+		nmi:       nmi,
+	}, handler)
+	p.function(p.stack[len(p.stack)-1].name, handler).Calls++
+
+	p.lastPC = handler
+	p.account(handler, cycles)
+}
+
+// Stall attributes the cycles that the CPU was stalled by a DMA transfer to
+// the current function.
+func (p *Profiler) Stall(cycles uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ensureRoot(p.lastPC)
+	p.account(p.lastPC, cycles)
+}
+
+// Reset clears the call stack after a reset of the system.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	p.stack = p.stack[:0]
+	p.updateStack(0)
+	p.clearSpinLoop()
+	p.mu.Unlock()
+}
+
+// FrameFinished finishes the per frame breakdown of the given frame. Only
+// the last frames are kept, all frames are added to the frame summary.
+func (p *Profiler) FrameFinished(frame uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.frame.Number = frame
+	if p.frame.Cycles > 0 {
+		p.addFrame(p.frame)
+	}
+	p.frame = Frame{
+		Number:    frame + 1,
+		Functions: map[string]uint64{},
+	}
+}
+
+// Functions returns the profiling results of all executed functions, sorted
+// by the total cycles.
+func (p *Profiler) Functions() []Function {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	functions := make([]Function, 0, len(p.functions))
+	for _, fun := range p.functions {
+		functions = append(functions, *fun)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].TotalCycles != functions[j].TotalCycles {
+			return functions[i].TotalCycles > functions[j].TotalCycles
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
+
+// Frames returns the per frame breakdown of the last finished frames.
+func (p *Profiler) Frames() []Frame {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	frames := make([]Frame, len(p.frames))
+	copy(frames, p.frames)
+	return frames
+}
+
+// FrameSummary returns the aggregated results of all finished frames.
+func (p *Profiler) FrameSummary() FrameSummary {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.frameSummary
+}
+
+// Overruns returns the first detected frame overruns.
+func (p *Profiler) Overruns() []Overrun {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	overruns := make([]Overrun, len(p.overruns))
+	copy(overruns, p.overruns)
+	return overruns
+}
+
+// OverrunCount returns the amount of all detected frame overruns.
+func (p *Profiler) OverrunCount() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.overrunCount
+}
+
+// addFrame adds a finished frame to the summary and the last frames.
+func (p *Profiler) addFrame(frame Frame) {
+	summary := &p.frameSummary
+	if summary.Frames == 0 || frame.BusyCycles() > summary.Busiest.BusyCycles() {
+		summary.Busiest = frame
+	}
+	summary.Frames++
+	summary.Cycles += frame.Cycles
+	summary.BusyCycles += frame.BusyCycles()
+
+	if len(p.frames) == maxFrames {
+		copy(p.frames, p.frames[1:])
+		p.frames = p.frames[:maxFrames-1]
+	}
+	p.frames = append(p.frames, frame)
+}
+
+// ensureRoot adds the frame of the reset handler if the call stack is empty.
+func (p *Profiler) ensureRoot(pc uint16) {
+	if len(p.stack) > 0 {
+		return
+	}
+	name := p.functionName(pc, "reset")
+	p.push(stackFrame{name: name, root: true}, pc)
+	p.function(name, pc).Calls++
+}
+
+// account attributes the cycles to the function that contains the address
+// and all functions of the call stack.
+func (p *Profiler) account(pc uint16, cycles uint64) {
+	self := p.functions[p.stack[len(p.stack)-1].name]
+	key := p.stackKey
+
+	if sym, ok := p.symbols.Lookup(pc); ok && sym.Name != self.Name {
+		// the code jumped into another function without a call
+		self = p.function(sym.Name, sym.Address)
+		key += ";" + sym.Name
+		if !p.inStack(self) {
+			self.TotalCycles += cycles
+		}
+	}
+
+	for _, fun := range p.stackFunctions {
+		fun.TotalCycles += cycles
+	}
+	self.SelfCycles += cycles
+	p.stacks[key] += cycles
+
+	p.frame.Cycles += cycles
+	p.frame.Functions[self.Name] += cycles
+	if p.spinning && p.interrupts == 0 {
+		p.frame.IdleCycles += cycles
+	}
+	if p.nmis > 0 {
+		p.frame.NMICycles += cycles
+	}
+}
+
+// push adds a frame to the call stack for a call of the given address.
+func (p *Profiler) push(frame stackFrame, address uint16) {
+	if len(p.stack) >= maxStackDepth {
+		p.stack = append(p.stack[:1], p.stack[2:]...) // drop the oldest call
+	}
+	p.stack = append(p.stack, frame)
+	p.function(frame.name, address)
+	p.updateStack(len(p.stack))
+}
+
+// pop removes all frames from the call stack that are returned from by a
+// rts or rti that is executed with the given stack pointer. This handles code
+// that manipulates the stack to return from multiple functions at once.
+func (p *Profiler) pop(sp uint8) {
+	depth := len(p.stack)
+	for depth > 0 {
+		frame := p.stack[depth-1]
+		if frame.root || frame.sp > sp {
+			break
+		}
+		depth--
+	}
+
+	if depth != len(p.stack) {
+		p.stack = p.stack[:depth]
+		p.updateStack(depth)
+	}
+}
+
+// updateStack updates the cached information of the call stack after it changed.
+func (p *Profiler) updateStack(depth int) {
+	names := make([]string, 0, depth)
+	p.stackFunctions = p.stackFunctions[:0]
+	p.interrupts = 0
+	p.nmis = 0
+
+	for _, frame := range p.stack {
+		names = append(names, frame.name)
+		if frame.interrupt {
+			p.interrupts++
+		}
+		if frame.nmi {
+			p.nmis++
+		}
+
+		fun := p.functions[frame.name]
+		if !p.inStack(fun) { // count recursive calls only once
+			p.stackFunctions = append(p.stackFunctions, fun)
+		}
+	}
+	p.stackKey = strings.Join(names, ";")
+}
+
+func (p *Profiler) inStack(fun *Function) bool {
+	for _, f := range p.stackFunctions {
+		if f == fun {
+			return true
+		}
+	}
+	return false
+}
+
+// updateSpinning detects whether the main code is waiting for the NMI in a
+// small loop, like polling the vertical blank flag or a variable set by the NMI.
+// A loop is waiting if the registers do not change between two iterations,
+// this excludes delay loops that count down a register after their first
+// iteration.
+func (p *Profiler) updateSpinning(step Step) {
+	if step.PC < p.spinStart || step.PC > p.spinEnd {
+		p.clearSpinLoop()
+	}
+
+	_, branching := m6502.BranchingInstructions[step.Instruction]
+	if !branching || step.Instruction == m6502.Jsr.Name ||
+		step.Next > step.PC || step.PC-step.Next > maxSpinLoopSize {
+		return
+	}
+
+	state := [3]uint8{step.A, step.X, step.Y}
+	if step.Next == p.spinStart && step.PC == p.spinEnd {
+		p.spinning = state == p.spinState
+	} else {
+		p.spinning = true // until the registers change in the next iteration
+		p.spinStart = step.Next
+		p.spinEnd = step.PC
+	}
+	p.spinState = state
+}
+
+// clearSpinLoop stops tracking the last small loop.
+func (p *Profiler) clearSpinLoop() {
+	p.spinning = false
+	p.spinStart = 1
+	p.spinEnd = 0
+}
+
+// checkOverrun records a frame overrun if the NMI interrupted the main code
+// while it was not waiting for the NMI, or if the NMI handler of the previous
+// frame is still running.
+func (p *Profiler) checkOverrun(interrupted uint16) {
+	inNMI := p.nmis > 0
+	if !inNMI && (p.spinning || p.interrupts > 0) {
+		return
+	}
+
+	p.overrunCount++
+	if len(p.overruns) == maxOverruns {
+		return
+	}
+	p.overruns = append(p.overruns, Overrun{
+		Frame: p.frame.Number,
+		PC:    interrupted,
+		Stack: p.stackKey,
+		InNMI: inNMI,
+	})
+}
+
+// functionName returns the name of the symbol that contains the address,
+// or the fallback name if no symbol is found.
+func (p *Profiler) functionName(address uint16, fallback string) string {
+	if sym, ok := p.symbols.Lookup(address); ok {
+		return sym.Name
+	}
+	return fallback
+}
+
+// function returns the results of the function with the given name, the
+// address is used for new functions.
+func (p *Profiler) function(name string, address uint16) *Function {
+	fun, ok := p.functions[name]
+	if !ok {
+		fun = &Function{Name: name, Address: address}
+		p.functions[name] = fun
+	}
+	return fun
+}
@@ -0,0 +1,123 @@
+package profiler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+// testProfile executes a main loop that waits for the NMI and calls a
+// subroutine, the NMI arrives while waiting or while the subroutine runs.
+func testProfile(p *Profiler, nmiInSubroutine bool) {
+	p.Instruction(Step{PC: 0x8000, Next: 0x8003, SP: 0xFD, Instruction: "jsr", Cycles: 6})
+	p.Instruction(Step{PC: 0x8003, Next: 0x8004, SP: 0xFD, Instruction: "dex", Cycles: 2, X: 1})
+	if nmiInSubroutine {
+		p.Interrupt(true, 0x8004, 0x9000, 0xFB, 7)
+		p.Instruction(Step{PC: 0x9000, Next: 0x8004, SP: 0xF8, Instruction: "rti", Cycles: 6})
+	}
+	p.Instruction(Step{PC: 0x8004, Next: 0x8010, SP: 0xFB, Instruction: "rts", Cycles: 6})
+
+	for i := 0; i < 3; i++ {
+		p.Instruction(Step{PC: 0x8010, Next: 0x8012, SP: 0xFD, Instruction: "lda", Cycles: 3})
+		p.Instruction(Step{PC: 0x8012, Next: 0x8010, SP: 0xFD, Instruction: "beq", Cycles: 3})
+	}
+	if !nmiInSubroutine {
+		p.Interrupt(true, 0x8010, 0x9000, 0xFD, 7)
+		p.Instruction(Step{PC: 0x9000, Next: 0x8010, SP: 0xFA, Instruction: "rti", Cycles: 6})
+	}
+	p.FrameFinished(0)
+}
+
+func TestProfiler(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil)
+	testProfile(p, false)
+
+	functions := p.Functions()
+	assert.Equal(t, 3, len(functions))
+	assert.Equal(t, Function{Name: "reset", Address: 0x8000, Calls: 1, SelfCycles: 18 + 6, TotalCycles: 45}, functions[0])
+	assert.Equal(t, Function{Name: "_label_8003", Address: 0x8003, Calls: 1, SelfCycles: 8, TotalCycles: 8}, functions[2])
+	assert.Equal(t, Function{Name: "nmi", Address: 0x9000, Calls: 1, SelfCycles: 13, TotalCycles: 13}, functions[1])
+
+	assert.Equal(t, 0, len(p.Overruns()))
+	frames := p.Frames()
+	assert.Equal(t, 1, len(frames))
+	assert.Equal(t, 45, frames[0].Cycles)
+	assert.Equal(t, 12, frames[0].IdleCycles) // the first iteration is not detected as waiting
+	assert.Equal(t, 13, frames[0].NMICycles)
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.WriteCollapsedStacks(&buf))
+	assert.Equal(t, "reset 24\nreset;_label_8003 8\nreset;nmi 13\n", buf.String())
+}
+
+func TestProfilerOverrun(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil)
+	testProfile(p, true)
+
+	overruns := p.Overruns()
+	assert.Equal(t, 1, len(overruns))
+	assert.Equal(t, Overrun{Frame: 0, PC: 0x8004, Stack: "reset;_label_8003"}, overruns[0])
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.WriteReport(&buf))
+	assert.True(t, strings.Contains(buf.String(), "frame 0: NMI interrupted the main code at $8004 in reset;_label_8003\n"))
+}
+
+func TestProfilerSymbols(t *testing.T) {
+	t.Parallel()
+
+	p := New(NewSymbols([]Symbol{
+		{Name: "main", Address: 0x8000},
+		{Name: "update", Address: 0x8003, Size: 2},
+		{Name: "vblank", Address: 0x9000},
+	}))
+	testProfile(p, false)
+
+	names := map[string]bool{}
+	for _, fun := range p.Functions() {
+		names[fun.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"main": true, "update": true, "vblank": true}, names)
+}
+
+func TestProfilerReturnMultiple(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil)
+	p.Instruction(Step{PC: 0x8000, Next: 0x8100, SP: 0xFD, Instruction: "jsr", Cycles: 6})
+	p.Instruction(Step{PC: 0x8100, Next: 0x8200, SP: 0xFB, Instruction: "jsr", Cycles: 6})
+	// the inner function drops the return address of the outer function
+	p.Instruction(Step{PC: 0x8200, Next: 0x8003, SP: 0xFB, Instruction: "rts", Cycles: 6})
+	p.Instruction(Step{PC: 0x8003, Next: 0x8004, SP: 0xFD, Instruction: "nop", Cycles: 2})
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.WriteCollapsedStacks(&buf))
+	assert.Equal(t, "reset 8\nreset;_label_8100 6\nreset;_label_8100;_label_8200 6\n", buf.String())
+}
+
+func TestProfilerFrameLimit(t *testing.T) {
+	t.Parallel()
+
+	p := New(nil)
+	for frame := uint64(0); frame < maxFrames+10; frame++ {
+		p.Stall(frame + 1)
+		p.FrameFinished(frame)
+	}
+
+	frames := p.Frames()
+	assert.Equal(t, maxFrames, len(frames))
+	assert.Equal(t, 10, frames[0].Number)
+	assert.Equal(t, maxFrames+9, frames[maxFrames-1].Number)
+
+	summary := p.FrameSummary()
+	assert.Equal(t, maxFrames+10, summary.Frames)
+	assert.Equal(t, (maxFrames+10)*(maxFrames+11)/2, summary.Cycles)
+	assert.Equal(t, summary.Cycles, summary.BusyCycles)
+	assert.Equal(t, maxFrames+9, summary.Busiest.Number)
+}
@@ -0,0 +1,130 @@
+package profiler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const topFrameFunctions = 3 // amount of functions listed per frame in the report
+
+// WriteReport writes a text report containing the cycles per function, the
+// first detected frame overruns and the per frame breakdown of the last frames.
+func (p *Profiler) WriteReport(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	functions := p.Functions()
+	summary := p.FrameSummary()
+	frames := p.Frames()
+	overruns := p.Overruns()
+	overrunCount := p.OverrunCount()
+
+	var cycles uint64
+	for _, fun := range functions {
+		cycles += fun.SelfCycles
+	}
+
+	fmt.Fprintf(w, "%-32s %-7s %10s %12s %7s %12s %7s\n",
+		"function", "address", "calls", "self cycles", "self %", "total cycles", "total %")
+	for _, fun := range functions {
+		fmt.Fprintf(w, "%-32s $%04X   %10d %12d %6.2f%% %12d %6.2f%%\n",
+			fun.Name, fun.Address, fun.Calls,
+			fun.SelfCycles, percent(fun.SelfCycles, cycles),
+			fun.TotalCycles, percent(fun.TotalCycles, cycles))
+	}
+
+	fmt.Fprintf(w, "\n%d frame overruns", overrunCount)
+	if overrunCount > uint64(len(overruns)) {
+		fmt.Fprintf(w, ", listing the first %d", len(overruns))
+	}
+	fmt.Fprintln(w)
+	for _, overrun := range overruns {
+		if overrun.InNMI {
+			fmt.Fprintf(w, "frame %d: NMI handler of the previous frame still running at $%04X in %s\n",
+				overrun.Frame, overrun.PC, overrun.Stack)
+		} else {
+			fmt.Fprintf(w, "frame %d: NMI interrupted the main code at $%04X in %s\n",
+				overrun.Frame, overrun.PC, overrun.Stack)
+		}
+	}
+
+	writeFrames(w, summary, frames)
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
+
+// writeFrames writes the summary of all frames and the per frame breakdown of
+// the last frames, busy cycles are all cycles that the main code did not spend
+// waiting for the NMI.
+func writeFrames(w io.Writer, summary FrameSummary, frames []Frame) {
+	fmt.Fprintf(w, "\n%d frames", summary.Frames)
+	if summary.Frames == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintf(w, ", average busy cycles %d, maximum busy cycles %d in frame %d\n",
+		summary.BusyCycles/summary.Frames, summary.Busiest.BusyCycles(), summary.Busiest.Number)
+	if summary.Frames > uint64(len(frames)) {
+		fmt.Fprintf(w, "listing the last %d frames\n", len(frames))
+	}
+
+	for _, frame := range frames {
+		fmt.Fprintf(w, "frame %6d: %6d cycles, %6d busy (%5.1f%%), %6d nmi, top: %s\n",
+			frame.Number, frame.Cycles, frame.BusyCycles(), percent(frame.BusyCycles(), frame.Cycles),
+			frame.NMICycles, topFunctions(frame))
+	}
+}
+
+// WriteCollapsedStacks writes the cycles of all call stacks in the collapsed
+// stack format, which is supported by flame graph tools like flamegraph.pl,
+// speedscope or inferno. Every line contains the function names of the call
+// stack separated by ; and the cycles spent in the stack.
+func (p *Profiler) WriteCollapsedStacks(writer io.Writer) error {
+	p.mu.Lock()
+	stacks := make([]string, 0, len(p.stacks))
+	for stack, cycles := range p.stacks {
+		stacks = append(stacks, fmt.Sprintf("%s %d", stack, cycles))
+	}
+	p.mu.Unlock()
+
+	sort.Strings(stacks)
+	if _, err := io.WriteString(writer, strings.Join(stacks, "\n")+"\n"); err != nil {
+		return fmt.Errorf("writing collapsed stacks: %w", err)
+	}
+	return nil
+}
+
+// topFunctions returns the functions of the frame with the most self cycles.
+func topFunctions(frame Frame) string {
+	names := make([]string, 0, len(frame.Functions))
+	for name := range frame.Functions {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := frame.Functions[names[i]], frame.Functions[names[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return names[i] < names[j]
+	})
+
+	if len(names) > topFrameFunctions {
+		names = names[:topFrameFunctions]
+	}
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s %d", name, frame.Functions[name])
+	}
+	return strings.Join(names, ", ")
+}
+
+func percent(value, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) * 100 / float64(total)
+}
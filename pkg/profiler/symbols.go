@@ -0,0 +1,146 @@
+package profiler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDebugFile is returned when a debug file can not be parsed.
+var ErrInvalidDebugFile = errors.New("invalid debug file")
+
+// Symbol defines a function of the program.
+type Symbol struct {
+	Name    string
+	Address uint16
+	Size    int // size in bytes, 0 if unknown, the function then ends at the next symbol
+}
+
+// Symbols contains the functions of a program sorted by address.
+type Symbols struct {
+	symbols []Symbol
+}
+
+// NewSymbols returns a new symbol table for the given functions.
+func NewSymbols(symbols []Symbol) *Symbols {
+	sorted := make([]Symbol, len(symbols))
+	copy(sorted, symbols)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Address < sorted[j].Address
+	})
+	return &Symbols{symbols: sorted}
+}
+
+// Lookup returns the function that contains the given address.
+func (s *Symbols) Lookup(address uint16) (Symbol, bool) {
+	i := sort.Search(len(s.symbols), func(i int) bool {
+		return s.symbols[i].Address > address
+	})
+	if i == 0 {
+		return Symbol{}, false
+	}
+
+	sym := s.symbols[i-1]
+	if sym.Size > 0 && int(address) >= int(sym.Address)+sym.Size {
+		return Symbol{}, false
+	}
+	return sym, true
+}
+
+// Len returns the amount of symbols.
+func (s *Symbols) Len() int {
+	return len(s.symbols)
+}
+
+// LoadDebugFile loads the symbols from a debug info file that was written by
+// the ld65 linker using the --dbgfile option. Every .proc scope becomes a
+// symbol, the nesgo compiler outputs every Go function as .proc with the
+// name of the function. If the file contains no scopes, all labels are used.
+func LoadDebugFile(reader io.Reader) (*Symbols, error) {
+	addresses := map[string]uint16{} // label addresses by symbol id
+	var labels, procs []Symbol
+	var scopes []map[string]string
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		typ, fields, err := parseDebugLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case "sym":
+			if fields["type"] != "lab" {
+				continue
+			}
+			value, err := strconv.ParseUint(fields["val"], 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("parsing value of symbol '%s': %w", fields["name"], err)
+			}
+			addresses[fields["id"]] = uint16(value)
+			labels = append(labels, Symbol{Name: fields["name"], Address: uint16(value)})
+
+		case "scope":
+			if fields["type"] == "scope" && fields["sym"] != "" {
+				scopes = append(scopes, fields)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading debug file: %w", err)
+	}
+
+	for _, scope := range scopes {
+		address, ok := addresses[scope["sym"]]
+		if !ok {
+			continue
+		}
+		size, _ := strconv.Atoi(scope["size"])
+		procs = append(procs, Symbol{Name: scope["name"], Address: address, Size: size})
+	}
+
+	if len(procs) > 0 {
+		return NewSymbols(procs), nil
+	}
+	return NewSymbols(labels), nil
+}
+
+// parseDebugLine parses a line of the format: type key=value,key="value".
+func parseDebugLine(line string) (string, map[string]string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", nil, nil
+	}
+
+	typ, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		typ, rest = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	fields := map[string]string{}
+	for rest != "" {
+		var key, value string
+		var ok bool
+		key, rest, ok = strings.Cut(rest, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("%w: missing value in line '%s'", ErrInvalidDebugFile, line)
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return "", nil, fmt.Errorf("%w: unterminated string in line '%s'", ErrInvalidDebugFile, line)
+			}
+			value = rest[1 : end+1]
+			rest = strings.TrimPrefix(rest[end+2:], ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		fields[strings.TrimSpace(key)] = value
+	}
+	return typ, fields, nil
+}
@@ -0,0 +1,74 @@
+package profiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+const testDebugFile = `version	major=2,minor=0
+info	csym=0,file=1,lib=0,line=4,mod=1,scope=3,seg=2,span=4,sym=3,type=2
+file	id=0,name="main.asm",size=120,mtime=0x64B80A51,mod=0
+mod	id=0,name="main.o",file=0
+seg	id=0,name="CODE",start=0x008000,size=0x0040,addrsize=absolute,type=ro,oname="main.nes",ooffs=16
+scope	id=0,name="",mod=0,size=64,span=0
+scope	id=1,name="main",mod=0,type=scope,size=16,parent=0,sym=0,span=1
+scope	id=2,name="update",mod=0,type=scope,size=11,parent=0,sym=1,span=2
+sym	id=0,name="main",addrsize=absolute,size=16,scope=0,def=0,ref=1,val=0x8000,seg=0,type=lab
+sym	id=1,name="update",addrsize=absolute,size=11,scope=0,def=1,val=0x8020,seg=0,type=lab
+sym	id=2,name="loop",addrsize=absolute,scope=2,def=2,val=0x8024,seg=0,type=lab
+sym	id=3,name="PPU_CTRL",addrsize=absolute,scope=0,def=3,val=0x2000,type=equ
+`
+
+func TestLoadDebugFile(t *testing.T) {
+	t.Parallel()
+
+	symbols, err := LoadDebugFile(strings.NewReader(testDebugFile))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, symbols.Len())
+
+	sym, ok := symbols.Lookup(0x8024)
+	assert.True(t, ok)
+	assert.Equal(t, "update", sym.Name)
+	assert.Equal(t, 0x8020, sym.Address)
+
+	sym, ok = symbols.Lookup(0x800F)
+	assert.True(t, ok)
+	assert.Equal(t, "main", sym.Name)
+
+	_, ok = symbols.Lookup(0x8010) // after the end of main
+	assert.False(t, ok)
+	_, ok = symbols.Lookup(0x7FFF)
+	assert.False(t, ok)
+}
+
+func TestLoadDebugFileLabels(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	for _, line := range strings.Split(testDebugFile, "\n") {
+		if !strings.HasPrefix(line, "scope") {
+			lines = append(lines, line)
+		}
+	}
+
+	symbols, err := LoadDebugFile(strings.NewReader(strings.Join(lines, "\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, symbols.Len())
+
+	sym, ok := symbols.Lookup(0x8030)
+	assert.True(t, ok)
+	assert.Equal(t, "loop", sym.Name)
+}
+
+func TestLoadDebugFileInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadDebugFile(strings.NewReader("sym\tid=0,name=\"main"))
+	assert.True(t, errors.Is(err, ErrInvalidDebugFile))
+
+	_, err = LoadDebugFile(strings.NewReader("sym\tid"))
+	assert.True(t, errors.Is(err, ErrInvalidDebugFile))
+}
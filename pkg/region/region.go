@@ -0,0 +1,111 @@
+// Package region defines the timing and color differences of the NES regions.
+package region
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownRegion is returned when a region name can not be parsed.
+var ErrUnknownRegion = errors.New("unknown region")
+
+// Region defines a NES hardware region.
+type Region int
+
+const (
+	NTSC  Region = iota // North America and Japan, RP2A03 CPU and RP2C02 PPU
+	PAL                 // Europe and Australia, RP2A07 CPU and RP2C07 PPU
+	Dendy               // Russian famiclone with UA6527P CPU and UA6538 PPU
+)
+
+// Timing contains the timing parameters of a region.
+type Timing struct {
+	CPUClock  int     // CPU clock rate in Hz
+	FrameRate float64 // frames per second
+
+	// PPU cycles that are executed per CPU cycles, NTSC executes 3 PPU cycles
+	// per CPU cycle, PAL 16 PPU cycles per 5 CPU cycles
+	PPUCycles int
+	CPUCycles int
+
+	ScanLines      int  // total amount of scanlines of a frame, including the pre-render scanline
+	VBlankScanLine int  // scanline that the vertical blank and NMI start at
+	SkipOddCycle   bool // whether the last cycle of the pre-render scanline is skipped on odd frames
+	SwapEmphasis   bool // whether the red and green color emphasis bits are swapped
+
+	// HueShift is the angle in degrees that the hue of the colors differs from
+	// the NTSC palette, the PAL PPU generates the color phases shifted by 15°
+	HueShift float64
+}
+
+// PreRenderScanLine returns the number of the pre-render scanline, which is the last scanline of a frame.
+func (t Timing) PreRenderScanLine() int {
+	return t.ScanLines - 1
+}
+
+var timings = map[Region]Timing{
+	NTSC: {
+		CPUClock:       1789773,
+		FrameRate:      60.0988,
+		PPUCycles:      3,
+		CPUCycles:      1,
+		ScanLines:      262,
+		VBlankScanLine: 241,
+		SkipOddCycle:   true,
+	},
+	PAL: {
+		CPUClock:       1662607,
+		FrameRate:      50.007,
+		PPUCycles:      16,
+		CPUCycles:      5,
+		ScanLines:      312,
+		VBlankScanLine: 241,
+		SwapEmphasis:   true,
+		HueShift:       -15,
+	},
+	Dendy: {
+		CPUClock:       1773448,
+		FrameRate:      50.007,
+		PPUCycles:      3,
+		CPUCycles:      1,
+		ScanLines:      312,
+		VBlankScanLine: 291,
+		SwapEmphasis:   true,
+		HueShift:       -15,
+	},
+}
+
+var names = map[Region]string{
+	NTSC:  "NTSC",
+	PAL:   "PAL",
+	Dendy: "Dendy",
+}
+
+// Timing returns the timing parameters of the region.
+func (r Region) Timing() Timing {
+	timing, ok := timings[r]
+	if !ok {
+		return timings[NTSC]
+	}
+	return timing
+}
+
+// String returns the name of the region.
+func (r Region) String() string {
+	name, ok := names[r]
+	if !ok {
+		return fmt.Sprintf("Region(%d)", int(r))
+	}
+	return name
+}
+
+// Parse returns the region matching the given case-insensitive name.
+func Parse(name string) (Region, error) {
+	for r, s := range names {
+		if strings.EqualFold(name, s) {
+			return r, nil
+		}
+	}
+	return NTSC, fmt.Errorf("%w '%s'", ErrUnknownRegion, name)
+}
@@ -0,0 +1,35 @@
+package region
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	r, err := Parse("pal")
+	assert.NoError(t, err)
+	assert.Equal(t, PAL, r)
+	assert.Equal(t, "PAL", r.String())
+
+	r, err = Parse("Dendy")
+	assert.NoError(t, err)
+	assert.Equal(t, Dendy, r)
+
+	_, err = Parse("secam")
+	assert.True(t, errors.Is(err, ErrUnknownRegion))
+}
+
+func TestTiming(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 261, NTSC.Timing().PreRenderScanLine())
+	assert.Equal(t, 311, PAL.Timing().PreRenderScanLine())
+	assert.Equal(t, 291, Dendy.Timing().VBlankScanLine)
+	assert.Equal(t, 0.0, NTSC.Timing().HueShift)
+	assert.Equal(t, -15.0, PAL.Timing().HueShift)
+	assert.Equal(t, NTSC.Timing(), Region(99).Timing())
+}
@@ -0,0 +1,147 @@
+package romdb
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NES 2.0 XML database region values.
+const (
+	nes20RegionNTSC  = 0
+	nes20RegionPAL   = 1
+	nes20RegionMulti = 2
+	nes20RegionDendy = 3
+)
+
+type nes20DB struct {
+	Games []nes20Game `xml:"game"`
+}
+
+type nes20Game struct {
+	Comment  string      `xml:",comment"`
+	ROM      nes20ROM    `xml:"rom"`
+	PCB      nes20PCB    `xml:"pcb"`
+	Console  nes20Device `xml:"console"`
+	PRGRAM   nes20Size   `xml:"prgram"`
+	PRGNVRAM nes20Size   `xml:"prgnvram"`
+	CHRRAM   nes20Size   `xml:"chrram"`
+	CHRNVRAM nes20Size   `xml:"chrnvram"`
+}
+
+type nes20ROM struct {
+	CRC32 string `xml:"crc32,attr"`
+}
+
+type nes20PCB struct {
+	Mapper    uint16 `xml:"mapper,attr"`
+	SubMapper byte   `xml:"submapper,attr"`
+	Mirroring string `xml:"mirroring,attr"`
+	Battery   byte   `xml:"battery,attr"`
+}
+
+type nes20Device struct {
+	Region int `xml:"region,attr"`
+}
+
+type nes20Size struct {
+	Size int `xml:"size,attr"`
+}
+
+// ConvertNES20DB converts the NES 2.0 XML database to the text format that
+// is read by Load. The entries are sorted by CRC32.
+func ConvertNES20DB(reader io.Reader, writer io.Writer) error {
+	var db nes20DB
+	if err := xml.NewDecoder(reader).Decode(&db); err != nil {
+		return fmt.Errorf("decoding NES 2.0 XML database: %w", err)
+	}
+
+	lines := make(map[uint32]string, len(db.Games))
+	for _, game := range db.Games {
+		crc, err := strconv.ParseUint(game.ROM.CRC32, 16, 32)
+		if err != nil {
+			return fmt.Errorf("%w: invalid CRC32 '%s'", ErrInvalidDatabase, game.ROM.CRC32)
+		}
+		line, err := convertGame(game)
+		if err != nil {
+			return fmt.Errorf("converting game %08X: %w", crc, err)
+		}
+		lines[uint32(crc)] = fmt.Sprintf("%08X %s", crc, line)
+	}
+
+	crcs := make([]uint32, 0, len(lines))
+	for crc := range lines {
+		crcs = append(crcs, crc)
+	}
+	sort.Slice(crcs, func(i, j int) bool {
+		return crcs[i] < crcs[j]
+	})
+
+	if _, err := io.WriteString(writer, header); err != nil {
+		return fmt.Errorf("writing ROM database: %w", err)
+	}
+	for _, crc := range crcs {
+		if _, err := fmt.Fprintln(writer, lines[crc]); err != nil {
+			return fmt.Errorf("writing ROM database: %w", err)
+		}
+	}
+	return nil
+}
+
+// header is written at the start of a converted database.
+const header = `# nesgo ROM database
+#
+# crc32 mapper submapper mirroring battery region prgram prgnvram chrram chrnvram name
+#
+# The CRC32 covers the PRG and CHR ROM without header and trainer. This file gets
+# generated from the NES 2.0 XML database by running:
+#
+#   go run ./pkg/romdb/gen -i nes20db.xml -o pkg/romdb/romdb.txt
+`
+
+func convertGame(game nes20Game) (string, error) {
+	var mirroring string
+	switch game.PCB.Mirroring {
+	case mirrorHorizontal, mirrorVertical, mirrorFour:
+		mirroring = game.PCB.Mirroring
+	default:
+		mirroring = mirrorMapper
+	}
+
+	var reg string
+	switch game.Console.Region {
+	case nes20RegionNTSC:
+		reg = "ntsc"
+	case nes20RegionPAL:
+		reg = "pal"
+	case nes20RegionMulti:
+		reg = "multi"
+	case nes20RegionDendy:
+		reg = "dendy"
+	default:
+		return "", fmt.Errorf("%w: invalid region %d", ErrInvalidDatabase, game.Console.Region)
+	}
+
+	line := fmt.Sprintf("%d %d %s %d %s %d %d %d %d",
+		game.PCB.Mapper, game.PCB.SubMapper, mirroring, game.PCB.Battery&1, reg,
+		game.PRGRAM.Size, game.PRGNVRAM.Size, game.CHRRAM.Size, game.CHRNVRAM.Size)
+
+	name := gameName(game.Comment)
+	if name != "" {
+		line += " " + name
+	}
+	return line, nil
+}
+
+// gameName returns the game name of the comment that contains the file
+// name of the ROM dump.
+func gameName(comment string) string {
+	name := strings.TrimSpace(comment)
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,47 @@
+// Package main implements a generator that converts the NES 2.0 XML database
+// to the ROM database format that is embedded in the romdb package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/retroenv/nesgo/pkg/romdb"
+)
+
+func main() {
+	input := flag.String("i", "", "NES 2.0 XML database file to read")
+	output := flag.String("o", "", "ROM database file to write")
+	flag.Parse()
+
+	if *input == "" || *output == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := generate(*input, *output); err != nil {
+		fmt.Printf("Generating ROM database failed: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(input, output string) error {
+	f, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("opening file '%s': %w", input, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	buf := &bytes.Buffer{}
+	if err := romdb.ConvertNES20DB(f, buf); err != nil {
+		return fmt.Errorf("converting database: %w", err)
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing file '%s': %w", output, err)
+	}
+	return nil
+}
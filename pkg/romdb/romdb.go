@@ -0,0 +1,256 @@
+// Package romdb implements a database of ROM header fields, keyed by the
+// CRC32 of the PRG and CHR ROM, that allows to fix wrong iNES headers of
+// ROM dumps.
+package romdb
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+)
+
+// ErrInvalidDatabase is returned when a database line can not be parsed.
+var ErrInvalidDatabase = errors.New("invalid ROM database")
+
+// minFields is the amount of fields of a database line without the name.
+const minFields = 10
+
+//go:embed romdb.txt
+var embedded string
+
+//go:embed curated.txt
+var curated string
+
+var (
+	defaultOnce sync.Once
+	defaultDB   *Database
+	defaultErr  error
+)
+
+// Mirroring values of the database format.
+const (
+	mirrorHorizontal = "H"
+	mirrorVertical   = "V"
+	mirrorFour       = "4"
+	mirrorMapper     = "M" // mirroring is controlled by the mapper
+)
+
+var regionNames = map[string]region.Region{
+	"ntsc":  region.NTSC,
+	"pal":   region.PAL,
+	"dendy": region.Dendy,
+}
+
+// Entry contains the header fields of a ROM.
+type Entry struct {
+	CRC32     uint32 // CRC32 of the PRG and CHR ROM
+	Mapper    uint16
+	SubMapper byte
+
+	Mirror           cartridge.MirrorMode
+	MirrorControlled bool // mirroring is controlled by the mapper, Mirror is not set
+	Battery          bool
+
+	Region      region.Region
+	MultiRegion bool // the game supports multiple regions, Region is not set
+
+	PRGRAMSize   int // volatile PRG-RAM size in bytes
+	PRGNVRAMSize int // battery backed PRG-RAM size in bytes
+	CHRRAMSize   int // volatile CHR-RAM size in bytes
+	CHRNVRAMSize int // battery backed CHR-RAM size in bytes
+
+	Name string
+}
+
+// Database contains the header fields of ROMs.
+type Database struct {
+	entries map[uint32]Entry
+}
+
+// Default returns the database that is embedded in the binary. It gets
+// generated from the NES 2.0 XML database using the generator in the gen
+// directory, the curated entries override the generated ones.
+func Default() (*Database, error) {
+	defaultOnce.Do(func() {
+		reader := io.MultiReader(strings.NewReader(embedded), strings.NewReader("\n"), strings.NewReader(curated))
+		defaultDB, defaultErr = Load(reader)
+	})
+	return defaultDB, defaultErr
+}
+
+// Load loads a database in the text format. Every line contains the fields
+// of an entry separated by whitespace:
+//
+//	crc32 mapper submapper mirroring battery region prgram prgnvram chrram chrnvram name
+//
+// The CRC32 is hexadecimal, the mirroring is H, V, 4 or M for mapper controlled
+// mirroring, the region is ntsc, pal, dendy or multi and the RAM sizes are in
+// bytes. The name is optional and can contain whitespace. Empty lines and lines
+// starting with # are ignored.
+func Load(reader io.Reader) (*Database, error) {
+	db := &Database{
+		entries: map[uint32]Entry{},
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseEntry(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		db.entries[entry.CRC32] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ROM database: %w", err)
+	}
+	return db, nil
+}
+
+func parseEntry(line string) (Entry, error) {
+	fields := strings.Fields(line)
+	if len(fields) < minFields {
+		return Entry{}, fmt.Errorf("%w: expected %d fields but found %d", ErrInvalidDatabase, minFields, len(fields))
+	}
+
+	var entry Entry
+	values := make([]uint64, minFields)
+	for i, bits := range []int{32, 16, 8} {
+		base := 10
+		if i == 0 {
+			base = 16
+		}
+		value, err := strconv.ParseUint(fields[i], base, bits)
+		if err != nil {
+			return Entry{}, fmt.Errorf("%w: invalid number '%s'", ErrInvalidDatabase, fields[i])
+		}
+		values[i] = value
+	}
+	for i := 6; i < minFields; i++ {
+		value, err := strconv.ParseUint(fields[i], 10, 32)
+		if err != nil {
+			return Entry{}, fmt.Errorf("%w: invalid number '%s'", ErrInvalidDatabase, fields[i])
+		}
+		values[i] = value
+	}
+	entry.CRC32 = uint32(values[0])
+	entry.Mapper = uint16(values[1])
+	entry.SubMapper = byte(values[2])
+	entry.PRGRAMSize = int(values[6])
+	entry.PRGNVRAMSize = int(values[7])
+	entry.CHRRAMSize = int(values[8])
+	entry.CHRNVRAMSize = int(values[9])
+
+	switch fields[3] {
+	case mirrorHorizontal:
+		entry.Mirror = cartridge.MirrorHorizontal
+	case mirrorVertical:
+		entry.Mirror = cartridge.MirrorVertical
+	case mirrorFour:
+		entry.Mirror = cartridge.Mirror4
+	case mirrorMapper:
+		entry.MirrorControlled = true
+	default:
+		return Entry{}, fmt.Errorf("%w: invalid mirroring '%s'", ErrInvalidDatabase, fields[3])
+	}
+
+	switch fields[4] {
+	case "0":
+	case "1":
+		entry.Battery = true
+	default:
+		return Entry{}, fmt.Errorf("%w: invalid battery flag '%s'", ErrInvalidDatabase, fields[4])
+	}
+
+	if fields[5] == "multi" {
+		entry.MultiRegion = true
+	} else {
+		reg, ok := regionNames[fields[5]]
+		if !ok {
+			return Entry{}, fmt.Errorf("%w: invalid region '%s'", ErrInvalidDatabase, fields[5])
+		}
+		entry.Region = reg
+	}
+
+	entry.Name = strings.Join(fields[minFields:], " ")
+	return entry, nil
+}
+
+// Len returns the amount of entries in the database.
+func (db *Database) Len() int {
+	return len(db.entries)
+}
+
+// Lookup returns the entry of the ROM with the given PRG and CHR ROM.
+func (db *Database) Lookup(prg, chr []byte) (Entry, bool) {
+	crc := crc32.Update(crc32.ChecksumIEEE(prg), crc32.IEEETable, chr)
+	entry, ok := db.entries[crc]
+	return entry, ok
+}
+
+// Apply overrides the header fields of the cartridge with the fields of the
+// database entry of its ROM. It returns the entry and a description of every
+// changed field, the entry is empty if the ROM is not in the database.
+func (db *Database) Apply(cart *cartridge.Cartridge) (Entry, []string) {
+	entry, ok := db.Lookup(cart.PRG, cart.CHR)
+	if !ok {
+		return Entry{}, nil
+	}
+
+	var changes []string
+	if entry.Mapper <= 0xff && byte(entry.Mapper) != cart.Mapper {
+		changes = append(changes, fmt.Sprintf("mapper %d -> %d", cart.Mapper, entry.Mapper))
+		cart.Mapper = byte(entry.Mapper)
+	}
+	if !entry.MirrorControlled && entry.Mirror != cart.Mirror {
+		changes = append(changes, fmt.Sprintf("mirroring %s -> %s", mirrorName(cart.Mirror), mirrorName(entry.Mirror)))
+		cart.Mirror = entry.Mirror
+	}
+
+	var battery byte
+	if entry.Battery {
+		battery = 1
+	}
+	if battery != cart.Battery {
+		changes = append(changes, fmt.Sprintf("battery %t -> %t", cart.Battery != 0, entry.Battery))
+		cart.Battery = battery
+	}
+
+	if !entry.MultiRegion && entry.Region != region.Dendy {
+		var videoFormat byte
+		if entry.Region == region.PAL {
+			videoFormat = 1
+		}
+		if videoFormat != cart.VideoFormat {
+			changes = append(changes, fmt.Sprintf("video format %d -> %d", cart.VideoFormat, videoFormat))
+			cart.VideoFormat = videoFormat
+		}
+	}
+	return entry, changes
+}
+
+func mirrorName(mode cartridge.MirrorMode) string {
+	switch mode {
+	case cartridge.MirrorHorizontal:
+		return "horizontal"
+	case cartridge.MirrorVertical:
+		return "vertical"
+	case cartridge.Mirror4:
+		return "four-screen"
+	default:
+		return strconv.Itoa(int(mode))
+	}
+}
@@ -0,0 +1,194 @@
+package romdb
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/retroenv/nesgo/pkg/region"
+	"github.com/retroenv/retrogolib/arch/nes/cartridge"
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func loadTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	f, err := os.Open("testdata/romdb.txt")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, f.Close())
+	}()
+
+	db, err := Load(f)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	db := loadTestDatabase(t)
+	assert.Equal(t, 2, db.Len())
+
+	entry, ok := db.Lookup(make([]byte, 16384), make([]byte, 8192))
+	assert.True(t, ok)
+	assert.Equal(t, 0x6EBED2EE, entry.CRC32)
+	assert.Equal(t, 3, entry.Mapper)
+	assert.Equal(t, cartridge.MirrorVertical, entry.Mirror)
+	assert.False(t, entry.MirrorControlled)
+	assert.True(t, entry.Battery)
+	assert.Equal(t, region.PAL, entry.Region)
+	assert.Equal(t, 8192, entry.PRGNVRAMSize)
+	assert.Equal(t, "Zero Filled (World)", entry.Name)
+
+	entry, ok = db.Lookup(bytes.Repeat([]byte{0xea}, 32768), nil)
+	assert.True(t, ok)
+	assert.Equal(t, 5, entry.SubMapper)
+	assert.True(t, entry.MirrorControlled)
+	assert.True(t, entry.MultiRegion)
+	assert.Equal(t, 8192, entry.CHRRAMSize)
+
+	_, ok = db.Lookup(make([]byte, 16384), nil)
+	assert.False(t, ok)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"6EBED2EE 3 0 V 1 pal 0 8192 0",
+		"XEBED2EE 3 0 V 1 pal 0 8192 0 0",
+		"6EBED2EE 3 0 X 1 pal 0 8192 0 0",
+		"6EBED2EE 3 0 V 2 pal 0 8192 0 0",
+		"6EBED2EE 3 0 V 1 secam 0 8192 0 0",
+	}
+	for _, line := range lines {
+		_, err := Load(strings.NewReader(line))
+		assert.True(t, errors.Is(err, ErrInvalidDatabase), line)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	db, err := Default()
+	assert.NoError(t, err)
+	assert.True(t, db != nil)
+}
+
+// minDefaultEntries is the minimum amount of entries that the database that is
+// generated from the NES 2.0 XML database has to contain.
+const minDefaultEntries = 10000
+
+func TestDefaultSize(t *testing.T) {
+	t.Parallel()
+
+	generated, err := Load(strings.NewReader(embedded))
+	assert.NoError(t, err)
+	if generated.Len() == 0 {
+		t.Skip("romdb.txt is not generated yet, run make romdb NES20DB=nes20db.xml")
+	}
+
+	db, err := Default()
+	assert.NoError(t, err)
+	assert.True(t, db.Len() >= minDefaultEntries, "generated database is incomplete")
+}
+
+func TestDefaultFixesHeader(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("../../internal/testroms/nestest/nestest.nes")
+	assert.NoError(t, err)
+
+	// simulate a dump with a header that was modified by DiskDude, which
+	// results in mapper 64 and a garbage video format, and set the vertical
+	// mirroring and battery flags
+	copy(data[7:], "DiskDude!")
+	data[6] |= 0b0000_0011
+
+	cart, err := cartridge.LoadFile(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 64, cart.Mapper)
+
+	db, err := Default()
+	assert.NoError(t, err)
+
+	entry, changes := db.Apply(cart)
+	assert.Equal(t, 0x158B0388, entry.CRC32)
+	assert.Equal(t, "nestest", entry.Name)
+	assert.Equal(t, []string{
+		"mapper 64 -> 0",
+		"mirroring vertical -> horizontal",
+		"battery true -> false",
+		"video format 115 -> 0",
+	}, changes)
+	assert.Equal(t, 0, cart.Mapper)
+	assert.Equal(t, cartridge.MirrorHorizontal, cart.Mirror)
+	assert.Equal(t, 0, cart.Battery)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	db := loadTestDatabase(t)
+	cart := &cartridge.Cartridge{
+		PRG:    make([]byte, 16384),
+		CHR:    make([]byte, 8192),
+		Mirror: cartridge.MirrorHorizontal,
+	}
+
+	entry, changes := db.Apply(cart)
+	assert.Equal(t, "Zero Filled (World)", entry.Name)
+	assert.Equal(t, []string{
+		"mapper 0 -> 3",
+		"mirroring horizontal -> vertical",
+		"battery false -> true",
+		"video format 0 -> 1",
+	}, changes)
+	assert.Equal(t, 3, cart.Mapper)
+	assert.Equal(t, cartridge.MirrorVertical, cart.Mirror)
+	assert.Equal(t, 1, cart.Battery)
+	assert.Equal(t, 1, cart.VideoFormat)
+
+	_, changes = db.Apply(cart)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestApplyMapperControlled(t *testing.T) {
+	t.Parallel()
+
+	db := loadTestDatabase(t)
+	cart := &cartridge.Cartridge{
+		PRG:         bytes.Repeat([]byte{0xea}, 32768),
+		Mapper:      1,
+		Mirror:      cartridge.MirrorHorizontal,
+		VideoFormat: 1,
+	}
+
+	_, changes := db.Apply(cart)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestConvertNES20DB(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/nes20db.xml")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, f.Close())
+	}()
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, ConvertNES20DB(f, buf))
+
+	expected := header +
+		"6EBED2EE 3 0 V 1 pal 0 8192 0 0 Zero Filled (World)\n" +
+		"DE2A1C8F 1 5 H 0 multi 0 0 8192 0 NOP Filled (World)\n"
+	assert.Equal(t, expected, buf.String())
+
+	db, err := Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, db.Len())
+}
@@ -0,0 +1,141 @@
+// Package screenshot implements saving of emulator screenshots as PNG files and
+// comparing of rendered frames against golden images.
+package screenshot
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// ErrSizeMismatch is returned when the compared images have different dimensions.
+var ErrSizeMismatch = errors.New("image sizes do not match")
+
+var diffColor = color.RGBA{R: 0xff, A: 0xff}
+
+// Result contains the result of an image comparison.
+type Result struct {
+	DifferentPixels int         // amount of pixels that differ more than the tolerance
+	MaxDifference   int         // highest difference of a single color channel
+	Diff            *image.RGBA // image with all different pixels marked in red
+}
+
+// Equal returns whether all pixels of the compared images are within the tolerance.
+func (r Result) Equal() bool {
+	return r.DifferentPixels == 0
+}
+
+// Save writes the image as PNG to the given file.
+func Save(img image.Image, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("creating file '%s': %w", fileName, err)
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("encoding png: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing file '%s': %w", fileName, err)
+	}
+	return nil
+}
+
+// Load reads a PNG image from the given file.
+func Load(fileName string) (*image.RGBA, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening file '%s': %w", fileName, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding png: %w", err)
+	}
+
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// CompareFile compares the image against the golden PNG image stored in the given file.
+func CompareFile(img image.Image, goldenFileName string, tolerance int) (Result, error) {
+	golden, err := Load(goldenFileName)
+	if err != nil {
+		return Result{}, err
+	}
+	return Compare(img, golden, tolerance)
+}
+
+// Compare compares the image against a golden image. A pixel is counted as different
+// if any of its color channels differs more than the given tolerance.
+// The returned diff image shows all matching pixels as dimmed grayscale and all
+// different pixels in red.
+func Compare(img, golden image.Image, tolerance int) (Result, error) {
+	bounds := img.Bounds()
+	goldenBounds := golden.Bounds()
+	if bounds.Dx() != goldenBounds.Dx() || bounds.Dy() != goldenBounds.Dy() {
+		return Result{}, fmt.Errorf("%w: %dx%d vs %dx%d", ErrSizeMismatch,
+			bounds.Dx(), bounds.Dy(), goldenBounds.Dx(), goldenBounds.Dy())
+	}
+
+	result := Result{
+		Diff: image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy())),
+	}
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c1 := color.RGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			c2 := color.RGBAModel.Convert(golden.At(goldenBounds.Min.X+x, goldenBounds.Min.Y+y)).(color.RGBA)
+
+			difference := maxChannelDifference(c1, c2)
+			if difference > result.MaxDifference {
+				result.MaxDifference = difference
+			}
+
+			if difference > tolerance {
+				result.DifferentPixels++
+				result.Diff.SetRGBA(x, y, diffColor)
+				continue
+			}
+
+			gray := color.GrayModel.Convert(c1).(color.Gray)
+			dimmed := gray.Y / 3
+			result.Diff.SetRGBA(x, y, color.RGBA{R: dimmed, G: dimmed, B: dimmed, A: 0xff})
+		}
+	}
+
+	return result, nil
+}
+
+func maxChannelDifference(c1, c2 color.RGBA) int {
+	maxDifference := 0
+	for _, d := range []int{
+		channelDifference(c1.R, c2.R),
+		channelDifference(c1.G, c2.G),
+		channelDifference(c1.B, c2.B),
+		channelDifference(c1.A, c2.A),
+	} {
+		if d > maxDifference {
+			maxDifference = d
+		}
+	}
+	return maxDifference
+}
+
+func channelDifference(b1, b2 byte) int {
+	d := int(b1) - int(b2)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
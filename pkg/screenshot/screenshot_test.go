@@ -0,0 +1,67 @@
+package screenshot
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/retroenv/retrogolib/assert"
+)
+
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: byte(x * 0x40), G: byte(y * 0x40), B: 0x80, A: 0xff})
+		}
+	}
+	return img
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	img := testImage()
+	fileName := filepath.Join(t.TempDir(), "test.png")
+	assert.NoError(t, Save(img, fileName))
+
+	loaded, err := Load(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Pix, loaded.Pix)
+
+	result, err := CompareFile(img, fileName, 0)
+	assert.NoError(t, err)
+	assert.True(t, result.Equal())
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	golden := testImage()
+	img := testImage()
+	img.SetRGBA(1, 2, color.RGBA{R: 0x40 + 3, G: 0x80, B: 0x80, A: 0xff})
+	img.SetRGBA(3, 3, color.RGBA{A: 0xff})
+
+	result, err := Compare(img, golden, 0)
+	assert.NoError(t, err)
+	assert.False(t, result.Equal())
+	assert.Equal(t, 2, result.DifferentPixels)
+	assert.Equal(t, 0xc0, result.MaxDifference)
+	assert.Equal(t, diffColor, result.Diff.RGBAAt(1, 2))
+	assert.Equal(t, diffColor, result.Diff.RGBAAt(3, 3))
+	assert.True(t, result.Diff.RGBAAt(0, 0) != diffColor)
+
+	result, err = Compare(img, golden, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.DifferentPixels)
+}
+
+func TestCompareSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	_, err := Compare(img, testImage(), 0)
+	assert.True(t, errors.Is(err, ErrSizeMismatch))
+}